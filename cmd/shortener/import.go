@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/StainlessSteelSnake/shurl/internal/config"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// importUsage содержит описание подкоманды импорта данных из файла хранилища во встроенную БД.
+const importUsage = "usage: shortener import <file> -bolt-storage-path <database> [flags]"
+
+// errImportUsage возвращается при неверном вызове подкоманды импорта.
+var errImportUsage = errors.New(importUsage)
+
+// runImport загружает записи из файла хранилища в формате JSON Lines во встроенную БД ключ-значение.
+func runImport(ctx context.Context, cfg *config.Configuration, filePath string, out io.Writer) error {
+	if filePath == "" || cfg.BoltStoragePath == "" {
+		return errImportUsage
+	}
+
+	s, err := storage.NewBoltStorage(storage.NewMemoryStorage(), cfg.BoltStoragePath)
+	if err != nil {
+		return err
+	}
+	s.DeletionCancel = func() {}
+	defer s.CloseFunc()()
+
+	result, err := s.Import(ctx, filePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Импортировано записей: %d, событий перехода: %d\n", result.Records, result.Clicks)
+	return err
+}
+
+// importFile разбирает параметры сервиса, выполняет подкоманду импорта и завершает работу программы.
+func importFile(filePath string) {
+	cfg := config.NewConfiguration()
+
+	err := runImport(context.Background(), cfg, filePath, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка при импорте данных:", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/config"
+)
+
+// keysUsage содержит описание подкоманды управления ключами подписи токенов пользователей.
+const keysUsage = "usage: shortener keys rotate -auth-keys-path <file> [flags]"
+
+// errKeysUsage возвращается при неверном вызове подкоманды управления ключами подписи.
+var errKeysUsage = errors.New(keysUsage)
+
+// newKeyring создаёт набор ключей подписи токенов пользователей по настройкам сервиса:
+// из файла с ключами, из секрета или, если не задано ни то, ни другое, возвращает nil.
+func newKeyring(cfg *config.Configuration) (*auth.Keyring, error) {
+	switch {
+	case cfg.AuthKeysPath != "":
+		return auth.LoadKeyring(cfg.AuthKeysPath)
+	case cfg.AuthSecret != "":
+		return auth.NewSecretKeyring(string(cfg.AuthSecret)), nil
+	default:
+		return nil, nil
+	}
+}
+
+// runKeys выполняет действие с ключами подписи: создаёт новый ключ для подписи, а прежние ключи оставляет только для проверки.
+// Запущенные экземпляры сервиса перечитывают файл с ключами при его изменении.
+func runKeys(cfg *config.Configuration, action string, out io.Writer) error {
+	if action != "rotate" || cfg.AuthKeysPath == "" {
+		return errKeysUsage
+	}
+
+	key, err := auth.RotateKeyring(cfg.AuthKeysPath, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Создан ключ подписи %s, прежние ключи оставлены только для проверки\n", key.ID)
+	return err
+}
+
+// keys разбирает параметры сервиса, выполняет подкоманду управления ключами подписи и завершает работу программы.
+func keys(action string) {
+	cfg := config.NewConfiguration()
+
+	err := runKeys(cfg, action, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка при смене ключа подписи:", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
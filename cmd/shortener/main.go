@@ -8,13 +8,20 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/StainlessSteelSnake/shurl/internal/analytics"
 	"github.com/StainlessSteelSnake/shurl/internal/auth"
 	"github.com/StainlessSteelSnake/shurl/internal/config"
 	"github.com/StainlessSteelSnake/shurl/internal/grpcserv"
 	"github.com/StainlessSteelSnake/shurl/internal/handlers"
+	"github.com/StainlessSteelSnake/shurl/internal/oidc"
+	"github.com/StainlessSteelSnake/shurl/internal/ratelimit"
+	"github.com/StainlessSteelSnake/shurl/internal/realip"
+	"github.com/StainlessSteelSnake/shurl/internal/safety"
 	"github.com/StainlessSteelSnake/shurl/internal/server"
 	"github.com/StainlessSteelSnake/shurl/internal/storage"
+	"github.com/StainlessSteelSnake/shurl/internal/urlnorm"
 	"golang.org/x/crypto/acme/autocert"
 )
 
@@ -48,36 +55,93 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	command, action, args := splitSubcommand(os.Args)
+	switch command {
+	case migrateCommand:
+		os.Args = args
+		migrate(action)
+	case importCommand:
+		os.Args = args
+		importFile(action)
+	case keysCommand:
+		os.Args = args
+		keys(action)
+	}
+
 	cfg := config.NewConfiguration()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalln("Ошибка в настройках сервиса:", err)
+	}
 	ctx := context.Background()
 
 	var h *handlers.Handler
-	var store storage.Storager
 
-	deletionContext, deletionCancel := context.WithCancel(ctx)
+	store, err := storage.NewStorage(ctx, storageOptions(cfg))
+	if err != nil {
+		log.Fatalln("Ошибка при создании хранилища:", err)
+	}
+
+	keyring, err := newKeyring(cfg)
+	if err != nil {
+		log.Fatalln("Ошибка при загрузке ключей подписи токенов:", err)
+	}
+
+	keyringContext, keyringCancel := context.WithCancel(ctx)
+	if keyring != nil {
+		keyring.ReloadProcess(keyringContext, auth.KeyringCheckInterval)
+	}
 
-	if cfg.DatabaseDSN != "" {
-		dbStore := storage.NewDBStorage(ctx, storage.NewMemoryStorage(), cfg.DatabaseDSN)
-		dbStore.DeletionCancel = deletionCancel
-		dbStore.DeletionQueueProcess(deletionContext)
-		store = dbStore
+	apiKeys, _ := store.(storage.APIKeyStorager)
+	authenticator := auth.NewAuth(keyring, auth.Options{
+		TokenTTL:      time.Duration(cfg.AuthTokenTTL),
+		RefreshBefore: time.Duration(cfg.AuthTokenRefresh),
+		Issuer:        cfg.BaseURL,
+		SecureCookie:  cfg.EnableHTTPS,
+		APIKeys:       apiKeys,
+	})
 
-	} else {
-		mStore := storage.NewMemoryStorage()
-		mStore.DeletionCancel = deletionCancel
-		mStore.DeletionQueueProcess(deletionContext)
-		store = mStore
+	proxies, err := realip.NewResolver(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalln("Ошибка в списке доверенных прокси:", err)
 	}
 
-	defer store.CloseFunc()
+	recorderContext, recorderCancel := context.WithCancel(ctx)
+	recorder := analytics.NewRecorder(store, []byte(cfg.AnalyticsSecret), proxies)
+	recorder.Run(recorderContext)
 
-	authenticator := auth.NewAuth()
+	passwordLimiter := ratelimit.NewResourceLimiter(ratelimit.DefaultAttempts, ratelimit.DefaultResourceAttempts, ratelimit.DefaultWindow)
 
-	h = handlers.NewHandler(store, cfg.BaseURL, authenticator, cfg.TrustedSubnet)
+	normalizer := urlnorm.NewNormalizer(urlnorm.Options{
+		Schemes:     cfg.URLSchemes,
+		StripParams: cfg.URLStripParams,
+		MaxLength:   cfg.URLMaxLength,
+	})
+
+	blocklist, err := safety.NewBlocklist(cfg.BaseURL, cfg.BlocklistPath)
+	if err != nil {
+		log.Fatalln("Ошибка при загрузке списка заблокированных доменов:", err)
+	}
+
+	blocklistContext, blocklistCancel := context.WithCancel(ctx)
+	blocklist.ReloadProcess(blocklistContext, safety.BlocklistCheckInterval)
+
+	var provider *oidc.Client
+	if cfg.OIDCIssuer != "" {
+		log.Println("Вход пользователей через провайдера OpenID Connect", cfg.OIDCIssuer)
+		provider = oidc.NewClient(oidc.Config{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: string(cfg.OIDCClientSecret),
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		})
+	}
+
+	h = handlers.NewHandler(store, cfg.BaseURL, authenticator, cfg.TrustedSubnet, proxies, recorder, passwordLimiter, normalizer, blocklist, provider)
 
 	srv := server.NewServer(cfg.ServerAddress, h)
 
-	grpcServ, err := grpcserv.NewServer(cfg.GrpcServerAddress, cfg.BaseURL, store, authenticator)
+	grpcServ, err := grpcserv.NewServer(cfg.GrpcServerAddress, cfg.BaseURL, store, authenticator, passwordLimiter, normalizer, blocklist)
 	if err != nil {
 		log.Fatalln("Ошибка при открытии tcp-канала", cfg.GrpcServerAddress, "для gRPC-сервера:", err)
 		grpcServ = nil
@@ -101,7 +165,23 @@ func main() {
 			grpcServ.GracefulStop()
 		}
 
-		deletionCancel()
+		recorderCancel()
+		blocklistCancel()
+		keyringCancel()
+		recorder.Wait()
+
+		if drainer, ok := store.(storage.DeletionDrainer); ok {
+			drainContext, drainCancel := context.WithTimeout(ctx, storage.DeletionDrainTimeout)
+			err := drainer.DrainDeletionQueue(drainContext)
+			if err != nil {
+				log.Println("Ошибка при удалении оставшихся в очереди коротких URL:", err)
+			}
+			drainCancel()
+		}
+
+		if closeStorage := store.CloseFunc(); closeStorage != nil {
+			closeStorage()
+		}
 
 		close(canTerminate)
 	}()
@@ -131,3 +211,34 @@ func main() {
 	<-canTerminate
 	log.Println("Terminating the server.")
 }
+
+// storageOptions переносит настройки хранилища из параметров сервиса.
+func storageOptions(cfg *config.Configuration) storage.Options {
+	return storage.Options{
+		DatabaseDSN:        cfg.DatabaseDSN,
+		RedisAddress:       cfg.RedisAddress,
+		BoltStoragePath:    cfg.BoltStoragePath,
+		FileStoragePath:    cfg.FileStoragePath,
+		Generator:          cfg.ShortURLGenerator,
+		GeneratedLength:    cfg.ShortURLLength,
+		Salt:               cfg.ShortURLSalt,
+		DedupScope:         cfg.DedupScope,
+		RestoreGracePeriod: time.Duration(cfg.RestoreGracePeriod),
+		Database: storage.DatabaseOptions{
+			CacheSize:         cfg.DatabaseCacheSize,
+			MinConns:          cfg.DatabaseMinConns,
+			MaxConns:          cfg.DatabaseMaxConns,
+			HealthCheckPeriod: time.Duration(cfg.DatabaseHealthCheckPeriod),
+			AcquireTimeout:    time.Duration(cfg.DatabaseAcquireTimeout),
+		},
+		Deletion: storage.DeletionOptions{
+			BatchSize:     cfg.DeletionBatchSize,
+			FlushInterval: time.Duration(cfg.DeletionFlushInterval),
+		},
+		Compaction: storage.CompactionOptions{
+			MinSize:  cfg.FileCompactionMinSize,
+			Ratio:    cfg.FileCompactionRatio,
+			Snapshot: cfg.FileSnapshot,
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/StainlessSteelSnake/shurl/internal/config"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// Подкоманды приложения, которые выполняются вместо запуска сервиса.
+const (
+	migrateCommand = "migrate"
+	importCommand  = "import"
+	keysCommand    = "keys"
+)
+
+// migrateUsage содержит описание подкоманды управления миграциями схемы БД.
+const migrateUsage = "usage: shortener migrate up|down|status [flags]"
+
+// errMigrateUsage возвращается при неверном вызове подкоманды управления миграциями.
+var errMigrateUsage = errors.New(migrateUsage)
+
+// splitSubcommand отделяет подкоманду и её действие от остальных аргументов командной строки,
+// чтобы параметры сервиса можно было разобрать обычным образом.
+func splitSubcommand(args []string) (command, action string, rest []string) {
+	if len(args) < 2 || (args[1] != migrateCommand && args[1] != importCommand && args[1] != keysCommand) {
+		return "", "", args
+	}
+
+	rest = append(rest, args[0])
+	if len(args) > 2 {
+		action = args[2]
+		rest = append(rest, args[3:]...)
+	}
+
+	return args[1], action, rest
+}
+
+// runMigrate выполняет действие с миграциями схемы БД: применение, откат последней миграции или вывод состояния.
+func runMigrate(ctx context.Context, cfg *config.Configuration, action string, out io.Writer) error {
+	if action != "up" && action != "down" && action != "status" {
+		return errMigrateUsage
+	}
+
+	if cfg.DatabaseDSN == "" {
+		return errors.New("не задана строка подключения к БД")
+	}
+
+	migrator, err := storage.ConnectMigrator(ctx, cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			_, err = fmt.Fprintln(out, "Схема БД в актуальном состоянии")
+			return err
+		}
+
+		for _, m := range applied {
+			_, err = fmt.Fprintf(out, "Применена миграция %04d %s\n", m.Version, m.Name)
+			if err != nil {
+				return err
+			}
+		}
+
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+
+		if reverted == nil {
+			_, err = fmt.Fprintln(out, "Нет применённых миграций")
+			return err
+		}
+
+		_, err = fmt.Fprintf(out, "Откачена миграция %04d %s\n", reverted.Version, reverted.Name)
+		return err
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "-"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+
+	return nil
+}
+
+// migrate разбирает параметры сервиса, выполняет подкоманду управления миграциями и завершает работу программы.
+func migrate(action string) {
+	cfg := config.NewConfiguration()
+
+	err := runMigrate(context.Background(), cfg, action, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка при выполнении миграций:", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
@@ -0,0 +1,215 @@
+// Пакет analytics отвечает за сбор и обработку статистики переходов по коротким URL.
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/StainlessSteelSnake/shurl/internal/realip"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// Константы для буферизации и пакетной записи событий перехода.
+const (
+	// ClickQueueSize задаёт максимальный размер очереди событий перехода, ожидающих записи в хранилище.
+	ClickQueueSize = 1024
+	// ClickBatchSize задаёт максимальный размер пакета событий перехода, записываемого в хранилище за один раз.
+	ClickBatchSize = 100
+	// ClickFlushInterval задаёт максимальное время ожидания перед записью неполного пакета событий перехода.
+	ClickFlushInterval = time.Second
+	// UnknownCountry содержит код страны, используемый до подключения определения страны по IP-адресу.
+	UnknownCountry = "ZZ"
+
+	dayLayout = "2006-01-02"
+
+	secretLength = 32 // Длина случайного секрета для хеширования IP-адресов в байтах
+)
+
+// Типы данных для сбора и обработки статистики переходов.
+type (
+	// Recorder накапливает события перехода в очереди и записывает их в хранилище пакетами в отдельном потоке,
+	// не задерживая обработку запросов на переход.
+	// IP-адреса посетителей хранятся только в виде HMAC с секретом сервиса.
+	Recorder struct {
+		storage storage.ClickStorager
+		queue   chan storage.Click
+		done    chan struct{}
+		secret  []byte
+		proxies *realip.Resolver
+	}
+
+	// DailyStats содержит статистику переходов по короткому URL за один день.
+	DailyStats struct {
+		Date           string `json:"date"`            // Дата в формате ГГГГ-ММ-ДД (UTC)
+		Clicks         int    `json:"clicks"`          // Количество переходов
+		UniqueVisitors int    `json:"unique_visitors"` // Количество уникальных посетителей
+	}
+
+	// Stats содержит сводную статистику переходов по короткому URL.
+	Stats struct {
+		ShortURL       string       `json:"short_url"`       // Короткий URL
+		Clicks         int          `json:"clicks"`          // Общее количество переходов
+		UniqueVisitors int          `json:"unique_visitors"` // Количество уникальных посетителей
+		Daily          []DailyStats `json:"daily"`           // Распределение переходов по дням
+	}
+)
+
+// NewRecorder создаёт обработчик событий перехода, записывающий их в заданное хранилище.
+// IP-адреса посетителей хешируются с заданным секретом, а если он не задан, со случайным,
+// и тогда посетители до и после перезапуска сервиса считаются разными.
+// IP-адрес посетителя берётся из заголовков, только если запрос получен от доверенного прокси.
+func NewRecorder(s storage.ClickStorager, secret []byte, proxies *realip.Resolver) *Recorder {
+	if len(secret) == 0 {
+		log.Println("Секрет для хеширования IP-адресов посетителей не задан, используется случайный секрет")
+
+		secret = make([]byte, secretLength)
+		_, err := rand.Read(secret)
+		if err != nil {
+			log.Fatalln("Ошибка при создании секрета для хеширования IP-адресов посетителей:", err)
+		}
+	}
+
+	return &Recorder{
+		storage: s,
+		queue:   make(chan storage.Click, ClickQueueSize),
+		done:    make(chan struct{}),
+		secret:  secret,
+		proxies: proxies,
+	}
+}
+
+// Record ставит событие перехода в очередь на запись. Если очередь переполнена, событие отбрасывается.
+func (r *Recorder) Record(c storage.Click) {
+	select {
+	case r.queue <- c:
+	default:
+		log.Println("Очередь событий перехода переполнена, событие для", c.ShortURL, "отброшено")
+	}
+}
+
+// Run запускает в отдельном потоке запись событий перехода в хранилище.
+// После отмены контекста оставшиеся в очереди события записываются в хранилище.
+func (r *Recorder) Run(ctx context.Context) {
+	go r.process(ctx)
+}
+
+// Wait ожидает завершения записи событий перехода после отмены контекста, переданного в Run.
+func (r *Recorder) Wait() {
+	<-r.done
+}
+
+func (r *Recorder) process(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(ClickFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]storage.Click, 0, ClickBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		err := r.storage.AddClicks(context.Background(), batch)
+		if err != nil {
+			log.Println("Ошибка при записи событий перехода в хранилище:", err)
+		}
+		batch = make([]storage.Click, 0, ClickBatchSize)
+	}
+
+	for {
+		select {
+		case c := <-r.queue:
+			batch = append(batch, c)
+			if len(batch) >= ClickBatchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+
+		case <-ctx.Done():
+			for {
+				select {
+				case c := <-r.queue:
+					batch = append(batch, c)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// NewClick создаёт событие перехода по короткому URL на основании данных HTTP-запроса.
+func (r *Recorder) NewClick(req *http.Request, shortURL string, now time.Time) storage.Click {
+	ip := ""
+	if clientIP := r.proxies.ClientIP(req); clientIP != nil {
+		ip = clientIP.String()
+	}
+
+	return storage.Click{
+		ShortURL:    shortURL,
+		Time:        now.UTC(),
+		Referrer:    req.Referer(),
+		UserAgent:   req.UserAgent(),
+		VisitorHash: r.HashIP(ip),
+		Country:     UnknownCountry,
+	}
+}
+
+// HashIP возвращает HMAC-SHA256 IP-адреса клиента с секретом сервиса, чтобы не хранить сам адрес.
+// Без секрета адрес нельзя восстановить перебором всех возможных адресов.
+func (r *Recorder) HashIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Summarize рассчитывает сводную статистику по списку событий перехода.
+func Summarize(shortURL string, clicks []storage.Click) Stats {
+	result := Stats{ShortURL: shortURL, Clicks: len(clicks), Daily: make([]DailyStats, 0)}
+
+	visitors := map[string]struct{}{}
+	days := map[string]*DailyStats{}
+	dayVisitors := map[string]map[string]struct{}{}
+
+	for _, c := range clicks {
+		visitors[c.VisitorHash] = struct{}{}
+
+		date := c.Time.UTC().Format(dayLayout)
+		day, ok := days[date]
+		if !ok {
+			day = &DailyStats{Date: date}
+			days[date] = day
+			dayVisitors[date] = map[string]struct{}{}
+		}
+
+		day.Clicks++
+		dayVisitors[date][c.VisitorHash] = struct{}{}
+	}
+
+	result.UniqueVisitors = len(visitors)
+	for date, day := range days {
+		day.UniqueVisitors = len(dayVisitors[date])
+		result.Daily = append(result.Daily, *day)
+	}
+
+	sort.Slice(result.Daily, func(i, j int) bool {
+		return result.Daily[i].Date < result.Daily[j].Date
+	})
+
+	return result
+}
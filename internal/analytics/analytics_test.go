@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/StainlessSteelSnake/shurl/internal/realip"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+func TestSummarize(t *testing.T) {
+	day1 := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		clicks []storage.Click
+		want   Stats
+	}{
+		{
+			"Нет переходов",
+			nil,
+			Stats{ShortURL: "dummy", Daily: []DailyStats{}},
+		},
+		{
+			"Переходы за два дня",
+			[]storage.Click{
+				{ShortURL: "dummy", Time: day2, VisitorHash: "a"},
+				{ShortURL: "dummy", Time: day1, VisitorHash: "a"},
+				{ShortURL: "dummy", Time: day1.Add(time.Hour), VisitorHash: "b"},
+				{ShortURL: "dummy", Time: day1.Add(2 * time.Hour), VisitorHash: "a"},
+			},
+			Stats{
+				ShortURL:       "dummy",
+				Clicks:         4,
+				UniqueVisitors: 2,
+				Daily: []DailyStats{
+					{Date: "2023-03-01", Clicks: 3, UniqueVisitors: 2},
+					{Date: "2023-03-02", Clicks: 1, UniqueVisitors: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Summarize("dummy", tt.clicks))
+		})
+	}
+}
+
+func TestRecorder_NewClick(t *testing.T) {
+	proxies, err := realip.NewResolver([]string{"10.0.0.1"})
+	require.NoError(t, err)
+
+	r := NewRecorder(storage.NewMemoryStorage(), []byte("secret"), proxies)
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		wantIP     string
+	}{
+		{"Прямой запрос", "192.168.1.10:1234", "", "192.168.1.10"},
+		{"Подменённый заголовок X-Real-IP", "192.168.1.10:1234", "8.8.8.8", "192.168.1.10"},
+		{"Запрос через доверенный прокси", "10.0.0.1:1234", "192.168.1.20", "192.168.1.20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/dummy", nil)
+			request.RemoteAddr = tt.remoteAddr
+			request.Header.Set("Referer", "https://ya.ru")
+			request.Header.Set("User-Agent", "test-agent")
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			click := r.NewClick(request, "dummy", now)
+
+			assert.Equal(t, "dummy", click.ShortURL)
+			assert.True(t, now.Equal(click.Time))
+			assert.Equal(t, "https://ya.ru", click.Referrer)
+			assert.Equal(t, "test-agent", click.UserAgent)
+			assert.Equal(t, r.HashIP(tt.wantIP), click.VisitorHash)
+			assert.Equal(t, UnknownCountry, click.Country)
+		})
+	}
+}
+
+func TestRecorder_HashIP(t *testing.T) {
+	r := NewRecorder(storage.NewMemoryStorage(), []byte("secret"), nil)
+	plain := sha256.Sum256([]byte("192.168.1.10"))
+
+	assert.Equal(t, r.HashIP("192.168.1.10"), r.HashIP("192.168.1.10"))
+	assert.NotEqual(t, hex.EncodeToString(plain[:]), r.HashIP("192.168.1.10"), "хеш без секрета восстанавливается перебором адресов")
+	assert.NotEqual(t, r.HashIP("192.168.1.10"), NewRecorder(storage.NewMemoryStorage(), []byte("other"), nil).HashIP("192.168.1.10"))
+	assert.NotEqual(t, r.HashIP("192.168.1.10"), NewRecorder(storage.NewMemoryStorage(), nil, nil).HashIP("192.168.1.10"),
+		"без заданного секрета используется случайный")
+	assert.Empty(t, r.HashIP(""))
+}
+
+func TestRecorder(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := NewRecorder(s, nil, nil)
+	r.Run(ctx)
+
+	for i := 0; i < ClickBatchSize+1; i++ {
+		r.Record(storage.Click{ShortURL: "dummy", Time: time.Now()})
+	}
+
+	cancel()
+	r.Wait()
+
+	clicks, err := s.GetClicks(context.Background(), "dummy")
+	require.NoError(t, err)
+	assert.Len(t, clicks, ClickBatchSize+1)
+}
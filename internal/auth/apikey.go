@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+const (
+	headerAuthorization = "Authorization" // Заголовок HTTP-запроса и ключ метаданных gRPC для передачи ключа API
+	bearerScheme        = "Bearer"        // Схема авторизации, в которой передаётся ключ API
+
+	apiKeyIDLength     = 8  // Длина идентификатора ключа API в байтах
+	apiKeySecretLength = 32 // Длина секрета ключа API в байтах
+	apiKeySeparator    = "."
+)
+
+// Ошибки проверки ключей API.
+var (
+	ErrAPIKeyInvalid   = errors.New("неверный ключ API")
+	ErrAPIKeyRevoked   = errors.New("ключ API отозван")
+	ErrAPIKeysDisabled = errors.New("ключи API не поддерживаются используемым хранилищем")
+	ErrScopeUnknown    = errors.New("неизвестная область действия, допустимы: create, read, update, delete")
+)
+
+// ParseScope проверяет название области действия. Пустое название означает отсутствие ограничений.
+func ParseScope(s string) (Scope, error) {
+	switch scope := Scope(s); scope {
+	case "", ScopeCreate, ScopeRead, ScopeUpdate, ScopeDelete:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrScopeUnknown, s)
+	}
+}
+
+// NewAPIKey создаёт ключ API для пользователя с заданной областью действия.
+// Возвращает запись для сохранения в хранилище, содержащую только хеш секрета, и сам ключ,
+// который передаётся клиенту один раз и не может быть восстановлен.
+func NewAPIKey(user, name string, scope Scope, now time.Time) (storage.APIKey, string, error) {
+	id := make([]byte, apiKeyIDLength)
+	_, err := rand.Read(id)
+	if err != nil {
+		return storage.APIKey{}, "", err
+	}
+
+	secret := make([]byte, apiKeySecretLength)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return storage.APIKey{}, "", err
+	}
+
+	key := storage.APIKey{
+		ID:        hex.EncodeToString(id),
+		User:      user,
+		Name:      name,
+		Hash:      hashAPIKeySecret(base64.RawURLEncoding.EncodeToString(secret)),
+		Scope:     string(scope),
+		CreatedAt: now.UTC(),
+	}
+
+	return key, key.ID + apiKeySeparator + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashAPIKeySecret вычисляет хеш секрета ключа API. Секрет случайный и длинный,
+// поэтому для него достаточно SHA-256 без соли.
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Allowed сообщает, разрешено ли пользователю выполнять действия из заданной области.
+func (u User) Allowed(scope Scope) bool {
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bearerToken извлекает ключ API из значения заголовка авторизации вида "Bearer <ключ>".
+func bearerToken(value string) (string, error) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found || !strings.EqualFold(scheme, bearerScheme) || strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("%w: ожидается заголовок вида '%s <ключ>'", ErrAPIKeyInvalid, bearerScheme)
+	}
+
+	return strings.TrimSpace(token), nil
+}
+
+// authAPIKey авторизовывает пользователя, с которым связан переданный ключ API.
+// Области действия пользователя ограничиваются областью действия ключа.
+func (a *authentication) authAPIKey(ctx context.Context, value string) (User, error) {
+	if a.options.APIKeys == nil {
+		return User{}, ErrAPIKeysDisabled
+	}
+
+	token, err := bearerToken(value)
+	if err != nil {
+		return User{}, err
+	}
+
+	id, secret, found := strings.Cut(token, apiKeySeparator)
+	if !found {
+		return User{}, ErrAPIKeyInvalid
+	}
+
+	key, err := a.options.APIKeys.GetAPIKey(ctx, id)
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		return User{}, ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(secret)), []byte(key.Hash)) != 1 {
+		return User{}, ErrAPIKeyInvalid
+	}
+	if key.Revoked() {
+		return User{}, ErrAPIKeyRevoked
+	}
+
+	scopes := DefaultScopes
+	if key.Scope != "" {
+		scopes = []Scope{Scope(key.Scope)}
+	}
+
+	return User{ID: key.User, Scopes: scopes}, nil
+}
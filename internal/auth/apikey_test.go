@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+func TestAuthenticate_apiKey(t *testing.T) {
+	ctx := context.Background()
+	keys := storage.NewMemoryStorage()
+	a := NewAuth(nil, Options{APIKeys: keys})
+
+	readKey, readSecret, err := NewAPIKey("service", "reports", ScopeRead, time.Now())
+	assert.NoError(t, err)
+	assert.NotContains(t, readKey.Hash, readSecret, "ключ не хранится в открытом виде")
+	assert.NoError(t, keys.AddAPIKey(ctx, readKey))
+
+	fullKey, fullSecret, err := NewAPIKey("service", "", "", time.Now())
+	assert.NoError(t, err)
+	assert.NoError(t, keys.AddAPIKey(ctx, fullKey))
+
+	revokedKey, revokedSecret, err := NewAPIKey("service", "", "", time.Now())
+	assert.NoError(t, err)
+	assert.NoError(t, keys.AddAPIKey(ctx, revokedKey))
+	assert.NoError(t, keys.RevokeAPIKey(ctx, revokedKey.ID, time.Now()))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+		wantScopes    []Scope
+	}{
+		{"Ключ с областью действия", "Bearer " + readSecret, http.StatusOK, []Scope{ScopeRead}},
+		{"Ключ без ограничений", "bearer " + fullSecret, http.StatusOK, DefaultScopes},
+		{"Отозванный ключ", "Bearer " + revokedSecret, http.StatusUnauthorized, nil},
+		{"Неверный секрет", "Bearer " + readKey.ID + ".secret", http.StatusUnauthorized, nil},
+		{"Неизвестный ключ", "Bearer unknown.secret", http.StatusUnauthorized, nil},
+		{"Другая схема авторизации", "Basic " + readSecret, http.StatusUnauthorized, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user User
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Authorization", tt.authorization)
+
+			writer := httptest.NewRecorder()
+			a.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user = UserFromContext(r.Context())
+			})).ServeHTTP(writer, request)
+
+			result := writer.Result()
+			defer result.Body.Close()
+
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+			assert.Empty(t, result.Cookies(), "при авторизации по ключу API cookie не выдаются")
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, "service", user.ID)
+				assert.Equal(t, tt.wantScopes, user.Scopes)
+			}
+		})
+	}
+}
+
+func TestGrpcAuthenticate_apiKey(t *testing.T) {
+	ctx := context.Background()
+	keys := storage.NewMemoryStorage()
+	a := NewAuth(nil, Options{APIKeys: keys})
+
+	key, secret, err := NewAPIKey("service", "", ScopeCreate, time.Now())
+	assert.NoError(t, err)
+	assert.NoError(t, keys.AddAPIKey(ctx, key))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return UserFromContext(ctx), nil
+	}
+
+	user, err := a.GrpcAuthenticate(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+secret)), nil, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, User{ID: "service", Scopes: []Scope{ScopeCreate}}, user)
+
+	_, err = a.GrpcAuthenticate(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+key.ID+".wrong")), nil, &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = NewAuth(nil, Options{}).GrpcAuthenticate(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+secret)), nil, &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "без хранилища ключи API не принимаются")
+}
@@ -3,218 +3,296 @@ package auth
 
 import (
 	"context"
-	"crypto/hmac"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
-	"errors"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
 )
 
 const (
-	secretKey            = "TheKey"         // Секретный ключ для подписи cookie
 	userIDLength         = 5                // Длина идентификатора пользователя для генерации случайной последовательности символов
 	cookieAuthentication = "authentication" // Заголовок HTTP-запроса для передачи данных авторизации
 )
 
 // Типы данных для хранения данных авторизации пользователя.
 type (
-	// authentication хранит данные авторизованного пользователя: его идентификатор, cookie и подпись для cookie.
+	// User содержит данные пользователя, авторизованного при обработке запроса.
+	User struct {
+		ID        string    // Идентификатор пользователя
+		Token     string    // Переданный в запросе или выпущенный при авторизации токен пользователя
+		Scopes    []Scope   // Области действия токена
+		ExpiresAt time.Time // Момент окончания действия токена
+		Provider  string    // Провайдер, через которого выполнен вход, пустой для анонимного пользователя
+	}
+
+	// userContextKey задаёт ключ, по которому данные авторизованного пользователя хранятся в контексте запроса.
+	userContextKey struct{}
+
+	// authentication выполняет авторизацию пользователей. Данные авторизованного пользователя
+	// не хранятся в аутентификаторе, а передаются обработчикам в контексте запроса,
+	// поэтому один аутентификатор обслуживает параллельные запросы разных пользователей.
 	authentication struct {
-		userID     string // Идентификатор пользователя
-		cookieSign []byte // Подпись для подтверждения подлинности cookie
-		cookieFull string // Переданные в HTTP-запросе или сгенерированные при авторизации cookie пользователя
+		keyring *Keyring         // Ключи подписи токенов
+		options Options          // Параметры выпуска токенов
+		now     func() time.Time // Источник текущего времени
+	}
+
+	// Options содержит параметры выпуска и проверки токенов и ссылку на хранилище ключей API.
+	Options struct {
+		TokenTTL      time.Duration          // Срок действия токена, по умолчанию DefaultTokenTTL
+		RefreshBefore time.Duration          // Оставшийся срок действия, при котором токен перевыпускается, по умолчанию четверть TokenTTL
+		Issuer        string                 // Издатель и получатель токенов, по умолчанию DefaultTokenIssuer
+		SecureCookie  bool                   // Признак передачи cookie только по HTTPS
+		APIKeys       storage.APIKeyStorager // Хранилище ключей API, если не задано, ключи API не принимаются
 	}
 
-	// Authenticator позволяет выполнять авторизацию пользователя и получать идентификатор авторизованного пользователя.
+	// Authenticator позволяет выполнять авторизацию пользователя. Данные авторизованного пользователя
+	// доступны обработчикам запроса через UserFromContext.
 	Authenticator interface {
 		// Обработка HTTP-запроса и авторизация пользователя
 		Authenticate(http.Handler) http.Handler
 		// Обработка gRPC-запроса и авторизация пользователя
 		GrpcAuthenticate(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error)
-		// Получение идентификатора авторизованного пользователя
-		GetUserID() string
-		// Получение токена авторизованного пользователя
-		GetTokenID() string
+		// Выпуск токена для пользователя, выполнившего вход через внешнего провайдера, и передача его в cookie
+		SignIn(w http.ResponseWriter, id string, provider string) (User, error)
+		// Удаление cookie с токеном пользователя
+		SignOut(w http.ResponseWriter)
 	}
 )
 
-// NewAuth создаёт экземпляр аутентификатора.
-func NewAuth() Authenticator {
-	a := authentication{"", make([]byte, 0), ""}
-	return &a
-}
-
-// authNew создаёт идентификатор для нового пользователя и соответствующие cookie.
-func (a *authentication) authNew() error {
-	log.Println("Создание ID для нового пользователя")
+// NewAuth создаёт экземпляр аутентификатора, подписывающего токены ключами из заданного набора.
+// Если набор не задан, создаётся случайный ключ, и токены, выданные до перезапуска сервиса, становятся недействительными.
+// Незаданные параметры выпуска токенов заменяются значениями по умолчанию.
+func NewAuth(keyring *Keyring, options Options) Authenticator {
+	if keyring == nil {
+		log.Println("Ключ подписи токенов не задан, используется случайный ключ")
 
-	b := make([]byte, userIDLength)
-	_, err := rand.Read(b)
-	if err != nil {
-		return err
+		key, err := GenerateKey(time.Now())
+		if err != nil {
+			log.Fatalln("Ошибка при создании ключа подписи токенов:", err)
+		}
+		keyring = &Keyring{keys: []Key{key}}
 	}
-	log.Println("Сгенерированы случайные байты для ID нового пользователя:", b)
 
-	a.userID = hex.EncodeToString(b)
-	log.Println("Создан ID для нового пользователя:", a.userID)
+	return &authentication{keyring: keyring, options: options.withDefaults(), now: time.Now}
+}
 
-	a.cookieSign, err = getSign(a.userID)
-	if err != nil {
-		return err
+// withDefaults возвращает параметры, в которых незаданные значения заменены значениями по умолчанию.
+func (o Options) withDefaults() Options {
+	if o.TokenTTL <= 0 {
+		o.TokenTTL = DefaultTokenTTL
 	}
-	log.Println("Сгенерирована подпись в байтах:", a.cookieSign)
+	if o.RefreshBefore <= 0 || o.RefreshBefore >= o.TokenTTL {
+		o.RefreshBefore = o.TokenTTL / 4
+	}
+	if o.Issuer == "" {
+		o.Issuer = DefaultTokenIssuer
+	}
+	return o
+}
 
-	a.cookieFull = a.userID + hex.EncodeToString(a.cookieSign)
-	log.Println("Сгенерированы cookie из ID нового пользователя подписи:", a.cookieFull)
+// NewContext возвращает копию контекста, содержащую данные авторизованного пользователя.
+func NewContext(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
 
-	return nil
+// UserFromContext возвращает данные пользователя, авторизованного при обработке запроса.
+// Если пользователь не авторизован, возвращаются пустые данные.
+func UserFromContext(ctx context.Context) User {
+	user, _ := ctx.Value(userContextKey{}).(User)
+	return user
 }
 
-// authExisting проверяет переданные в HTTP-запросе cookie и авторизовывает пользователя на их основании.
-func (a *authentication) authExisting(cookie string) error {
-	if cookie == "" {
-		return errors.New("не переданы cookie для идентификации пользователя")
+// issue выпускает токен для пользователя с заданными идентификатором, областями действия и провайдером,
+// через которого выполнен вход.
+func (a *authentication) issue(id string, scopes []Scope, provider string, now time.Time) (User, error) {
+	key, err := a.keyring.signingKey()
+	if err != nil {
+		return User{}, err
 	}
-	log.Println("Получены cookie '"+cookieAuthentication+"':", cookie)
 
-	data, err := hex.DecodeString(cookie)
+	expiresAt := time.Unix(now.Add(a.options.TokenTTL).Unix(), 0)
+	token, err := issueToken(key, claims{
+		Subject:   id,
+		Issuer:    a.options.Issuer,
+		Audience:  a.options.Issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+		Scopes:    scopes,
+		Provider:  provider,
+	})
 	if err != nil {
-		return err
+		return User{}, err
 	}
-	log.Println("Cookie расшифрованы в следующие байты:", data)
+	log.Println("Выпущен токен для пользователя", id, "ключом", key.ID, "до", expiresAt.Format(time.RFC3339))
 
-	if len(cookie) < userIDLength*2 {
-		return errors.New("неправильная длина cookie")
-	}
-	id := cookie[:userIDLength*2]
-	log.Println("Из cookie извлечён ID пользователя:", id)
-	if id == "" {
-		return errors.New("неправильная длина ID пользователя")
+	return User{ID: id, Token: token, Scopes: scopes, ExpiresAt: expiresAt, Provider: provider}, nil
+}
+
+// authNew создаёт идентификатор для нового пользователя и выпускает для него токен.
+func (a *authentication) authNew(now time.Time) (User, error) {
+	b := make([]byte, userIDLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return User{}, err
 	}
 
-	signReceived := data[userIDLength:]
-	log.Println("Из cookie извлечена подпись:", signReceived)
+	id := hex.EncodeToString(b)
+	log.Println("Создан ID для нового пользователя:", id)
+
+	return a.issue(id, DefaultScopes, "", now)
+}
 
-	signCalculated, err := getSign(id)
+// authExisting проверяет переданный токен и авторизовывает пользователя на его основании.
+func (a *authentication) authExisting(token string, now time.Time) (User, error) {
+	c, err := parseToken(token, a.keyring, a.options.Issuer, now)
 	if err != nil {
-		return err
+		return User{}, err
 	}
-	log.Println("Рассчитана подпись для полученного ID пользователя:", signCalculated)
 
-	if !hmac.Equal(signReceived, signCalculated) {
-		return errors.New("в cookie передана неправильная подпись для ID пользователя")
+	return User{ID: c.Subject, Token: token, Scopes: c.Scopes, ExpiresAt: time.Unix(c.ExpiresAt, 0), Provider: c.Provider}, nil
+}
+
+// authenticate авторизовывает пользователя по переданному токену. Если токен не передан или неверен,
+// создаётся новый пользователь, а если срок действия токена скоро истекает, токен перевыпускается.
+// Возвращает признак того, что пользователю выпущен новый токен.
+func (a *authentication) authenticate(token string, source string) (User, bool, error) {
+	now := a.now()
+
+	if token != "" {
+		user, err := a.authExisting(token, now)
+		if err == nil {
+			if user.ExpiresAt.Sub(now) > a.options.RefreshBefore {
+				return user, false, nil
+			}
+
+			refreshed, err := a.issue(user.ID, user.Scopes, user.Provider, now)
+			if err != nil {
+				log.Println("Ошибка при перевыпуске токена пользователя:", err)
+				return user, false, nil
+			}
+			return refreshed, true, nil
+		}
+		log.Println("Ошибка при аутентификации пользователя через "+source+" '"+cookieAuthentication+"':", err)
 	}
 
-	log.Println("Рассчитанная и полученная подписи для переданного в cookie ID пользователя совпадают")
-	a.userID = id
-	a.cookieSign = signReceived
-	a.cookieFull = cookie
-	return nil
+	user, err := a.authNew(now)
+	if err != nil {
+		log.Println("Ошибка при создании ID пользователя:", err)
+		return User{}, false, err
+	}
+
+	return user, true, nil
 }
 
 // Authenticate обрабатывает http-запрос на авторизацию пользователя.
-// Затем передаёт запрос следующему обработчику в цепочке.
+// Затем передаёт запрос следующему обработчику в цепочке, добавив в контекст запроса данные пользователя.
 func (a *authentication) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		a.userID = ""
-		a.cookieFull = ""
+		if authorization := r.Header.Get(headerAuthorization); authorization != "" {
+			user, err := a.authAPIKey(r.Context(), authorization)
+			if err != nil {
+				log.Println("Ошибка при аутентификации по ключу API:", err)
+				w.Header().Set("WWW-Authenticate", bearerScheme)
+				http.Error(w, "ошибка при аутентификации по ключу API: "+err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), user)))
+			return
+		}
 
+		token := ""
 		cookie, err := r.Cookie(cookieAuthentication)
 		if err != nil {
 			log.Println("Cookie '" + cookieAuthentication + "' не переданы")
+		} else {
+			token = cookie.Value
 		}
 
-		err = nil
-		if cookie != nil {
-			err = a.authExisting(cookie.Value)
-		}
-		if err != nil {
-			log.Println("Ошибка при аутентификации пользователя через cookie 'authentication':", err)
+		user, issued, _ := a.authenticate(token, "cookie")
+		if issued {
+			a.setCookie(w, user.Token, int(a.options.TokenTTL/time.Second))
 		}
 
-		err = nil
-		if a.cookieFull == "" {
-			err = a.authNew()
-		}
-		if err != nil {
-			log.Println("Ошибка при создании ID пользователя:", err)
-		}
-
-		if a.cookieFull != "" {
-			http.SetCookie(w, &http.Cookie{Name: cookieAuthentication, Value: a.cookieFull})
-		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), user)))
+	})
+}
 
-		next.ServeHTTP(w, r)
+// setCookie передаёт токен пользователя в cookie с заданным сроком хранения в секундах.
+// Отрицательный срок хранения означает удаление cookie.
+func (a *authentication) setCookie(w http.ResponseWriter, token string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieAuthentication,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   a.options.SecureCookie,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
 	})
 }
 
-// Authenticate обрабатывает gRPC-запрос на авторизацию пользователя.
-// Затем передаёт запрос следующему обработчику в цепочке.
+// SignIn выпускает токен для пользователя, выполнившего вход через внешнего провайдера, и передаёт его в cookie.
+// Токен заменяет ранее выданный токен анонимного пользователя.
+func (a *authentication) SignIn(w http.ResponseWriter, id string, provider string) (User, error) {
+	user, err := a.issue(id, DefaultScopes, provider, a.now())
+	if err != nil {
+		return User{}, err
+	}
+	log.Println("Пользователь", id, "выполнил вход через", provider)
+
+	a.setCookie(w, user.Token, int(a.options.TokenTTL/time.Second))
+	return user, nil
+}
+
+// SignOut удаляет cookie с токеном пользователя. При следующем запросе пользователь авторизуется как новый анонимный.
+func (a *authentication) SignOut(w http.ResponseWriter) {
+	a.setCookie(w, "", -1)
+}
+
+// GrpcAuthenticate обрабатывает gRPC-запрос на авторизацию пользователя.
+// Затем передаёт запрос следующему обработчику в цепочке, добавив в контекст запроса данные пользователя.
 func (a *authentication) GrpcAuthenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	a.userID = ""
-	a.cookieFull = ""
+	if authorization := metadata.ValueFromIncomingContext(ctx, strings.ToLower(headerAuthorization)); len(authorization) != 0 {
+		user, err := a.authAPIKey(ctx, authorization[0])
+		if err != nil {
+			log.Println("Ошибка при аутентификации по ключу API:", err)
+			return nil, status.Error(codes.Unauthenticated, "Ошибка при аутентификации по ключу API: "+err.Error())
+		}
 
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		log.Println("Метаданные '" + cookieAuthentication + "' не переданы")
+		return handler(NewContext(ctx, user), req)
 	}
 
-	tokens := md.Get(cookieAuthentication)
+	token := ""
+	tokens := metadata.ValueFromIncomingContext(ctx, cookieAuthentication)
 	if len(tokens) == 0 {
 		log.Println("Метаданные '" + cookieAuthentication + "' не переданы")
 	} else {
-		err := a.authExisting(tokens[0])
-		if err != nil {
-			log.Println("Ошибка при аутентификации пользователя через метаданные 'authentication':", err)
-		}
+		token = tokens[0]
 	}
 
-	if a.cookieFull == "" {
-		err := a.authNew()
-		if err != nil {
-			log.Println("Ошибка при создании ID пользователя:", err)
-		}
-	}
-
-	if a.cookieFull == "" {
+	user, issued, err := a.authenticate(token, "метаданные")
+	if err != nil {
 		err := status.Error(codes.Unauthenticated, "Ошибка при аутентификации пользователя")
 		return nil, err
 	}
 
-	md.Set(cookieAuthentication, a.cookieFull)
-
-	return handler(ctx, req)
-}
-
-// GetUserID возвращает идентификатор авторизованного пользователя.
-func (a *authentication) GetUserID() string {
-	return a.userID
-}
-
-// GetUserToken возвращает токен авторизованного пользователя.
-func (a *authentication) GetTokenID() string {
-	return a.cookieFull
-}
-
-// getSign создаёт подпись для переданного идентификатора пользователя
-// по алгоритму SHA-256 с использованием секретного ключа.
-func getSign(id string) ([]byte, error) {
-	if id == "" {
-		return nil, errors.New("не задан user ID пользователя")
-	}
-
-	h := hmac.New(sha256.New, []byte(secretKey))
-	_, err := h.Write([]byte(id))
-	if err != nil {
-		return nil, err
+	if issued {
+		err = grpc.SetHeader(ctx, metadata.Pairs(cookieAuthentication, user.Token))
+		if err != nil {
+			log.Println("Ошибка при передаче токена в метаданных ответа:", err)
+		}
 	}
 
-	return h.Sum(nil), nil
+	return handler(NewContext(ctx, user), req)
 }
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// KeyringCheckInterval задаёт периодичность проверки изменений файла с ключами подписи.
+	KeyringCheckInterval = 10 * time.Second
+
+	keyIDLength     = 4  // Длина идентификатора ключа подписи в байтах
+	keySecretLength = 32 // Длина секрета ключа подписи в байтах
+)
+
+// Ошибки загрузки ключей подписи.
+var (
+	ErrNoSigningKey = errors.New("в наборе нет ключа для подписи токенов")
+	ErrInvalidKey   = errors.New("неверный ключ подписи")
+)
+
+// Типы данных для хранения ключей подписи токенов пользователей.
+type (
+	// Key содержит ключ подписи токенов. Ключ, помеченный только для проверки, не используется для подписи новых токенов,
+	// но токены, подписанные им ранее, остаются действительными.
+	Key struct {
+		ID         string    `json:"id"`                    // Идентификатор ключа, передаваемый в токене
+		Secret     []byte    `json:"secret"`                // Секрет для подписи, в файле хранится в base64
+		CreatedAt  time.Time `json:"created_at"`            // Момент создания ключа
+		VerifyOnly bool      `json:"verify_only,omitempty"` // Признак ключа только для проверки
+	}
+
+	// keyringFile описывает структуру файла с ключами подписи.
+	keyringFile struct {
+		Keys []Key `json:"keys"`
+	}
+
+	// Keyring содержит набор ключей подписи токенов. Новые токены подписываются последним ключом, не помеченным
+	// только для проверки, а токены проверяются ключом, идентификатор которого указан в токене.
+	// Набор, загруженный из файла, перечитывается при изменении файла, см. ReloadProcess.
+	Keyring struct {
+		filePath string
+		locker   sync.RWMutex
+		keys     []Key
+		info     os.FileInfo
+	}
+)
+
+// NewKeyring создаёт набор из заданных ключей подписи.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	err := validateKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keyring{keys: keys}, nil
+}
+
+// NewSecretKeyring создаёт набор из одного ключа подписи с заданным секретом.
+// Идентификатор ключа вычисляется по секрету, поэтому при смене секрета меняется и идентификатор.
+func NewSecretKeyring(secret string) *Keyring {
+	sum := sha256.Sum256([]byte(secret))
+	return &Keyring{keys: []Key{{ID: hex.EncodeToString(sum[:keyIDLength]), Secret: []byte(secret)}}}
+}
+
+// LoadKeyring загружает набор ключей подписи из файла в формате JSON.
+func LoadKeyring(filePath string) (*Keyring, error) {
+	k := &Keyring{filePath: filePath}
+
+	_, err := k.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// GenerateKey создаёт ключ подписи со случайными идентификатором и секретом.
+func GenerateKey(now time.Time) (Key, error) {
+	id := make([]byte, keyIDLength)
+	_, err := rand.Read(id)
+	if err != nil {
+		return Key{}, err
+	}
+
+	secret := make([]byte, keySecretLength)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: hex.EncodeToString(id), Secret: secret, CreatedAt: now.UTC()}, nil
+}
+
+// RotateKeyring добавляет в файл с ключами подписи новый ключ и помечает прежние ключи только для проверки.
+// Если файл не существует, он создаётся. Возвращает новый ключ.
+func RotateKeyring(filePath string, now time.Time) (Key, error) {
+	keys, err := readKeyringFile(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Key{}, err
+	}
+
+	key, err := GenerateKey(now)
+	if err != nil {
+		return Key{}, err
+	}
+
+	for i := range keys {
+		keys[i].VerifyOnly = true
+	}
+	keys = append(keys, key)
+
+	err = writeKeyringFile(filePath, keys)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return key, nil
+}
+
+// Reload перечитывает файл с ключами подписи, если он изменился или был заменён с момента прошлой загрузки.
+// Возвращает признак того, что набор был перезагружен. При ошибке чтения сохраняется прежний набор.
+func (k *Keyring) Reload() (bool, error) {
+	info, err := os.Stat(k.filePath)
+	if err != nil {
+		return false, err
+	}
+
+	k.locker.RLock()
+	unchanged := k.info != nil && os.SameFile(k.info, info) &&
+		info.ModTime().Equal(k.info.ModTime()) && info.Size() == k.info.Size()
+	k.locker.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	keys, err := readKeyringFile(k.filePath)
+	if err != nil {
+		return false, err
+	}
+
+	err = validateKeys(keys)
+	if err != nil {
+		return false, err
+	}
+
+	k.locker.Lock()
+	k.keys = keys
+	k.info = info
+	k.locker.Unlock()
+
+	log.Println("Загружены ключи подписи из файла", k.filePath, ", ключей:", len(keys))
+	return true, nil
+}
+
+// ReloadProcess периодически проверяет изменения файла с ключами подписи и перечитывает его.
+func (k *Keyring) ReloadProcess(ctx context.Context, interval time.Duration) {
+	if k.filePath == "" {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if _, err := k.Reload(); err != nil {
+				log.Println("Ошибка при загрузке ключей подписи:", err)
+			}
+		}
+	}()
+}
+
+// signingKey возвращает ключ для подписи новых токенов.
+func (k *Keyring) signingKey() (Key, error) {
+	k.locker.RLock()
+	defer k.locker.RUnlock()
+
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		if !k.keys[i].VerifyOnly {
+			return k.keys[i], nil
+		}
+	}
+
+	return Key{}, ErrNoSigningKey
+}
+
+// key возвращает ключ для проверки токена по его идентификатору.
+func (k *Keyring) key(id string) (Key, bool) {
+	k.locker.RLock()
+	defer k.locker.RUnlock()
+
+	for _, key := range k.keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+
+	return Key{}, false
+}
+
+// validateKeys проверяет, что у ключей заданы секреты и уникальные идентификаторы и что есть ключ для подписи.
+func validateKeys(keys []Key) error {
+	ids := make(map[string]bool, len(keys))
+	signing := false
+
+	for _, key := range keys {
+		if key.ID == "" || len(key.Secret) == 0 {
+			return fmt.Errorf("%w: не задан идентификатор или секрет", ErrInvalidKey)
+		}
+		if ids[key.ID] {
+			return fmt.Errorf("%w: повторяется идентификатор %s", ErrInvalidKey, key.ID)
+		}
+		ids[key.ID] = true
+		signing = signing || !key.VerifyOnly
+	}
+
+	if !signing {
+		return ErrNoSigningKey
+	}
+
+	return nil
+}
+
+// readKeyringFile читает ключи подписи из файла.
+func readKeyringFile(filePath string) ([]Key, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var content keyringFile
+	err = json.NewDecoder(f).Decode(&content)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.Keys, nil
+}
+
+// writeKeyringFile записывает ключи подписи во временный файл и заменяет им файл с ключами,
+// чтобы экземпляры сервиса не прочитали файл, записанный частично.
+func writeKeyringFile(filePath string, keys []Key) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(keyringFile{Keys: keys})
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filePath)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// authenticateToken выполняет запрос с заданным токеном и возвращает данные авторизованного пользователя.
+func authenticateToken(a Authenticator, token string) User {
+	var user User
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		request.AddCookie(&http.Cookie{Name: cookieAuthentication, Value: token})
+	}
+
+	a.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = UserFromContext(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), request)
+
+	return user
+}
+
+func TestRotateKeyring(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "keys.json")
+	now := time.Now()
+
+	first, err := RotateKeyring(filePath, now)
+	assert.NoError(t, err)
+
+	keyring, err := LoadKeyring(filePath)
+	assert.NoError(t, err)
+	key, err := keyring.signingKey()
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, key.ID, "ключ создаётся, если файла не было")
+
+	second, err := RotateKeyring(filePath, now)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	reloaded, err := keyring.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+
+	key, err = keyring.signingKey()
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID, key.ID, "новые токены подписываются новым ключом")
+
+	key, ok := keyring.key(first.ID)
+	assert.True(t, ok)
+	assert.True(t, key.VerifyOnly, "прежний ключ остаётся только для проверки")
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []Key
+		wantErr error
+	}{
+		{"Ключ для подписи", []Key{{ID: "a", Secret: []byte("secret")}}, nil},
+		{"Нет ключей", nil, ErrNoSigningKey},
+		{"Только ключи для проверки", []Key{{ID: "a", Secret: []byte("secret"), VerifyOnly: true}}, ErrNoSigningKey},
+		{"Не задан секрет", []Key{{ID: "a"}}, ErrInvalidKey},
+		{"Повторяется идентификатор", []Key{{ID: "a", Secret: []byte("1")}, {ID: "a", Secret: []byte("2")}}, ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys...)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthenticate_keyRotation(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "keys.json")
+	first, err := RotateKeyring(filePath, time.Now())
+	assert.NoError(t, err)
+
+	keyring, err := LoadKeyring(filePath)
+	assert.NoError(t, err)
+	a := NewAuth(keyring, Options{})
+
+	user := authenticateToken(a, "")
+	assert.NotEmpty(t, user.ID)
+	assert.True(t, strings.HasPrefix(user.Token, tokenVersion+tokenSeparator+first.ID+tokenSeparator), "в токене передаётся идентификатор ключа")
+
+	second, err := RotateKeyring(filePath, time.Now())
+	assert.NoError(t, err)
+	_, err = keyring.Reload()
+	assert.NoError(t, err)
+
+	assert.Equal(t, user, authenticateToken(a, user.Token), "токен, подписанный прежним ключом, остаётся действительным")
+	assert.True(t, strings.HasPrefix(authenticateToken(a, "").Token, tokenVersion+tokenSeparator+second.ID+tokenSeparator))
+
+	other := authenticateToken(NewAuth(NewSecretKeyring("secret"), Options{}), "")
+	assert.NotEqual(t, other.ID, authenticateToken(a, other.Token).ID, "токен, подписанный неизвестным ключом, не принимается")
+
+	forged := user.Token[:strings.LastIndex(user.Token, tokenSeparator)+1] + strings.Repeat("A", 43)
+	assert.NotEqual(t, user.ID, authenticateToken(a, forged).ID, "токен с неверной подписью не принимается")
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTokenTTL задаёт срок действия токена пользователя по умолчанию.
+	DefaultTokenTTL = 30 * 24 * time.Hour
+	// DefaultTokenIssuer задаёт издателя и получателя токенов по умолчанию.
+	DefaultTokenIssuer = "shurl"
+
+	tokenVersion   = "v2" // Версия формата токена, токены без версии прежнего формата не принимаются
+	tokenSeparator = "."  // Разделитель частей токена
+	tokenParts     = 4    // Количество частей токена: версия, идентификатор ключа, данные и подпись
+)
+
+// Области действия токена.
+const (
+	ScopeCreate Scope = "create" // Создание коротких URL
+	ScopeRead   Scope = "read"   // Получение коротких URL пользователя и статистики переходов
+	ScopeUpdate Scope = "update" // Изменение исходного URL и параметров существующих коротких URL пользователя
+	ScopeDelete Scope = "delete" // Удаление и восстановление коротких URL пользователя
+)
+
+// Ошибки проверки токена.
+var (
+	ErrTokenMalformed = errors.New("неправильный формат токена")
+	ErrTokenVersion   = errors.New("неподдерживаемая версия токена")
+	ErrTokenSignature = errors.New("неправильная подпись токена")
+	ErrTokenExpired   = errors.New("истёк срок действия токена")
+	ErrTokenClaims    = errors.New("неправильные данные токена")
+)
+
+// Типы данных для выпуска и проверки токенов.
+type (
+	// Scope задаёт область действия токена.
+	Scope string
+
+	// claims содержит данные, передаваемые в токене.
+	claims struct {
+		Subject   string  `json:"sub"`           // Идентификатор пользователя
+		Issuer    string  `json:"iss"`           // Издатель токена
+		Audience  string  `json:"aud"`           // Получатель токена
+		IssuedAt  int64   `json:"iat"`           // Момент выпуска токена в секундах Unix
+		ExpiresAt int64   `json:"exp"`           // Момент окончания действия токена в секундах Unix
+		Scopes    []Scope `json:"scp,omitempty"` // Области действия токена
+		Provider  string  `json:"idp,omitempty"` // Провайдер, через которого пользователь выполнил вход
+	}
+)
+
+// DefaultScopes задаёт области действия токенов, выдаваемых пользователям.
+var DefaultScopes = []Scope{ScopeCreate, ScopeRead, ScopeUpdate, ScopeDelete}
+
+// issueToken выпускает токен с заданными данными, подписанный ключом key.
+// Токен имеет вид v2.<идентификатор ключа>.<данные в base64url>.<подпись в base64url>.
+func issueToken(key Key, c claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	signed := tokenVersion + tokenSeparator + key.ID + tokenSeparator + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + tokenSeparator + base64.RawURLEncoding.EncodeToString(getSign(signed, key.Secret)), nil
+}
+
+// parseToken проверяет версию, подпись, издателя, получателя и срок действия токена и возвращает его данные.
+// Ключ для проверки подписи выбирается из набора по идентификатору, указанному в токене.
+func parseToken(token string, keyring *Keyring, issuer string, now time.Time) (claims, error) {
+	parts := strings.Split(token, tokenSeparator)
+	if len(parts) != tokenParts {
+		return claims{}, ErrTokenMalformed
+	}
+	if parts[0] != tokenVersion {
+		return claims{}, fmt.Errorf("%w: %s", ErrTokenVersion, parts[0])
+	}
+
+	key, ok := keyring.key(parts[1])
+	if !ok {
+		return claims{}, fmt.Errorf("%w: неизвестный ключ %s", ErrTokenSignature, parts[1])
+	}
+
+	sign, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return claims{}, ErrTokenMalformed
+	}
+
+	signed := token[:len(token)-len(parts[3])-len(tokenSeparator)]
+	if !hmac.Equal(sign, getSign(signed, key.Secret)) {
+		return claims{}, ErrTokenSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims{}, ErrTokenMalformed
+	}
+
+	var c claims
+	err = json.Unmarshal(payload, &c)
+	if err != nil {
+		return claims{}, ErrTokenMalformed
+	}
+
+	if c.Subject == "" || c.Issuer != issuer || c.Audience != issuer {
+		return claims{}, ErrTokenClaims
+	}
+	if !now.Before(time.Unix(c.ExpiresAt, 0)) {
+		return claims{}, ErrTokenExpired
+	}
+
+	return c, nil
+}
+
+// getSign создаёт подпись для переданных данных по алгоритму HMAC-SHA-256 с использованием секрета ключа подписи.
+func getSign(data string, secret []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
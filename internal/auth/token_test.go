@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func Test_parseToken(t *testing.T) {
+	key := Key{ID: "key1", Secret: []byte("secret")}
+	keyring, err := NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	valid := claims{
+		Subject:   "0123456789",
+		Issuer:    DefaultTokenIssuer,
+		Audience:  DefaultTokenIssuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+		Scopes:    DefaultScopes,
+	}
+
+	issue := func(c claims) string {
+		token, err := issueToken(key, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	tampered := func(token string) string {
+		parts := strings.Split(token, tokenSeparator)
+		parts[2] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"9999999999","iss":"shurl","aud":"shurl","exp":9999999999}`))
+		return strings.Join(parts, tokenSeparator)
+	}
+	withClaims := func(change func(c *claims)) string {
+		c := valid
+		change(&c)
+		return issue(c)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"Действующий токен", issue(valid), nil},
+		{"Истёк срок действия", withClaims(func(c *claims) { c.ExpiresAt = now.Unix() }), ErrTokenExpired},
+		{"Другой издатель", withClaims(func(c *claims) { c.Issuer = "other" }), ErrTokenClaims},
+		{"Другой получатель", withClaims(func(c *claims) { c.Audience = "other" }), ErrTokenClaims},
+		{"Не задан пользователь", withClaims(func(c *claims) { c.Subject = "" }), ErrTokenClaims},
+		{"Изменены данные", tampered(issue(valid)), ErrTokenSignature},
+		{"Неизвестная версия", "v9" + strings.TrimPrefix(issue(valid), tokenVersion), ErrTokenVersion},
+		{"Токен прежнего формата", "key1.0123456789abcdef", ErrTokenMalformed},
+		{"Подпись не в base64url", issue(valid) + "!", ErrTokenMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseToken(tt.token, keyring, DefaultTokenIssuer, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, valid, c)
+		})
+	}
+}
+
+func TestAuthenticate_refresh(t *testing.T) {
+	now := time.Now()
+	a := NewAuth(nil, Options{TokenTTL: 4 * time.Hour, RefreshBefore: time.Hour, SecureCookie: true}).(*authentication)
+	a.now = func() time.Time { return now }
+
+	serve := func(token string) (User, *http.Response) {
+		var user User
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			request.AddCookie(&http.Cookie{Name: cookieAuthentication, Value: token})
+		}
+
+		writer := httptest.NewRecorder()
+		a.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user = UserFromContext(r.Context())
+		})).ServeHTTP(writer, request)
+
+		return user, writer.Result()
+	}
+
+	user, result := serve("")
+	assert.Equal(t, DefaultScopes, user.Scopes)
+	cookies := result.Cookies()
+	if assert.Len(t, cookies, 1, "новому пользователю выдаётся cookie") {
+		assert.Equal(t, user.Token, cookies[0].Value)
+		assert.Equal(t, 4*60*60, cookies[0].MaxAge)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+		assert.Equal(t, "/", cookies[0].Path)
+	}
+
+	now = now.Add(2 * time.Hour)
+	same, result := serve(user.Token)
+	assert.Equal(t, user, same)
+	assert.Empty(t, result.Cookies(), "cookie не перевыпускается, пока срок действия токена не подходит к концу")
+
+	now = now.Add(90 * time.Minute)
+	refreshed, result := serve(user.Token)
+	assert.Equal(t, user.ID, refreshed.ID, "при перевыпуске токена пользователь сохраняется")
+	assert.NotEqual(t, user.Token, refreshed.Token)
+	assert.True(t, refreshed.ExpiresAt.After(user.ExpiresAt))
+	if assert.Len(t, result.Cookies(), 1) {
+		assert.Equal(t, refreshed.Token, result.Cookies()[0].Value)
+	}
+
+	now = now.Add(5 * time.Hour)
+	expired, result := serve(refreshed.Token)
+	assert.NotEqual(t, user.ID, expired.ID, "по токену с истёкшим сроком действия создаётся новый пользователь")
+	assert.Len(t, result.Cookies(), 1)
+}
+
+func TestAuthenticate_explicitScopes(t *testing.T) {
+	now := time.Now()
+	a := NewAuth(nil, Options{}).(*authentication)
+	key, err := a.keyring.signingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := issueToken(key, claims{
+		Subject:   "0123456789",
+		Issuer:    DefaultTokenIssuer,
+		Audience:  DefaultTokenIssuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+		Scopes:    []Scope{ScopeCreate, ScopeRead, ScopeDelete},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := a.authExisting(token, now)
+	assert.NoError(t, err)
+	assert.False(t, user.Allowed(ScopeUpdate), "токену разрешены только явно указанные в нём области действия")
+
+	issued, err := a.authNew(now)
+	assert.NoError(t, err)
+	c, err := parseToken(issued.Token, a.keyring, DefaultTokenIssuer, now)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultScopes, c.Scopes, "новые токены выпускаются с явным списком областей действия")
+}
+
+func TestSignIn(t *testing.T) {
+	now := time.Now()
+	a := NewAuth(nil, Options{TokenTTL: 4 * time.Hour, RefreshBefore: time.Hour}).(*authentication)
+	a.now = func() time.Time { return now }
+
+	writer := httptest.NewRecorder()
+	user, err := a.SignIn(writer, "named", "https://idp.example")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://idp.example", user.Provider)
+	cookies := writer.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, user.Token, cookies[0].Value)
+	}
+
+	now = now.Add(3*time.Hour + 30*time.Minute)
+	refreshed, issued, err := a.authenticate(user.Token, "cookie")
+	assert.NoError(t, err)
+	assert.True(t, issued)
+	assert.Equal(t, "named", refreshed.ID)
+	assert.Equal(t, user.Provider, refreshed.Provider, "при перевыпуске токена провайдер сохраняется")
+
+	writer = httptest.NewRecorder()
+	a.SignOut(writer)
+	cookies = writer.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Empty(t, cookies[0].Value)
+		assert.Negative(t, cookies[0].MaxAge, "при выходе cookie удаляется")
+	}
+}
+
+func TestGrpcAuthenticate(t *testing.T) {
+	a := NewAuth(nil, Options{})
+	httpUser := authenticateToken(a, "")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return UserFromContext(ctx), nil
+	}
+	call := func(token string) User {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(cookieAuthentication, token))
+		}
+		user, err := a.GrpcAuthenticate(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		assert.NoError(t, err)
+		return user.(User)
+	}
+
+	assert.Equal(t, httpUser, call(httpUser.Token), "токен проверяется одинаково для HTTP и gRPC")
+	assert.NotEqual(t, httpUser.ID, call("").ID)
+}
@@ -4,9 +4,13 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
@@ -19,16 +23,81 @@ const (
 	defaultGrpcServerAddress = "localhost:3200"
 )
 
+// MinShortURLLength и MaxShortURLLength ограничивают длину генерируемых коротких URL.
+// Наибольшая длина определяется размером столбца short_url в БД (varchar(32)).
+const (
+	MinShortURLLength = 4
+	MaxShortURLLength = 32
+)
+
+// ErrShortURLLength возвращается, если длина генерируемых коротких URL выходит за допустимые пределы.
+var ErrShortURLLength = errors.New("недопустимая длина генерируемых коротких URL")
+
+// Duration содержит продолжительность, которая задаётся строкой вида "30s" или "1m30s"
+// в параметрах командной строки, переменных окружения и файле настроек.
+type Duration time.Duration
+
+// Secret содержит секретное значение, которое не выводится в журнал вместе с остальными настройками.
+type Secret string
+
+// List содержит список значений, которые задаются строкой через запятую
+// в параметрах командной строки и переменных окружения и массивом строк в файле настроек.
+type List []string
+
 // Configuration содержит перечень настроек сервиса.
 type Configuration struct {
-	ServerAddress     string `env:"SERVER_ADDRESS" json:"server_address"`           // Адрес HTTP-сервера приложения
-	BaseURL           string `env:"BASE_URL" json:"base_url"`                       // Корневой URL работающего сервиса
-	FileStoragePath   string `env:"FILE_STORAGE_PATH" json:"file_storage_path"`     // Путь к файлу для хранения данных сервиса
-	DatabaseDSN       string `env:"DATABASE_DSN" json:"database_dsn"`               // Строка для подключения к базе данных
-	EnableHTTPS       bool   `env:"ENABLE_HTTPS" json:"enable_https"`               // Признак "включить поддержку HTTPS"
-	ConfigFilePath    string `env:"CONFIG" json:"-"`                                // Путь к файлу с настройками сервиса
-	TrustedSubnet     string `env:"TRUSTED_SUBNET" json:"trusted_subnet"`           // IP-подсеть, из которой разрешены запросы статистики сервиса
-	GrpcServerAddress string `env:"GRPC_SERVER_ADDRESS" json:"grpc_server_address"` // Адрес gRPC-сервера приложения
+	ServerAddress     string `env:"SERVER_ADDRESS" json:"server_address"`                    // Адрес HTTP-сервера приложения
+	BaseURL           string `env:"BASE_URL" json:"base_url"`                                // Корневой URL работающего сервиса
+	FileStoragePath   string `env:"FILE_STORAGE_PATH" json:"file_storage_path"`              // Путь к файлу для хранения данных сервиса
+	DatabaseDSN       string `env:"DATABASE_DSN" json:"database_dsn"`                        // Строка для подключения к базе данных
+	EnableHTTPS       bool   `env:"ENABLE_HTTPS" json:"enable_https"`                        // Признак "включить поддержку HTTPS"
+	ConfigFilePath    string `env:"CONFIG" json:"-"`                                         // Путь к файлу с настройками сервиса
+	TrustedSubnet     string `env:"TRUSTED_SUBNET" json:"trusted_subnet"`                    // IP-подсеть, из которой разрешены запросы статистики сервиса
+	GrpcServerAddress string `env:"GRPC_SERVER_ADDRESS" json:"grpc_server_address"`          // Адрес gRPC-сервера приложения
+	TrustedProxies    List   `env:"TRUSTED_PROXIES" envSeparator:"," json:"trusted_proxies"` // Адреса и IP-подсети обратных прокси, от которых принимаются заголовки X-Real-IP и X-Forwarded-For
+	ShortURLGenerator string `env:"SHORT_URL_GENERATOR" json:"short_url_generator"`          // Способ генерации коротких URL: time, random, sequence или hash
+	ShortURLLength    int    `env:"SHORT_URL_LENGTH" json:"short_url_length"`                // Длина генерируемых коротких URL для способов random и hash, от 4 до 32, 0 - по умолчанию 8
+	ShortURLSalt      string `env:"SHORT_URL_SALT" json:"short_url_salt"`                    // Соль для перемешивания алфавита при способе sequence
+	DatabaseCacheSize int    `env:"DATABASE_CACHE_SIZE" json:"database_cache_size"`          // Количество записей в кэше чтения из БД, 0 - кэш отключён
+
+	DatabaseMinConns          int      `env:"DATABASE_MIN_CONNS" json:"database_min_conns"`                     // Минимальное количество соединений в пуле соединений с БД
+	DatabaseMaxConns          int      `env:"DATABASE_MAX_CONNS" json:"database_max_conns"`                     // Максимальное количество соединений в пуле соединений с БД
+	DatabaseHealthCheckPeriod Duration `env:"DATABASE_HEALTH_CHECK_PERIOD" json:"database_health_check_period"` // Период проверки простаивающих соединений с БД
+	DatabaseAcquireTimeout    Duration `env:"DATABASE_ACQUIRE_TIMEOUT" json:"database_acquire_timeout"`         // Максимальное время ожидания свободного соединения с БД
+
+	RedisAddress    string `env:"REDIS_ADDRESS" json:"redis_address"`         // Адрес Redis-совместимого сервера в виде host:port или redis://...
+	BoltStoragePath string `env:"BOLT_STORAGE_PATH" json:"bolt_storage_path"` // Путь к файлу встроенной БД ключ-значение для хранения данных сервиса
+
+	FileCompactionMinSize int64   `env:"FILE_COMPACTION_MIN_SIZE" json:"file_compaction_min_size"` // Минимальный размер файла хранилища в байтах для автоматического сжатия
+	FileCompactionRatio   float64 `env:"FILE_COMPACTION_RATIO" json:"file_compaction_ratio"`       // Отношение количества строк в файле к количеству коротких URL для автоматического сжатия, 0 - отключено
+	FileSnapshot          bool    `env:"FILE_SNAPSHOT" json:"file_snapshot"`                       // Признак сжатия файла хранилища в файл снимка с журналом изменений после него
+
+	DedupScope string `env:"DEDUP_SCOPE" json:"dedup_scope"` // Область поиска ранее сокращённых URL: global или user
+
+	URLSchemes     List `env:"URL_SCHEMES" envSeparator:"," json:"url_schemes"`           // Разрешённые схемы исходных URL, по умолчанию http и https
+	URLStripParams List `env:"URL_STRIP_PARAMS" envSeparator:"," json:"url_strip_params"` // Удаляемые из исходных URL параметры запроса, по умолчанию utm_* и fbclid
+	URLMaxLength   int  `env:"URL_MAX_LENGTH" json:"url_max_length"`                      // Максимальная длина исходного URL в байтах
+
+	BlocklistPath string `env:"BLOCKLIST_PATH" json:"blocklist_path"` // Путь к файлу со списком заблокированных доменов, перечитывается при изменении
+
+	AnalyticsSecret Secret `env:"ANALYTICS_SECRET" json:"analytics_secret"` // Секрет для хеширования IP-адресов посетителей в статистике переходов
+
+	RestoreGracePeriod Duration `env:"RESTORE_GRACE_PERIOD" json:"restore_grace_period"` // Срок, в течение которого пользователь может восстановить удалённый короткий URL
+
+	DeletionBatchSize     int      `env:"DELETION_BATCH_SIZE" json:"deletion_batch_size"`         // Максимальный размер пакета для массового удаления коротких URL
+	DeletionFlushInterval Duration `env:"DELETION_FLUSH_INTERVAL" json:"deletion_flush_interval"` // Периодичность удаления неполного пакета коротких URL из очереди
+
+	AuthSecret   Secret `env:"AUTH_SECRET" json:"auth_secret"`       // Секрет для подписи токенов пользователей, если не задан файл с ключами подписи
+	AuthKeysPath string `env:"AUTH_KEYS_PATH" json:"auth_keys_path"` // Путь к файлу с ключами подписи токенов пользователей, перечитывается при изменении
+
+	AuthTokenTTL     Duration `env:"AUTH_TOKEN_TTL" json:"auth_token_ttl"`         // Срок действия токенов пользователей
+	AuthTokenRefresh Duration `env:"AUTH_TOKEN_REFRESH" json:"auth_token_refresh"` // Оставшийся срок действия, при котором токен пользователя перевыпускается
+
+	OIDCIssuer       string `env:"OIDC_ISSUER" json:"oidc_issuer"`                  // Идентификатор провайдера OpenID Connect, если не задан, вход через провайдера отключён
+	OIDCClientID     string `env:"OIDC_CLIENT_ID" json:"oidc_client_id"`            // Идентификатор сервиса у провайдера OpenID Connect
+	OIDCClientSecret Secret `env:"OIDC_CLIENT_SECRET" json:"oidc_client_secret"`    // Секрет сервиса у провайдера OpenID Connect
+	OIDCRedirectURL  string `env:"OIDC_REDIRECT_URL" json:"oidc_redirect_url"`      // Адрес возврата от провайдера, по умолчанию <base URL>auth/callback
+	OIDCScopes       List   `env:"OIDC_SCOPES" envSeparator:"," json:"oidc_scopes"` // Дополнительные запрашиваемые у провайдера области доступа, например email
 }
 
 // NewConfiguration создаёт перечень настроек сервиса.
@@ -66,11 +135,24 @@ func NewConfiguration() *Configuration {
 		cfg.BaseURL += "/"
 	}
 
+	if cfg.OIDCIssuer != "" && cfg.OIDCRedirectURL == "" {
+		cfg.OIDCRedirectURL = cfg.BaseURL + "auth/callback"
+	}
+
 	log.Println("Resulting config:", cfg)
 
 	return cfg
 }
 
+// Validate проверяет значения настроек, при которых сервис не может работать.
+func (c *Configuration) Validate() error {
+	if c.ShortURLLength != 0 && (c.ShortURLLength < MinShortURLLength || c.ShortURLLength > MaxShortURLLength) {
+		return fmt.Errorf("%w: %d, допустимо от %d до %d", ErrShortURLLength, c.ShortURLLength, MinShortURLLength, MaxShortURLLength)
+	}
+
+	return nil
+}
+
 func (c *Configuration) fillFromFlags() {
 	flag.StringVar(&c.ServerAddress, "a", "", "string with HTTP-server address")
 	flag.StringVar(&c.GrpcServerAddress, "g", "", "string with gRPC-server address")
@@ -81,6 +163,38 @@ func (c *Configuration) fillFromFlags() {
 	flag.StringVar(&c.ConfigFilePath, "c", "", "path to configuration file")
 	flag.StringVar(&c.ConfigFilePath, "config", "", "path to configuration file")
 	flag.StringVar(&c.TrustedSubnet, "t", "", "trusted subnet that is allowed to check service statistics")
+	flag.Var(&c.TrustedProxies, "trusted-proxies", "comma-separated addresses or subnets of reverse proxies whose X-Real-IP and X-Forwarded-For headers are trusted")
+	flag.StringVar(&c.ShortURLGenerator, "short-url-generator", "", "short URL generation strategy: time, random, sequence or hash")
+	flag.IntVar(&c.ShortURLLength, "short-url-length", 0, "length of short URLs made by random and hash strategies, from 4 to 32 (default 8)")
+	flag.StringVar(&c.ShortURLSalt, "short-url-salt", "", "salt to shuffle the alphabet of the sequence strategy")
+	flag.IntVar(&c.DatabaseCacheSize, "database-cache-size", 0, "number of short URLs kept in the database read cache, 0 disables the cache")
+	flag.IntVar(&c.DatabaseMinConns, "database-min-conns", 0, "minimum number of connections in the database pool")
+	flag.IntVar(&c.DatabaseMaxConns, "database-max-conns", 0, "maximum number of connections in the database pool")
+	flag.Var(&c.DatabaseHealthCheckPeriod, "database-health-check-period", "period of health checks of idle database connections, e.g. 1m")
+	flag.Var(&c.DatabaseAcquireTimeout, "database-acquire-timeout", "maximum time to wait for a free database connection, e.g. 5s")
+	flag.StringVar(&c.RedisAddress, "redis-address", "", "address of a Redis-compatible server as host:port or redis:// URL")
+	flag.StringVar(&c.BoltStoragePath, "bolt-storage-path", "", "path to the embedded key-value database file")
+	flag.Int64Var(&c.FileCompactionMinSize, "file-compaction-min-size", 0, "minimum size in bytes of the storage file to compact it automatically")
+	flag.Float64Var(&c.FileCompactionRatio, "file-compaction-ratio", 0, "ratio of storage file lines to short URLs that triggers compaction, 0 disables automatic compaction")
+	flag.BoolVar(&c.FileSnapshot, "file-snapshot", false, "compact the storage file into a snapshot followed by a log of changes")
+	flag.StringVar(&c.DedupScope, "dedup-scope", "", "scope of searching previously shortened URLs: global or user")
+	flag.Var(&c.URLSchemes, "url-schemes", "comma-separated list of allowed schemes of original URLs, e.g. http,https")
+	flag.Var(&c.URLStripParams, "url-strip-params", "comma-separated list of query parameters removed from original URLs, e.g. utm_*,fbclid")
+	flag.IntVar(&c.URLMaxLength, "url-max-length", 0, "maximum length of original URLs in bytes")
+	flag.StringVar(&c.BlocklistPath, "blocklist-path", "", "path to the file with blocked destination domains, one per line")
+	flag.Var(&c.RestoreGracePeriod, "restore-grace-period", "period during which users can restore deleted short URLs, e.g. 24h")
+	flag.IntVar(&c.DeletionBatchSize, "deletion-batch-size", 0, "maximum number of short URLs deleted in one batch")
+	flag.Var(&c.DeletionFlushInterval, "deletion-flush-interval", "period of deleting an incomplete batch of queued short URLs, e.g. 100ms")
+	flag.Var(&c.AnalyticsSecret, "analytics-secret", "secret to hash visitor IP addresses in click statistics")
+	flag.Var(&c.AuthSecret, "auth-secret", "secret to sign user tokens when no signing keys file is set")
+	flag.StringVar(&c.AuthKeysPath, "auth-keys-path", "", "path to the file with signing keys of user tokens, see 'shortener keys rotate'")
+	flag.Var(&c.AuthTokenTTL, "auth-token-ttl", "lifetime of user tokens, e.g. 720h")
+	flag.Var(&c.AuthTokenRefresh, "auth-token-refresh", "remaining lifetime at which user tokens are reissued, e.g. 168h")
+	flag.StringVar(&c.OIDCIssuer, "oidc-issuer", "", "OpenID Connect provider to sign users in with, sign-in is disabled when empty")
+	flag.StringVar(&c.OIDCClientID, "oidc-client-id", "", "client ID registered at the OpenID Connect provider")
+	flag.Var(&c.OIDCClientSecret, "oidc-client-secret", "client secret registered at the OpenID Connect provider")
+	flag.StringVar(&c.OIDCRedirectURL, "oidc-redirect-url", "", "callback URL registered at the OpenID Connect provider, defaults to <base URL>auth/callback")
+	flag.Var(&c.OIDCScopes, "oidc-scopes", "comma-separated additional scopes requested from the OpenID Connect provider, e.g. email,profile")
 
 	flag.Parse()
 
@@ -144,5 +258,192 @@ func (c *Configuration) fillFromFile() error {
 		c.TrustedSubnet = tmpConfig.TrustedSubnet
 	}
 
+	if tmpConfig.TrustedProxies != nil && c.TrustedProxies == nil {
+		c.TrustedProxies = tmpConfig.TrustedProxies
+	}
+
+	if tmpConfig.ShortURLGenerator != "" && c.ShortURLGenerator == "" {
+		c.ShortURLGenerator = tmpConfig.ShortURLGenerator
+	}
+
+	if tmpConfig.ShortURLLength != 0 && c.ShortURLLength == 0 {
+		c.ShortURLLength = tmpConfig.ShortURLLength
+	}
+
+	if tmpConfig.ShortURLSalt != "" && c.ShortURLSalt == "" {
+		c.ShortURLSalt = tmpConfig.ShortURLSalt
+	}
+
+	if tmpConfig.DatabaseCacheSize != 0 && c.DatabaseCacheSize == 0 {
+		c.DatabaseCacheSize = tmpConfig.DatabaseCacheSize
+	}
+
+	if tmpConfig.DatabaseMinConns != 0 && c.DatabaseMinConns == 0 {
+		c.DatabaseMinConns = tmpConfig.DatabaseMinConns
+	}
+
+	if tmpConfig.DatabaseMaxConns != 0 && c.DatabaseMaxConns == 0 {
+		c.DatabaseMaxConns = tmpConfig.DatabaseMaxConns
+	}
+
+	if tmpConfig.DatabaseHealthCheckPeriod != 0 && c.DatabaseHealthCheckPeriod == 0 {
+		c.DatabaseHealthCheckPeriod = tmpConfig.DatabaseHealthCheckPeriod
+	}
+
+	if tmpConfig.DatabaseAcquireTimeout != 0 && c.DatabaseAcquireTimeout == 0 {
+		c.DatabaseAcquireTimeout = tmpConfig.DatabaseAcquireTimeout
+	}
+
+	if tmpConfig.RedisAddress != "" && c.RedisAddress == "" {
+		c.RedisAddress = tmpConfig.RedisAddress
+	}
+
+	if tmpConfig.BoltStoragePath != "" && c.BoltStoragePath == "" {
+		c.BoltStoragePath = tmpConfig.BoltStoragePath
+	}
+
+	if tmpConfig.FileCompactionMinSize != 0 && c.FileCompactionMinSize == 0 {
+		c.FileCompactionMinSize = tmpConfig.FileCompactionMinSize
+	}
+
+	if tmpConfig.FileCompactionRatio != 0 && c.FileCompactionRatio == 0 {
+		c.FileCompactionRatio = tmpConfig.FileCompactionRatio
+	}
+
+	if tmpConfig.FileSnapshot && !c.FileSnapshot {
+		c.FileSnapshot = true
+	}
+
+	if tmpConfig.DedupScope != "" && c.DedupScope == "" {
+		c.DedupScope = tmpConfig.DedupScope
+	}
+
+	if tmpConfig.URLSchemes != nil && c.URLSchemes == nil {
+		c.URLSchemes = tmpConfig.URLSchemes
+	}
+
+	if tmpConfig.URLStripParams != nil && c.URLStripParams == nil {
+		c.URLStripParams = tmpConfig.URLStripParams
+	}
+
+	if tmpConfig.URLMaxLength != 0 && c.URLMaxLength == 0 {
+		c.URLMaxLength = tmpConfig.URLMaxLength
+	}
+
+	if tmpConfig.BlocklistPath != "" && c.BlocklistPath == "" {
+		c.BlocklistPath = tmpConfig.BlocklistPath
+	}
+
+	if tmpConfig.RestoreGracePeriod != 0 && c.RestoreGracePeriod == 0 {
+		c.RestoreGracePeriod = tmpConfig.RestoreGracePeriod
+	}
+
+	if tmpConfig.DeletionBatchSize != 0 && c.DeletionBatchSize == 0 {
+		c.DeletionBatchSize = tmpConfig.DeletionBatchSize
+	}
+
+	if tmpConfig.DeletionFlushInterval != 0 && c.DeletionFlushInterval == 0 {
+		c.DeletionFlushInterval = tmpConfig.DeletionFlushInterval
+	}
+
+	if tmpConfig.AnalyticsSecret != "" && c.AnalyticsSecret == "" {
+		c.AnalyticsSecret = tmpConfig.AnalyticsSecret
+	}
+
+	if tmpConfig.AuthSecret != "" && c.AuthSecret == "" {
+		c.AuthSecret = tmpConfig.AuthSecret
+	}
+
+	if tmpConfig.AuthKeysPath != "" && c.AuthKeysPath == "" {
+		c.AuthKeysPath = tmpConfig.AuthKeysPath
+	}
+
+	if tmpConfig.AuthTokenTTL != 0 && c.AuthTokenTTL == 0 {
+		c.AuthTokenTTL = tmpConfig.AuthTokenTTL
+	}
+
+	if tmpConfig.AuthTokenRefresh != 0 && c.AuthTokenRefresh == 0 {
+		c.AuthTokenRefresh = tmpConfig.AuthTokenRefresh
+	}
+
+	if tmpConfig.OIDCIssuer != "" && c.OIDCIssuer == "" {
+		c.OIDCIssuer = tmpConfig.OIDCIssuer
+	}
+
+	if tmpConfig.OIDCClientID != "" && c.OIDCClientID == "" {
+		c.OIDCClientID = tmpConfig.OIDCClientID
+	}
+
+	if tmpConfig.OIDCClientSecret != "" && c.OIDCClientSecret == "" {
+		c.OIDCClientSecret = tmpConfig.OIDCClientSecret
+	}
+
+	if tmpConfig.OIDCRedirectURL != "" && c.OIDCRedirectURL == "" {
+		c.OIDCRedirectURL = tmpConfig.OIDCRedirectURL
+	}
+
+	if tmpConfig.OIDCScopes != nil && c.OIDCScopes == nil {
+		c.OIDCScopes = tmpConfig.OIDCScopes
+	}
+
+	return nil
+}
+
+// String возвращает продолжительность в виде строки, например "1m30s".
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Set разбирает продолжительность из параметра командной строки.
+func (d *Duration) Set(value string) error {
+	return d.UnmarshalText([]byte(value))
+}
+
+// MarshalText возвращает продолжительность в виде строки для сохранения в файл настроек.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText разбирает продолжительность из переменной окружения или файла настроек.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// String скрывает секретное значение при выводе настроек в журнал.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return "***"
+}
+
+// Set задаёт секретное значение из параметра командной строки.
+func (s *Secret) Set(value string) error {
+	*s = Secret(value)
+	return nil
+}
+
+// String возвращает список значений в виде строки через запятую.
+func (l List) String() string {
+	return strings.Join(l, ",")
+}
+
+// Set разбирает список значений через запятую из параметра командной строки.
+// Пустая строка задаёт пустой список.
+func (l *List) Set(value string) error {
+	*l = List{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
 	return nil
 }
@@ -1,8 +1,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewConfiguration(t *testing.T) {
@@ -71,3 +77,197 @@ func TestConfiguration_fillFromEnvironment(t *testing.T) {
 		})
 	}
 }
+
+func TestDuration_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Duration
+		wantErr bool
+	}{
+		{"Секунды", "30s", Duration(30 * time.Second), false},
+		{"Минуты и секунды", "1m30s", Duration(90 * time.Second), false},
+		{"Число без единиц измерения", "30", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Duration
+			err := d.UnmarshalText([]byte(tt.text))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if d != tt.want {
+				t.Errorf("UnmarshalText() = %v, want %v", d, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguration_fillFromEnvironmentDuration(t *testing.T) {
+	t.Setenv("DATABASE_HEALTH_CHECK_PERIOD", "1m")
+	t.Setenv("DATABASE_ACQUIRE_TIMEOUT", "5s")
+
+	c := &Configuration{}
+	if err := c.fillFromEnvironment(); err != nil {
+		t.Fatalf("fillFromEnvironment() error = %v", err)
+	}
+
+	if c.DatabaseHealthCheckPeriod != Duration(time.Minute) {
+		t.Errorf("DatabaseHealthCheckPeriod = %v, want %v", c.DatabaseHealthCheckPeriod, time.Minute)
+	}
+	if c.DatabaseAcquireTimeout != Duration(5*time.Second) {
+		t.Errorf("DatabaseAcquireTimeout = %v, want %v", c.DatabaseAcquireTimeout, 5*time.Second)
+	}
+}
+
+func TestConfiguration_fillFromFileDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"database_acquire_timeout": "2s", "database_max_conns": 8}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Configuration{ConfigFilePath: path}
+	if err = c.fillFromFile(); err != nil {
+		t.Fatalf("fillFromFile() error = %v", err)
+	}
+
+	if c.DatabaseAcquireTimeout != Duration(2*time.Second) {
+		t.Errorf("DatabaseAcquireTimeout = %v, want %v", c.DatabaseAcquireTimeout, 2*time.Second)
+	}
+	if c.DatabaseMaxConns != 8 {
+		t.Errorf("DatabaseMaxConns = %v, want %v", c.DatabaseMaxConns, 8)
+	}
+}
+
+func TestConfiguration_fillFromFileDeletion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"deletion_batch_size": 50, "deletion_flush_interval": "500ms"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Configuration{ConfigFilePath: path}
+	if err = c.fillFromFile(); err != nil {
+		t.Fatalf("fillFromFile() error = %v", err)
+	}
+
+	if c.DeletionBatchSize != 50 {
+		t.Errorf("DeletionBatchSize = %v, want %v", c.DeletionBatchSize, 50)
+	}
+	if c.DeletionFlushInterval != Duration(500*time.Millisecond) {
+		t.Errorf("DeletionFlushInterval = %v, want %v", c.DeletionFlushInterval, 500*time.Millisecond)
+	}
+}
+
+func TestSecret_String(t *testing.T) {
+	t.Setenv("AUTH_SECRET", "very-secret")
+
+	c := &Configuration{}
+	if err := c.fillFromEnvironment(); err != nil {
+		t.Fatalf("fillFromEnvironment() error = %v", err)
+	}
+
+	if c.AuthSecret != "very-secret" {
+		t.Errorf("AuthSecret = %q, want %q", c.AuthSecret, "very-secret")
+	}
+	if out := fmt.Sprint(c); strings.Contains(out, "very-secret") {
+		t.Errorf("секрет выводится вместе с настройками: %s", out)
+	}
+}
+
+func TestConfiguration_fillFromEnvironmentList(t *testing.T) {
+	t.Setenv("URL_SCHEMES", "http,https,ftp")
+	t.Setenv("URL_STRIP_PARAMS", "utm_*")
+
+	c := &Configuration{}
+	if err := c.fillFromEnvironment(); err != nil {
+		t.Fatalf("fillFromEnvironment() error = %v", err)
+	}
+
+	if c.URLSchemes.String() != "http,https,ftp" {
+		t.Errorf("URLSchemes = %v, want %v", c.URLSchemes, "http,https,ftp")
+	}
+	if c.URLStripParams.String() != "utm_*" {
+		t.Errorf("URLStripParams = %v, want %v", c.URLStripParams, "utm_*")
+	}
+}
+
+func TestList_Set(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  int
+	}{
+		{"Несколько значений", "http, https", 2},
+		{"Пустые значения пропускаются", "utm_*,,", 1},
+		{"Пустая строка задаёт пустой список", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l List
+			if err := l.Set(tt.value); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if l == nil || len(l) != tt.want {
+				t.Errorf("Set() = %#v, want %v values", l, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguration_fillFromFileOIDC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"oidc_issuer": "https://idp.example", "oidc_client_id": "shurl", "oidc_client_secret": "client-secret", "oidc_scopes": ["email"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Configuration{ConfigFilePath: path, OIDCClientID: "from-flags"}
+	if err = c.fillFromFile(); err != nil {
+		t.Fatalf("fillFromFile() error = %v", err)
+	}
+
+	if c.OIDCIssuer != "https://idp.example" {
+		t.Errorf("OIDCIssuer = %q, want %q", c.OIDCIssuer, "https://idp.example")
+	}
+	if c.OIDCClientID != "from-flags" {
+		t.Errorf("OIDCClientID = %q, want %q", c.OIDCClientID, "from-flags")
+	}
+	if c.OIDCClientSecret != "client-secret" {
+		t.Errorf("OIDCClientSecret = %q, want %q", c.OIDCClientSecret, "client-secret")
+	}
+	if !reflect.DeepEqual(c.OIDCScopes, List{"email"}) {
+		t.Errorf("OIDCScopes = %v, want %v", c.OIDCScopes, List{"email"})
+	}
+	if out := fmt.Sprint(c); strings.Contains(out, "client-secret") {
+		t.Errorf("секрет выводится вместе с настройками: %s", out)
+	}
+}
+
+func TestConfiguration_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		wantErr bool
+	}{
+		{"Длина по умолчанию", 0, false},
+		{"Наименьшая длина", MinShortURLLength, false},
+		{"Наибольшая длина", MaxShortURLLength, false},
+		{"Слишком короткий URL", MinShortURLLength - 1, true},
+		{"Слишком длинный URL", MaxShortURLLength + 1, true},
+		{"Отрицательная длина", -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Configuration{ShortURLLength: tt.length}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrShortURLLength) {
+				t.Errorf("Validate() error = %v, want %v", err, ErrShortURLLength)
+			}
+		})
+	}
+}
@@ -3,29 +3,71 @@ package grpcserv
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"net"
 	"strings"
+	"time"
 
+	"github.com/StainlessSteelSnake/shurl/internal/analytics"
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
 	pb "github.com/StainlessSteelSnake/shurl/internal/grpcserv/proto"
+	"github.com/StainlessSteelSnake/shurl/internal/safety"
 	"github.com/StainlessSteelSnake/shurl/internal/storage"
+	"github.com/StainlessSteelSnake/shurl/internal/urlnorm"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // PostLongUrl обрабатывает gRPC-запрос на сокращение URL, возвращает короткий URL.
 func (s *grpcServer) PostLongUrl(ctx context.Context, req *pb.PostLongUrlRequest) (*pb.PostLongUrlResponse, error) {
 	log.Println("Пришедший в запросе исходный URL:", req.OriginalUrl)
 
-	if req.OriginalUrl == "" {
-		log.Println("Неверный формат URL")
-		return nil, status.Error(codes.InvalidArgument, "неверный формат URL")
+	longURL, err := s.checkURL(ctx, req.OriginalUrl, "original_url")
+	if err != nil {
+		return nil, err
+	}
+
+	var response = pb.PostLongUrlResponse{Token: auth.UserFromContext(ctx).Token}
+
+	expiresAt, err := storage.NewExpiration(timestampOrZero(req.ExpiresAt), req.TtlSeconds, time.Now())
+	if err != nil {
+		log.Println("Ошибка '", err, "' при проверке срока действия URL:", longURL)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	longURL := req.OriginalUrl
+	options := storage.URLOptions{Alias: req.Alias, ExpiresAt: expiresAt}
+	if req.Password != "" {
+		options.PasswordHash, err = storage.HashPassword(req.Password)
+		if err != nil {
+			log.Println("Ошибка '", err, "' при обработке пароля для URL:", longURL)
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
 
-	var response = pb.PostLongUrlResponse{Token: s.auth.GetTokenID()}
+	shortURL, err := s.storage.AddURL(ctx, longURL, auth.UserFromContext(ctx).ID, options)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+
+	if err != nil && errors.Is(err, storage.ErrAliasInvalid) {
+		log.Println("Ошибка '", err, "' при проверке псевдонима:", req.Alias)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err != nil && errors.Is(err, storage.ErrAliasTaken) {
+		log.Println("Псевдоним", req.Alias, "уже занят")
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+
+	if err != nil && errors.Is(err, storage.DBErrorDublicate) && options.Alias != "" && shortURL != options.Alias {
+		log.Println("Исходный URL", longURL, "уже сокращён как", shortURL, ", псевдоним", req.Alias, "не создан")
+		return nil, status.Error(codes.AlreadyExists, "исходный URL уже сокращён как "+s.baseURL+shortURL+", псевдоним не создан")
+	}
 
-	shortURL, err := s.storage.AddURL(longURL, s.auth.GetUserID())
 	if err != nil && errors.Is(err, storage.DBErrorUnknown) {
 		log.Println("Ошибка '", err, "' при добxавлении в БД URL:", longURL)
 		return nil, status.Errorf(codes.Internal, "ошибка при добавлении в БД: "+err.Error())
@@ -50,12 +92,15 @@ func (s *grpcServer) PostLongUrl(ctx context.Context, req *pb.PostLongUrlRequest
 
 // GetLongUrl обрабатывает gRPC-запрос на восстановление исходного URL по переданному короткому URL.
 func (s *grpcServer) GetLongUrl(ctx context.Context, req *pb.GetLongUrlRequest) (*pb.GetLongUrlResponse, error) {
-	var response = pb.GetLongUrlResponse{Token: s.auth.GetTokenID()}
+	var response = pb.GetLongUrlResponse{Token: auth.UserFromContext(ctx).Token}
 
 	shortUrl := req.ShortUrl
 	log.Println("Идентификатор короткого URL, полученный из gRPC-запроса:", shortUrl)
 
-	result, err := s.storage.FindURL(shortUrl)
+	result, err := s.storage.FindURL(ctx, shortUrl)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
 	if err != nil {
 		log.Println("Ошибка '", err, "'. Не найден URL с указанным коротким идентификатором:", shortUrl)
 		return nil, status.Error(codes.NotFound, "URL с указанным коротким идентификатором не найден")
@@ -66,69 +111,120 @@ func (s *grpcServer) GetLongUrl(ctx context.Context, req *pb.GetLongUrlRequest)
 		return nil, status.Error(codes.Unavailable, "URL с указанным коротким идентификатором не найден")
 	}
 
+	if result.Expired(time.Now()) {
+		log.Println("Срок действия короткого идентификатора", shortUrl, "истёк", result.ExpiresAt)
+		return nil, status.Error(codes.FailedPrecondition, "срок действия URL с указанным коротким идентификатором истёк")
+	}
+
+	if result.Protected() {
+		client := clientAddr(ctx)
+		if s.limiter != nil {
+			if allowed, _ := s.limiter.Allow(shortUrl, client, time.Now()); !allowed {
+				log.Println("Превышено количество попыток ввода пароля для короткого идентификатора", shortUrl)
+				return nil, status.Error(codes.ResourceExhausted, "слишком много попыток ввода пароля, повторите позже")
+			}
+		}
+
+		if !result.CheckPassword(req.Password) {
+			log.Println("Передан неверный пароль для короткого идентификатора", shortUrl)
+			return nil, status.Error(codes.PermissionDenied, "неверный пароль для короткого URL")
+		}
+		if s.limiter != nil {
+			s.limiter.Release(shortUrl, client)
+		}
+	}
+
 	log.Println("Найден URL", result.LongURL, "для короткого идентификатора", shortUrl)
 	response.OriginalUrl = result.LongURL
+	response.Flagged = result.Flagged
 
 	return &response, nil
 }
 
 // PostLongUrls обрабатывает gRPC-запрос на сокращение переданных URL, возвращает список коротких URL.
 func (s *grpcServer) PostLongUrls(ctx context.Context, req *pb.PostLongUrlsRequest) (*pb.PostLongUrlsResponse, error) {
-	var response = pb.PostLongUrlsResponse{Token: s.auth.GetTokenID()}
+	var response = pb.PostLongUrlsResponse{Token: auth.UserFromContext(ctx).Token}
 
 	var longUrls = make(storage.BatchURLs, 0, len(req.LongUrls))
-	for _, longUrl := range req.LongUrls {
-		longUrls = append(longUrls, storage.RecordURL{ID: longUrl.CorrelationId, URL: longUrl.OriginalUrl})
+	for i, longUrl := range req.LongUrls {
+		originalUrl, err := s.checkURL(ctx, longUrl.OriginalUrl, fmt.Sprintf("long_urls[%d].original_url", i))
+		if err != nil {
+			return nil, err
+		}
+
+		expiresAt, err := storage.NewExpiration(timestampOrZero(longUrl.ExpiresAt), longUrl.TtlSeconds, time.Now())
+		if err != nil {
+			log.Println("Ошибка '", err, "' при проверке срока действия URL:", longUrl.OriginalUrl)
+			return nil, status.Error(codes.InvalidArgument, "запись "+longUrl.CorrelationId+": "+err.Error())
+		}
+
+		longUrls = append(longUrls, storage.RecordURL{ID: longUrl.CorrelationId, URL: originalUrl, ExpiresAt: expiresAt})
 	}
 
-	shortUrls, err := s.storage.AddURLs(longUrls, s.auth.GetUserID())
+	shortUrls, err := s.storage.AddURLs(ctx, longUrls, auth.UserFromContext(ctx).ID)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
 	if err != nil {
 		log.Println("Ошибка '", err, "' при добавлении в БД URLs:", longUrls)
 		return nil, status.Error(codes.Internal, "ошибка при добавлении в БД URLs: "+err.Error())
 	}
 
-	response.ShortUrls = make([]*pb.PostLongUrlsResponse_PostLongUrlResponseRecord, len(shortUrls))
+	response.ShortUrls = make([]*pb.PostLongUrlsResponse_PostLongUrlResponseRecord, 0, len(shortUrls))
 	for _, shortUrl := range shortUrls {
 		response.ShortUrls = append(response.ShortUrls, &pb.PostLongUrlsResponse_PostLongUrlResponseRecord{
 			CorrelationId: shortUrl.ID,
 			ShortUrl:      s.baseURL + shortUrl.URL,
+			Duplicate:     shortUrl.Duplicate,
 		})
 	}
 
 	return &response, nil
 }
 
-// GetLongUrlsByUser обрабатывает gRPC-запрос на получение списка всех сокращённых и исходных URL для текущего пользователя.
+// GetLongUrlsByUser обрабатывает gRPC-запрос на получение страницы списка сокращённых и исходных URL для текущего пользователя.
+// Курсор следующей страницы возвращается в поле next_cursor, для последней страницы он пустой.
 func (s *grpcServer) GetLongUrlsByUser(ctx context.Context, req *pb.GetLongUrlsByUserRequest) (*pb.GetLongUrlsByUserResponse, error) {
-	var response = pb.GetLongUrlsByUserResponse{Token: s.auth.GetTokenID()}
+	user := auth.UserFromContext(ctx)
+	var response = pb.GetLongUrlsByUserResponse{Token: user.Token}
 
-	urls := s.storage.GetURLsByUser(s.auth.GetUserID())
-	if len(urls) == 0 {
-		log.Println("Для пользователя с идентификатором '" + s.auth.GetUserID() + "' не найдены сохранённые URL")
-		return &response, nil
+	query, err := storage.NewListQuery(int(req.Limit), req.Cursor, req.Order, req.Status, req.Search)
+	if err != nil {
+		log.Println("Неверные параметры запроса списка URL:", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	log.Println("Для пользователя с идентификатором '"+s.auth.GetUserID()+"' найдено ", len(urls), "сохранённых URL:")
 
-	for i, shortURL := range urls {
-		result, err := s.storage.FindURL(shortURL)
-		if err != nil {
-			continue
-		}
+	page, err := s.storage.ListURLs(ctx, user.ID, query)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при поиске URL пользователя с идентификатором", user.ID)
+		return nil, status.Error(codes.Internal, "ошибка при поиске URL пользователя: "+err.Error())
+	}
 
-		record := pb.GetLongUrlsByUserResponse_GetLongUrlsByUserResponseRecord{
-			ShortUrl:    s.baseURL + shortURL,
-			OriginalUrl: result.LongURL,
-		}
-		log.Println("Запись", i, "короткий URL", record.ShortUrl, "длинный URL", record.OriginalUrl)
-		response.Urls = append(response.Urls, &record)
+	log.Println("Для пользователя с идентификатором '"+user.ID+"' найдено ", len(page.URLs), "сохранённых URL")
+
+	for _, e := range page.URLs {
+		response.Urls = append(response.Urls, &pb.GetLongUrlsByUserResponse_GetLongUrlsByUserResponseRecord{
+			ShortUrl:    s.baseURL + e.ShortURL,
+			OriginalUrl: e.LongURL,
+			CreatedAt:   timestampOrNil(e.CreatedAt),
+			Deleted:     e.Deleted,
+			ExpiresAt:   timestampOrNil(e.ExpiresAt),
+		})
 	}
+	response.NextCursor = page.NextCursor
 
 	return &response, nil
 }
 
 // Delete обрабатывает gRPC-запрос на удаление переданных URL.
 func (s *grpcServer) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
-	var response = pb.DeleteResponse{Token: s.auth.GetTokenID()}
+	var response = pb.DeleteResponse{Token: auth.UserFromContext(ctx).Token}
 
 	log.Println("Тело запроса на удаление данных:\n", req.ShortUrls)
 	if len(req.ShortUrls) == 0 {
@@ -141,29 +237,212 @@ func (s *grpcServer) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.Del
 	}
 	log.Println("Список подлежащих удалению коротких идентификаторов URL:\n", req.ShortUrls)
 
-	_ = s.storage.DeleteURLs(req.ShortUrls, s.auth.GetUserID())
+	job, err := s.storage.DeleteURLs(ctx, req.ShortUrls, auth.UserFromContext(ctx).ID)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при удалении URL:", req.ShortUrls)
+		return nil, status.Error(codes.Internal, "ошибка при удалении URL: "+err.Error())
+	}
 
+	log.Println("Создано задание на удаление", job.ID)
+	response.JobId, response.Done, response.Urls = job.ID, job.Done(), s.deletionResults(job)
 	return &response, nil
 }
 
+// GetDeletionJob обрабатывает gRPC-запрос на получение состояния задания на удаление, созданного текущим пользователем.
+func (s *grpcServer) GetDeletionJob(ctx context.Context, req *pb.GetDeletionJobRequest) (*pb.GetDeletionJobResponse, error) {
+	job, err := s.storage.GetDeletionJob(ctx, req.JobId, auth.UserFromContext(ctx).ID)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+	if errors.Is(err, storage.ErrDeletionJobNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при получении задания на удаление:", req.JobId)
+		return nil, status.Error(codes.Internal, "ошибка при получении задания на удаление: "+err.Error())
+	}
+
+	return &pb.GetDeletionJobResponse{JobId: job.ID, Done: job.Done(), Urls: s.deletionResults(job), Token: auth.UserFromContext(ctx).Token}, nil
+}
+
+// deletionResults преобразует состояние удаления коротких URL задания в формат gRPC-ответа.
+func (s *grpcServer) deletionResults(job storage.DeletionJob) []*pb.DeletionResult {
+	results := make([]*pb.DeletionResult, 0, len(job.Results))
+	for _, r := range job.Results {
+		results = append(results, &pb.DeletionResult{ShortUrl: s.baseURL + r.ShortURL, Status: string(r.Status)})
+	}
+
+	return results
+}
+
+// timestampOrZero преобразует необязательную временную метку gRPC-запроса во время, возвращая нулевое время при её отсутствии.
+func timestampOrZero(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
+
 // Ping обрабатывает gRPC-запрос на проверку подключения к хранилищу сокращённых URL.
+// Статистика пула соединений в ответе не передаётся: она доступна только из доверенной подсети.
 func (s *grpcServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
-	err := s.storage.Ping()
+	err := s.storage.Ping(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
 	if err != nil {
 		log.Println(err)
 		errResponse := status.Error(codes.Internal, err.Error())
 		return nil, errResponse
 	}
 
-	return &pb.PingResponse{Token: s.auth.GetTokenID()}, nil
+	return &pb.PingResponse{Token: auth.UserFromContext(ctx).Token}, nil
 }
 
 // Stats обрабатывает gRPC-запрос на получение статистики сервиса: количества URL и пользователей.
 func (s *grpcServer) Stats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
-	var response = pb.StatsResponse{Token: s.auth.GetTokenID()}
+	var response = pb.StatsResponse{Token: auth.UserFromContext(ctx).Token}
+
+	urls, users, err := s.storage.GetStatistics(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при получении статистики сервиса")
+		return nil, status.Error(codes.Internal, "ошибка при получении статистики сервиса: "+err.Error())
+	}
 
-	urls, users := s.storage.GetStatistics()
 	response.Urls, response.Users = int32(urls), int32(users)
 
 	return &response, nil
 }
+
+// GetUrlStats обрабатывает gRPC-запрос на получение статистики переходов по короткому URL текущего пользователя.
+func (s *grpcServer) GetUrlStats(ctx context.Context, req *pb.GetUrlStatsRequest) (*pb.GetUrlStatsResponse, error) {
+	user := auth.UserFromContext(ctx)
+	var response = pb.GetUrlStatsResponse{Token: user.Token}
+
+	shortUrl := strings.Replace(req.ShortUrl, s.baseURL, "", -1)
+	log.Println("Идентификатор короткого URL, полученный из gRPC-запроса:", shortUrl)
+
+	result, err := s.storage.FindURL(ctx, shortUrl)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "'. Не найден URL с указанным коротким идентификатором:", shortUrl)
+		return nil, status.Error(codes.NotFound, "URL с указанным коротким идентификатором не найден")
+	}
+
+	if result.User != user.ID {
+		log.Println("Короткий идентификатор", shortUrl, "не принадлежит пользователю", user.ID)
+		return nil, status.Error(codes.PermissionDenied, "статистика доступна только пользователю, создавшему короткий URL")
+	}
+
+	clicks, err := s.storage.GetClicks(ctx, shortUrl)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при получении переходов по короткому идентификатору:", shortUrl)
+		return nil, status.Error(codes.Internal, "ошибка при получении статистики: "+err.Error())
+	}
+
+	stats := analytics.Summarize(s.baseURL+shortUrl, clicks)
+	response.Clicks, response.UniqueVisitors = int32(stats.Clicks), int32(stats.UniqueVisitors)
+	for _, day := range stats.Daily {
+		response.Daily = append(response.Daily, &pb.GetUrlStatsResponse_DailyStats{
+			Date:           day.Date,
+			Clicks:         int32(day.Clicks),
+			UniqueVisitors: int32(day.UniqueVisitors),
+		})
+	}
+
+	return &response, nil
+}
+
+// checkURL проверяет исходный URL, приводит его к единому виду и проверяет безопасность его страницы назначения.
+// Для недопустимого исходного URL возвращается ошибка с кодом InvalidArgument,
+// для небезопасной страницы назначения - ошибка с кодом PermissionDenied.
+func (s *grpcServer) checkURL(ctx context.Context, raw, field string) (string, error) {
+	longURL, err := s.normalizer.Normalize(raw)
+	if err != nil {
+		log.Println("Ошибка '", err, "' при проверке исходного URL:", raw)
+		return "", invalidURL(err, field)
+	}
+
+	if s.checker == nil {
+		return longURL, nil
+	}
+
+	err = s.checker.Check(ctx, longURL)
+	var safetyErr *safety.Error
+	if errors.As(err, &safetyErr) {
+		log.Println("Страница назначения исходного URL", longURL, "признана небезопасной:", err)
+		return "", unsafeURL(safetyErr)
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при проверке страницы назначения исходного URL:", longURL)
+		return "", status.Error(codes.Internal, "ошибка при проверке страницы назначения: "+err.Error())
+	}
+
+	return longURL, nil
+}
+
+// invalidURL возвращает ошибку с кодом InvalidArgument и описанием недопустимого поля запроса в подробностях ошибки.
+func invalidURL(err error, field string) error {
+	reason := "invalid_url"
+	var urlErr *urlnorm.Error
+	if errors.As(err, &urlErr) {
+		reason = string(urlErr.Reason)
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: reason}},
+	})
+	if detailsErr != nil {
+		log.Println("Не удалось добавить подробности к ошибке проверки исходного URL:", detailsErr)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return st.Err()
+}
+
+// unsafeURL возвращает ошибку с кодом PermissionDenied и причиной отказа в подробностях ошибки.
+func unsafeURL(err *safety.Error) error {
+	st, detailsErr := status.New(codes.PermissionDenied, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: string(err.Reason),
+		Domain: "shurl",
+	})
+	if detailsErr != nil {
+		log.Println("Не удалось добавить подробности к ошибке проверки страницы назначения:", detailsErr)
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return st.Err()
+}
+
+// deadlineExceeded возвращает gRPC-ошибку для операции с хранилищем, прерванной из-за истечения срока выполнения запроса.
+func deadlineExceeded(err error) error {
+	log.Println("Превышено время ожидания ответа от хранилища:", err)
+	return status.Error(codes.DeadlineExceeded, "превышено время ожидания ответа от хранилища")
+}
+
+// clientAddr возвращает IP-адрес клиента, отправившего gRPC-запрос, или пустую строку, если адрес неизвестен.
+func clientAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,131 @@
+package grpcserv
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	pb "github.com/StainlessSteelSnake/shurl/internal/grpcserv/proto"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetUrl обрабатывает gRPC-запрос на получение сведений о коротком URL текущего пользователя.
+func (s *grpcServer) GetUrl(ctx context.Context, req *pb.GetUrlRequest) (*pb.GetUrlResponse, error) {
+	shortUrl := strings.Replace(req.ShortUrl, s.baseURL, "", -1)
+	log.Println("Идентификатор короткого URL, полученный из gRPC-запроса:", shortUrl)
+
+	metadata, err := s.urlMetadata(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetUrlResponse{Url: metadata, Token: auth.UserFromContext(ctx).Token}, nil
+}
+
+// UpdateUrl обрабатывает gRPC-запрос на изменение исходного URL короткого URL текущего пользователя.
+// Новый исходный URL проходит те же проверки, что и при сокращении.
+func (s *grpcServer) UpdateUrl(ctx context.Context, req *pb.UpdateUrlRequest) (*pb.UpdateUrlResponse, error) {
+	shortUrl := strings.Replace(req.ShortUrl, s.baseURL, "", -1)
+	log.Println("Изменение исходного URL для короткого идентификатора", shortUrl)
+
+	longURL, err := s.checkURL(ctx, req.OriginalUrl, "original_url")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.storage.UpdateURL(ctx, shortUrl, longURL, auth.UserFromContext(ctx).ID)
+	if err != nil {
+		return nil, manageError(shortUrl, err)
+	}
+
+	metadata, err := s.urlMetadata(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateUrlResponse{Url: metadata, Token: auth.UserFromContext(ctx).Token}, nil
+}
+
+// RestoreUrl обрабатывает gRPC-запрос на восстановление удалённого короткого URL текущего пользователя.
+func (s *grpcServer) RestoreUrl(ctx context.Context, req *pb.RestoreUrlRequest) (*pb.RestoreUrlResponse, error) {
+	shortUrl := strings.Replace(req.ShortUrl, s.baseURL, "", -1)
+	log.Println("Восстановление удалённого короткого идентификатора", shortUrl)
+
+	err := s.storage.RestoreURL(ctx, shortUrl, auth.UserFromContext(ctx).ID)
+	if err != nil {
+		return nil, manageError(shortUrl, err)
+	}
+
+	metadata, err := s.urlMetadata(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RestoreUrlResponse{Url: metadata, Token: auth.UserFromContext(ctx).Token}, nil
+}
+
+// urlMetadata формирует сведения о коротком URL, если он принадлежит текущему пользователю.
+func (s *grpcServer) urlMetadata(ctx context.Context, shortUrl string) (*pb.UrlMetadata, error) {
+	result, err := s.storage.FindURL(ctx, shortUrl)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, deadlineExceeded(err)
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "'. Не найден URL с указанным коротким идентификатором:", shortUrl)
+		return nil, status.Error(codes.NotFound, "URL с указанным коротким идентификатором не найден")
+	}
+
+	user := auth.UserFromContext(ctx)
+	if result.User != user.ID {
+		log.Println("Короткий идентификатор", shortUrl, "не принадлежит пользователю", user.ID)
+		return nil, status.Error(codes.PermissionDenied, "сведения доступны только пользователю, создавшему короткий URL")
+	}
+
+	return &pb.UrlMetadata{
+		ShortUrl:    s.baseURL + shortUrl,
+		OriginalUrl: result.LongURL,
+		Deleted:     result.Deleted,
+		DeletedAt:   timestampOrNil(result.DeletedAt),
+		ExpiresAt:   timestampOrNil(result.ExpiresAt),
+		Protected:   result.Protected(),
+		Flagged:     result.Flagged,
+	}, nil
+}
+
+// manageError возвращает gRPC-ошибку, соответствующую ошибке изменения или восстановления короткого URL.
+func manageError(shortUrl string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return deadlineExceeded(err)
+	}
+
+	log.Println("Ошибка '", err, "' при изменении короткого идентификатора:", shortUrl)
+
+	switch {
+	case errors.Is(err, storage.ErrURLNotFound):
+		return status.Error(codes.NotFound, "URL с указанным коротким идентификатором не найден")
+	case errors.Is(err, storage.ErrNotOwner):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, storage.ErrURLDeleted), errors.Is(err, storage.ErrURLExpired),
+		errors.Is(err, storage.ErrURLNotDeleted), errors.Is(err, storage.ErrRestorePeriodExpired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, storage.DBErrorDublicate):
+		return status.Error(codes.AlreadyExists, "исходный URL уже сокращён")
+	default:
+		return status.Error(codes.Internal, "ошибка при изменении короткого URL: "+err.Error())
+	}
+}
+
+// timestampOrNil преобразует момент времени в gRPC-формат. Для нулевого момента возвращается nil.
+func timestampOrNil(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
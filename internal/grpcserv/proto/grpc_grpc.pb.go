@@ -25,8 +25,13 @@ const (
 	ShurlService_PostLongUrls_FullMethodName      = "/grpc_server.ShurlService/PostLongUrls"
 	ShurlService_GetLongUrlsByUser_FullMethodName = "/grpc_server.ShurlService/GetLongUrlsByUser"
 	ShurlService_Delete_FullMethodName            = "/grpc_server.ShurlService/Delete"
+	ShurlService_GetDeletionJob_FullMethodName    = "/grpc_server.ShurlService/GetDeletionJob"
 	ShurlService_Ping_FullMethodName              = "/grpc_server.ShurlService/Ping"
 	ShurlService_Stats_FullMethodName             = "/grpc_server.ShurlService/Stats"
+	ShurlService_GetUrlStats_FullMethodName       = "/grpc_server.ShurlService/GetUrlStats"
+	ShurlService_GetUrl_FullMethodName            = "/grpc_server.ShurlService/GetUrl"
+	ShurlService_UpdateUrl_FullMethodName         = "/grpc_server.ShurlService/UpdateUrl"
+	ShurlService_RestoreUrl_FullMethodName        = "/grpc_server.ShurlService/RestoreUrl"
 )
 
 // ShurlServiceClient is the client API for ShurlService service.
@@ -38,8 +43,13 @@ type ShurlServiceClient interface {
 	PostLongUrls(ctx context.Context, in *PostLongUrlsRequest, opts ...grpc.CallOption) (*PostLongUrlsResponse, error)
 	GetLongUrlsByUser(ctx context.Context, in *GetLongUrlsByUserRequest, opts ...grpc.CallOption) (*GetLongUrlsByUserResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	GetDeletionJob(ctx context.Context, in *GetDeletionJobRequest, opts ...grpc.CallOption) (*GetDeletionJobResponse, error)
 	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	GetUrlStats(ctx context.Context, in *GetUrlStatsRequest, opts ...grpc.CallOption) (*GetUrlStatsResponse, error)
+	GetUrl(ctx context.Context, in *GetUrlRequest, opts ...grpc.CallOption) (*GetUrlResponse, error)
+	UpdateUrl(ctx context.Context, in *UpdateUrlRequest, opts ...grpc.CallOption) (*UpdateUrlResponse, error)
+	RestoreUrl(ctx context.Context, in *RestoreUrlRequest, opts ...grpc.CallOption) (*RestoreUrlResponse, error)
 }
 
 type shurlServiceClient struct {
@@ -95,6 +105,15 @@ func (c *shurlServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts
 	return out, nil
 }
 
+func (c *shurlServiceClient) GetDeletionJob(ctx context.Context, in *GetDeletionJobRequest, opts ...grpc.CallOption) (*GetDeletionJobResponse, error) {
+	out := new(GetDeletionJobResponse)
+	err := c.cc.Invoke(ctx, ShurlService_GetDeletionJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *shurlServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
 	out := new(PingResponse)
 	err := c.cc.Invoke(ctx, ShurlService_Ping_FullMethodName, in, out, opts...)
@@ -113,6 +132,42 @@ func (c *shurlServiceClient) Stats(ctx context.Context, in *StatsRequest, opts .
 	return out, nil
 }
 
+func (c *shurlServiceClient) GetUrlStats(ctx context.Context, in *GetUrlStatsRequest, opts ...grpc.CallOption) (*GetUrlStatsResponse, error) {
+	out := new(GetUrlStatsResponse)
+	err := c.cc.Invoke(ctx, ShurlService_GetUrlStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shurlServiceClient) GetUrl(ctx context.Context, in *GetUrlRequest, opts ...grpc.CallOption) (*GetUrlResponse, error) {
+	out := new(GetUrlResponse)
+	err := c.cc.Invoke(ctx, ShurlService_GetUrl_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shurlServiceClient) UpdateUrl(ctx context.Context, in *UpdateUrlRequest, opts ...grpc.CallOption) (*UpdateUrlResponse, error) {
+	out := new(UpdateUrlResponse)
+	err := c.cc.Invoke(ctx, ShurlService_UpdateUrl_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shurlServiceClient) RestoreUrl(ctx context.Context, in *RestoreUrlRequest, opts ...grpc.CallOption) (*RestoreUrlResponse, error) {
+	out := new(RestoreUrlResponse)
+	err := c.cc.Invoke(ctx, ShurlService_RestoreUrl_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShurlServiceServer is the server API for ShurlService service.
 // All implementations must embed UnimplementedShurlServiceServer
 // for forward compatibility
@@ -122,8 +177,13 @@ type ShurlServiceServer interface {
 	PostLongUrls(context.Context, *PostLongUrlsRequest) (*PostLongUrlsResponse, error)
 	GetLongUrlsByUser(context.Context, *GetLongUrlsByUserRequest) (*GetLongUrlsByUserResponse, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	GetDeletionJob(context.Context, *GetDeletionJobRequest) (*GetDeletionJobResponse, error)
 	Ping(context.Context, *PingRequest) (*PingResponse, error)
 	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	GetUrlStats(context.Context, *GetUrlStatsRequest) (*GetUrlStatsResponse, error)
+	GetUrl(context.Context, *GetUrlRequest) (*GetUrlResponse, error)
+	UpdateUrl(context.Context, *UpdateUrlRequest) (*UpdateUrlResponse, error)
+	RestoreUrl(context.Context, *RestoreUrlRequest) (*RestoreUrlResponse, error)
 	mustEmbedUnimplementedShurlServiceServer()
 }
 
@@ -146,12 +206,27 @@ func (UnimplementedShurlServiceServer) GetLongUrlsByUser(context.Context, *GetLo
 func (UnimplementedShurlServiceServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedShurlServiceServer) GetDeletionJob(context.Context, *GetDeletionJobRequest) (*GetDeletionJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeletionJob not implemented")
+}
 func (UnimplementedShurlServiceServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
 func (UnimplementedShurlServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
+func (UnimplementedShurlServiceServer) GetUrlStats(context.Context, *GetUrlStatsRequest) (*GetUrlStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUrlStats not implemented")
+}
+func (UnimplementedShurlServiceServer) GetUrl(context.Context, *GetUrlRequest) (*GetUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUrl not implemented")
+}
+func (UnimplementedShurlServiceServer) UpdateUrl(context.Context, *UpdateUrlRequest) (*UpdateUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUrl not implemented")
+}
+func (UnimplementedShurlServiceServer) RestoreUrl(context.Context, *RestoreUrlRequest) (*RestoreUrlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreUrl not implemented")
+}
 func (UnimplementedShurlServiceServer) mustEmbedUnimplementedShurlServiceServer() {}
 
 // UnsafeShurlServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -255,6 +330,24 @@ func _ShurlService_Delete_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShurlService_GetDeletionJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeletionJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShurlServiceServer).GetDeletionJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShurlService_GetDeletionJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShurlServiceServer).GetDeletionJob(ctx, req.(*GetDeletionJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShurlService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PingRequest)
 	if err := dec(in); err != nil {
@@ -291,6 +384,78 @@ func _ShurlService_Stats_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShurlService_GetUrlStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUrlStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShurlServiceServer).GetUrlStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShurlService_GetUrlStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShurlServiceServer).GetUrlStats(ctx, req.(*GetUrlStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShurlService_GetUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShurlServiceServer).GetUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShurlService_GetUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShurlServiceServer).GetUrl(ctx, req.(*GetUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShurlService_UpdateUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShurlServiceServer).UpdateUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShurlService_UpdateUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShurlServiceServer).UpdateUrl(ctx, req.(*UpdateUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShurlService_RestoreUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShurlServiceServer).RestoreUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShurlService_RestoreUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShurlServiceServer).RestoreUrl(ctx, req.(*RestoreUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ShurlService_ServiceDesc is the grpc.ServiceDesc for ShurlService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -318,6 +483,10 @@ var ShurlService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _ShurlService_Delete_Handler,
 		},
+		{
+			MethodName: "GetDeletionJob",
+			Handler:    _ShurlService_GetDeletionJob_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _ShurlService_Ping_Handler,
@@ -326,6 +495,22 @@ var ShurlService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stats",
 			Handler:    _ShurlService_Stats_Handler,
 		},
+		{
+			MethodName: "GetUrlStats",
+			Handler:    _ShurlService_GetUrlStats_Handler,
+		},
+		{
+			MethodName: "GetUrl",
+			Handler:    _ShurlService_GetUrl_Handler,
+		},
+		{
+			MethodName: "UpdateUrl",
+			Handler:    _ShurlService_UpdateUrl_Handler,
+		},
+		{
+			MethodName: "RestoreUrl",
+			Handler:    _ShurlService_RestoreUrl_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/grpc.proto",
@@ -1,28 +1,66 @@
 package grpcserv
 
 import (
+	"context"
 	"log"
 	"net"
 
 	"github.com/StainlessSteelSnake/shurl/internal/auth"
 	pb "github.com/StainlessSteelSnake/shurl/internal/grpcserv/proto"
+	"github.com/StainlessSteelSnake/shurl/internal/ratelimit"
+	"github.com/StainlessSteelSnake/shurl/internal/safety"
 	"github.com/StainlessSteelSnake/shurl/internal/storage"
+	"github.com/StainlessSteelSnake/shurl/internal/urlnorm"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// scopeNone обозначает методы, доступные любому пользователю независимо от областей действия.
+const scopeNone auth.Scope = ""
+
+// methodScopes задаёт области действия, которые должны быть разрешены пользователю для вызова методов сервиса.
+// Методы, не указанные в списке, не вызываются, поэтому каждый новый метод нужно добавить в список явно.
+var methodScopes = map[string]auth.Scope{
+	pb.ShurlService_GetLongUrl_FullMethodName:        scopeNone,
+	pb.ShurlService_Ping_FullMethodName:              scopeNone,
+	pb.ShurlService_Stats_FullMethodName:             scopeNone,
+	pb.ShurlService_PostLongUrl_FullMethodName:       auth.ScopeCreate,
+	pb.ShurlService_PostLongUrls_FullMethodName:      auth.ScopeCreate,
+	pb.ShurlService_UpdateUrl_FullMethodName:         auth.ScopeUpdate,
+	pb.ShurlService_GetLongUrlsByUser_FullMethodName: auth.ScopeRead,
+	pb.ShurlService_GetUrl_FullMethodName:            auth.ScopeRead,
+	pb.ShurlService_GetUrlStats_FullMethodName:       auth.ScopeRead,
+	pb.ShurlService_GetDeletionJob_FullMethodName:    auth.ScopeRead,
+	pb.ShurlService_Delete_FullMethodName:            auth.ScopeDelete,
+	pb.ShurlService_RestoreUrl_FullMethodName:        auth.ScopeDelete,
+}
+
 type grpcServer struct {
 	pb.UnimplementedShurlServiceServer
-	storage storage.Storager
-	auth    auth.Authenticator
-	baseURL string
+	storage    storage.Storager
+	auth       auth.Authenticator
+	baseURL    string
+	limiter    *ratelimit.ResourceLimiter
+	normalizer *urlnorm.Normalizer
+	checker    safety.DestinationChecker
 }
 
 // NewServer создаёт и запускает в отдельном потоке экземпляр gRPC-сервера.
-func NewServer(host string, baseURL string, storage storage.Storager, auth auth.Authenticator) (*grpc.Server, error) {
+// Если обработчик проверки исходных URL не задан, используются настройки проверки по умолчанию.
+// Если проверка безопасности страниц назначения не задана, страницы назначения не проверяются.
+func NewServer(host string, baseURL string, storage storage.Storager, auth auth.Authenticator, limiter *ratelimit.ResourceLimiter, normalizer *urlnorm.Normalizer, checker safety.DestinationChecker) (*grpc.Server, error) {
 	server := grpcServer{
-		storage: storage,
-		auth:    auth,
-		baseURL: baseURL,
+		storage:    storage,
+		auth:       auth,
+		baseURL:    baseURL,
+		limiter:    limiter,
+		normalizer: normalizer,
+		checker:    checker,
+	}
+
+	if server.normalizer == nil {
+		server.normalizer = urlnorm.NewNormalizer(urlnorm.Options{})
 	}
 
 	// определяем порт для сервера
@@ -32,7 +70,7 @@ func NewServer(host string, baseURL string, storage storage.Storager, auth auth.
 	}
 
 	// создаём gRPC-сервер без зарегистрированной службы
-	s := grpc.NewServer(grpc.UnaryInterceptor(server.auth.GrpcAuthenticate))
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(server.auth.GrpcAuthenticate, requireScope))
 
 	// регистрируем сервис
 
@@ -47,3 +85,18 @@ func NewServer(host string, baseURL string, storage storage.Storager, auth auth.
 
 	return s, nil
 }
+
+// requireScope вызывает метод сервиса, только если пользователю разрешены действия из области, заданной для метода,
+// например если запрос выполнен по ключу API с такой областью действия. Методы без заданной области не вызываются.
+func requireScope(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	scope, ok := methodScopes[info.FullMethod]
+	if !ok {
+		log.Println("Для метода", info.FullMethod, "не задана область действия")
+		return nil, status.Error(codes.PermissionDenied, "Для метода не задана область действия")
+	}
+	if scope != scopeNone && !auth.UserFromContext(ctx).Allowed(scope) {
+		return nil, status.Error(codes.PermissionDenied, "Действие не разрешено для области действия ключа API")
+	}
+
+	return handler(ctx, req)
+}
@@ -0,0 +1,192 @@
+package grpcserv
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	pb "github.com/StainlessSteelSnake/shurl/internal/grpcserv/proto"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// newTestClient запускает gRPC-сервер с заданными хранилищем и аутентификатором на свободном порту
+// и возвращает подключённого к нему клиента.
+func newTestClient(t *testing.T, s storage.Storager, a auth.Authenticator) pb.ShurlServiceClient {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	server, err := NewServer(address, "http://localhost:8080/", s, a, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewShurlServiceClient(conn)
+}
+
+func TestServer_concurrentUsers(t *testing.T) {
+	const users, requests = 8, 10
+
+	client := newTestClient(t, storage.NewMemoryStorage(), auth.NewAuth(nil, auth.Options{}))
+	ctx := context.Background()
+
+	errs := make(chan error, users)
+	for i := 0; i < users; i++ {
+		go func(i int) {
+			token := ""
+			for j := 0; j < requests; j++ {
+				requestCtx := ctx
+				if token != "" {
+					requestCtx = metadata.AppendToOutgoingContext(ctx, "authentication", token)
+				}
+
+				response, err := client.PostLongUrl(requestCtx, &pb.PostLongUrlRequest{OriginalUrl: fmt.Sprintf("https://example.com/%d/%d", i, j)})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if token == "" {
+					token = response.Token
+				}
+				if response.Token != token {
+					errs <- fmt.Errorf("пользователю %d возвращён чужой токен", i)
+					return
+				}
+			}
+
+			userCtx := metadata.AppendToOutgoingContext(ctx, "authentication", token)
+			response, err := client.GetLongUrlsByUser(userCtx, &pb.GetLongUrlsByUserRequest{})
+			if err != nil {
+				errs <- err
+				return
+			}
+			if len(response.Urls) != requests {
+				errs <- fmt.Errorf("пользователь %d: получено %d URL вместо %d", i, len(response.Urls), requests)
+				return
+			}
+			for _, u := range response.Urls {
+				if !strings.HasPrefix(u.OriginalUrl, fmt.Sprintf("https://example.com/%d/", i)) {
+					errs <- fmt.Errorf("пользователь %d получил чужой URL %s", i, u.OriginalUrl)
+					return
+				}
+			}
+
+			errs <- nil
+		}(i)
+	}
+
+	for i := 0; i < users; i++ {
+		assert.NoError(t, <-errs)
+	}
+}
+
+func TestServer_apiKeyScope(t *testing.T) {
+	ctx := context.Background()
+	s := storage.NewMemoryStorage()
+	client := newTestClient(t, s, auth.NewAuth(nil, auth.Options{APIKeys: s}))
+
+	newKey := func(scope auth.Scope) context.Context {
+		key, secret, err := auth.NewAPIKey("service", "", scope, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = s.AddAPIKey(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+secret)
+	}
+	createCtx, readCtx := newKey(auth.ScopeCreate), newKey(auth.ScopeRead)
+
+	request := &pb.PostLongUrlsRequest{LongUrls: []*pb.PostLongUrlsRequest_PostLongUrlRequestRecord{{CorrelationId: "1", OriginalUrl: "https://example.com/batch"}}}
+	_, err := client.PostLongUrls(createCtx, request)
+	assert.NoError(t, err)
+
+	_, err = client.PostLongUrls(readCtx, request)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "ключ только для чтения не позволяет создавать короткие URL")
+
+	response, err := client.GetLongUrlsByUser(readCtx, &pb.GetLongUrlsByUserRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, response.Urls, 1)
+
+	_, err = client.GetLongUrlsByUser(createCtx, &pb.GetLongUrlsByUserRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	if len(response.Urls) == 1 {
+		update := &pb.UpdateUrlRequest{ShortUrl: response.Urls[0].ShortUrl, OriginalUrl: "https://evil.example/"}
+		_, err = client.UpdateUrl(createCtx, update)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "ключ только для создания не позволяет менять существующие короткие URL")
+
+		update.OriginalUrl = "https://example.com/updated"
+		_, err = client.UpdateUrl(newKey(auth.ScopeUpdate), update)
+		assert.NoError(t, err)
+	}
+
+	_, err = client.GetLongUrlsByUser(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer unknown.key"), &pb.GetLongUrlsByUserRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_GetLongUrl(t *testing.T) {
+	ctx := context.Background()
+	s := storage.NewMemoryStorage()
+
+	active, err := s.AddURL(ctx, "https://ya.ru", "user1", storage.URLOptions{})
+	assert.NoError(t, err)
+	expired, err := s.AddURL(ctx, "https://go.dev", "user1", storage.URLOptions{ExpiresAt: time.Now().Add(-time.Minute)})
+	assert.NoError(t, err)
+
+	client := newTestClient(t, s, auth.NewAuth(nil, auth.Options{}))
+
+	tests := []struct {
+		name     string
+		shortURL string
+		wantCode codes.Code
+	}{
+		{"Действующий короткий URL", active, codes.OK},
+		{"Короткий URL с истёкшим сроком действия", expired, codes.FailedPrecondition},
+		{"Несуществующий короткий URL", "unknown", codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetLongUrl(ctx, &pb.GetLongUrlRequest{ShortUrl: tt.shortURL})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func Test_methodScopes(t *testing.T) {
+	for _, method := range pb.ShurlService_ServiceDesc.Methods {
+		fullMethod := "/" + pb.ShurlService_ServiceDesc.ServiceName + "/" + method.MethodName
+		_, ok := methodScopes[fullMethod]
+		assert.True(t, ok, "для метода %s не задана область действия", fullMethod)
+	}
+	assert.Len(t, methodScopes, len(pb.ShurlService_ServiceDesc.Methods), "в списке есть методы, которых нет в сервисе")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	_, err := requireScope(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/shurl.ShurlService/Unknown"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "метод без заданной области действия не вызывается")
+}
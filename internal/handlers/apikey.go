@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// Типы данных для управления ключами API.
+type (
+	// APIKeyRequestBody содержит поля для обработки тела запроса на создание ключа API в формате JSON.
+	APIKeyRequestBody struct {
+		UserID string `json:"user_id"`         // Пользователь, от имени которого выполняются запросы с ключом
+		Name   string `json:"name,omitempty"`  // Описание ключа
+		Scope  string `json:"scope,omitempty"` // Область действия ключа: create, read, update или delete, по умолчанию без ограничений
+	}
+
+	// APIKeyMetadata содержит поля для формирования тела ответа в формате JSON со сведениями о ключе API.
+	// Сам ключ передаётся только в ответе на запрос создания ключа.
+	APIKeyMetadata struct {
+		ID        string     `json:"id"`
+		Key       string     `json:"key,omitempty"` // Ключ для передачи в заголовке Authorization: Bearer
+		UserID    string     `json:"user_id"`
+		Name      string     `json:"name,omitempty"`
+		Scope     string     `json:"scope,omitempty"`
+		CreatedAt time.Time  `json:"created_at"`
+		RevokedAt *time.Time `json:"revoked_at,omitempty"` // Момент отзыва ключа
+	}
+)
+
+// newAPIKeyMetadata формирует сведения о ключе API по записи хранилища.
+func newAPIKeyMetadata(key storage.APIKey) APIKeyMetadata {
+	return APIKeyMetadata{
+		ID:        key.ID,
+		UserID:    key.User,
+		Name:      key.Name,
+		Scope:     key.Scope,
+		CreatedAt: key.CreatedAt,
+		RevokedAt: timeOrNil(key.RevokedAt),
+	}
+}
+
+// apiKeys возвращает хранилище ключей API и отвечает кодом 501, если используемое хранилище их не поддерживает.
+func (h *Handler) apiKeys(w http.ResponseWriter) (storage.APIKeyStorager, bool) {
+	keys, ok := h.storage.(storage.APIKeyStorager)
+	if !ok {
+		http.Error(w, "ключи API не поддерживаются используемым хранилищем", http.StatusNotImplemented)
+	}
+
+	return keys, ok
+}
+
+// postAPIKey создаёт ключ API для заданного пользователя и отвечает сведениями о нём вместе с самим ключом.
+// Запрос разрешён только из доверенной IP-подсети.
+func (h *Handler) postAPIKey(w http.ResponseWriter, r *http.Request) {
+	log.Println("Обработка запроса на создание ключа API")
+
+	if !h.trusted(w, r) {
+		return
+	}
+
+	keys, ok := h.apiKeys(w)
+	if !ok {
+		return
+	}
+
+	b, err := decodeRequest(r)
+	if err != nil {
+		log.Println("Неверный формат данных в запросе:", err)
+		http.Error(w, "неверный формат данных в запросе: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	requestBody := APIKeyRequestBody{}
+	err = json.Unmarshal(b, &requestBody)
+	if err != nil {
+		log.Println("Неверный формат данных в запросе:", err)
+		http.Error(w, "неверный формат данных в запросе: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if requestBody.UserID == "" {
+		http.Error(w, "не задан пользователь, от имени которого выполняются запросы с ключом API", http.StatusBadRequest)
+		return
+	}
+
+	scope, err := auth.ParseScope(requestBody.Scope)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key, secret, err := auth.NewAPIKey(requestBody.UserID, requestBody.Name, scope, time.Now())
+	if err != nil {
+		log.Println("Ошибка при создании ключа API:", err)
+		http.Error(w, "ошибка при создании ключа API: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = keys.AddAPIKey(r.Context(), key)
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при сохранении ключа API")
+		http.Error(w, "ошибка при сохранении ключа API: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Println("Создан ключ API", key.ID, "для пользователя", key.User)
+
+	response := newAPIKeyMetadata(key)
+	response.Key = secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		log.Println("Не удалось закодировать в JSON сведения о ключе API:", err)
+	}
+}
+
+// getAPIKeys отвечает списком всех ключей API, включая отозванные, без самих ключей.
+// Запрос разрешён только из доверенной IP-подсети.
+func (h *Handler) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	log.Println("Обработка запроса на получение списка ключей API")
+
+	if !h.trusted(w, r) {
+		return
+	}
+
+	keys, ok := h.apiKeys(w)
+	if !ok {
+		return
+	}
+
+	list, err := keys.ListAPIKeys(r.Context())
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при получении списка ключей API")
+		http.Error(w, "ошибка при получении списка ключей API: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]APIKeyMetadata, len(list))
+	for i, key := range list {
+		response[i] = newAPIKeyMetadata(key)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		log.Println("Не удалось закодировать в JSON список ключей API:", err)
+	}
+}
+
+// revokeAPIKey отзывает ключ API. Запросы с отозванным ключом больше не принимаются.
+// Запрос разрешён только из доверенной IP-подсети.
+func (h *Handler) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	log.Println("Отзыв ключа API", id)
+
+	if !h.trusted(w, r) {
+		return
+	}
+
+	keys, ok := h.apiKeys(w)
+	if !ok {
+		return
+	}
+
+	err := keys.RevokeAPIKey(r.Context(), id, time.Now())
+	if storageTimeout(w, err) {
+		return
+	}
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		http.Error(w, "ключ API с указанным идентификатором не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при отзыве ключа API", id)
+		http.Error(w, "ошибка при отзыве ключа API: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
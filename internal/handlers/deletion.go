@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// Типы данных для ответа о состоянии задания на удаление.
+type (
+	// DeletionJobResponse содержит поля для формирования тела ответа в формате JSON о состоянии задания на удаление.
+	DeletionJobResponse struct {
+		ID   string                 `json:"job_id"`
+		Done bool                   `json:"done"` // Признак того, что в задании не осталось коротких URL в очереди на удаление
+		URLs []DeletionResultRecord `json:"urls"`
+	}
+
+	// DeletionResultRecord содержит короткий URL из запроса на удаление и состояние его удаления:
+	// queued, deleted, not_found, forbidden, already_deleted или failed.
+	DeletionResultRecord struct {
+		ShortURL string `json:"short_url"`
+		Status   string `json:"status"`
+	}
+)
+
+// getDeletionJob отвечает состоянием задания на удаление, созданного пользователем.
+func (h *Handler) getDeletionJob(w http.ResponseWriter, r *http.Request) {
+	log.Println("Полученный GET-запрос:", r.URL)
+
+	id := chi.URLParam(r, "job")
+	job, err := h.storage.GetDeletionJob(r.Context(), id, auth.UserFromContext(r.Context()).ID)
+	if storageTimeout(w, err) {
+		return
+	}
+	if errors.Is(err, storage.ErrDeletionJobNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при получении задания на удаление:", id)
+		http.Error(w, "ошибка при получении задания на удаление: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeDeletionJob(w, job, http.StatusOK)
+}
+
+// writeDeletionJob отвечает заданным кодом и состоянием задания на удаление в формате JSON.
+func writeDeletionJob(w http.ResponseWriter, job storage.DeletionJob, code int) {
+	response := DeletionJobResponse{ID: job.ID, Done: job.Done(), URLs: make([]DeletionResultRecord, 0, len(job.Results))}
+	for _, result := range job.Results {
+		response.URLs = append(response.URLs, DeletionResultRecord{ShortURL: baseURL + result.ShortURL, Status: string(result.Status)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	err := enc.Encode(response)
+	if err != nil {
+		log.Println("Не удалось закодировать в JSON состояние задания на удаление:", err)
+	}
+}
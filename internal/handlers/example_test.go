@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/urlnorm"
 )
 
 func ExampleHandler_badRequest() {
@@ -107,7 +108,7 @@ func ExampleHandler_postLongURL() {
 		},
 	}
 	for _, tt := range tests {
-		h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth()}
+		h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth(nil, auth.Options{}), normalizer: urlnorm.NewNormalizer(urlnorm.Options{})}
 
 		writer := httptest.NewRecorder()
 		requestBody := strings.NewReader(tt.longURL)
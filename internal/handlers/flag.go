@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// warningTemplate содержит страницу с предупреждением о переходе по короткому URL,
+// помеченному администратором как ведущий на подозрительную страницу.
+var warningTemplate = template.Must(template.New("warning").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Подозрительная ссылка</title>
+</head>
+<body>
+<h1>Подозрительная ссылка</h1>
+<p>Ссылка ведёт на страницу, которая может быть небезопасной:</p>
+<p><code>{{.LongURL}}</code></p>
+<p>Не вводите на этой странице пароли и личные данные.</p>
+<p><a href="{{.LongURL}}" rel="noopener noreferrer nofollow">Всё равно перейти</a></p>
+</body>
+</html>
+`))
+
+type warningPage struct {
+	LongURL string
+}
+
+// renderWarning выводит страницу с предупреждением вместо перенаправления на исходный длинный URL.
+func renderWarning(w http.ResponseWriter, longURL string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	err := warningTemplate.Execute(w, warningPage{LongURL: longURL})
+	if err != nil {
+		log.Println("Ошибка при формировании страницы с предупреждением:", err)
+	}
+}
+
+// flagURL устанавливает пометку короткого URL как ведущего на подозрительную страницу по запросу PUT
+// и снимает её по запросу DELETE. Запрос разрешён только из доверенной IP-подсети.
+func (h *Handler) flagURL(w http.ResponseWriter, r *http.Request) {
+	shortURL := chi.URLParam(r, "id")
+	flagged := r.Method == http.MethodPut
+	log.Println("Изменение пометки короткого идентификатора", shortURL, ":", flagged)
+
+	if !h.trusted(w, r) {
+		return
+	}
+
+	flagger, ok := h.storage.(storage.Flagger)
+	if !ok {
+		http.Error(w, "пометка коротких URL не поддерживается используемым хранилищем", http.StatusNotImplemented)
+		return
+	}
+
+	err := flagger.FlagURL(r.Context(), shortURL, flagged)
+	if storageTimeout(w, err) {
+		return
+	}
+	if errors.Is(err, storage.ErrURLNotFound) {
+		http.Error(w, "URL с указанным коротким идентификатором не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при изменении пометки короткого идентификатора:", shortURL)
+		http.Error(w, "ошибка при изменении пометки короткого URL: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
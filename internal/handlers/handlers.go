@@ -2,33 +2,59 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/StainlessSteelSnake/shurl/internal/analytics"
 	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/oidc"
+	"github.com/StainlessSteelSnake/shurl/internal/ratelimit"
+	"github.com/StainlessSteelSnake/shurl/internal/realip"
+	"github.com/StainlessSteelSnake/shurl/internal/safety"
 	"github.com/StainlessSteelSnake/shurl/internal/storage"
+	"github.com/StainlessSteelSnake/shurl/internal/urlnorm"
 )
 
 // Типы данных для обработчиков http-запросов.
 type (
 	// Handler содержит общие настройки и данные для обработки запросов: ссылку на маршрутизатор,
-	// ссылку на хранилище данных и ссылку на обработчик авторизации пользователя.
+	// ссылку на хранилище данных, ссылку на обработчик авторизации пользователя,
+	// доверенную IP-подсеть и доверенные обратные прокси,
+	// ссылку на обработчик событий перехода по коротким URL,
+	// ссылку на ограничитель попыток ввода пароля к защищённым коротким URL,
+	// ссылку на обработчик проверки и нормализации исходных URL,
+	// ссылку на проверку безопасности страниц назначения
+	// и ссылку на клиент провайдера OpenID Connect для входа пользователей.
 	Handler struct {
 		*chi.Mux
 		storage         storage.Storager
 		auth            auth.Authenticator
 		trustedIpSubnet *net.IPNet
+		proxies         *realip.Resolver
+		recorder        *analytics.Recorder
+		limiter         *ratelimit.ResourceLimiter
+		normalizer      *urlnorm.Normalizer
+		checker         safety.DestinationChecker
+		provider        *oidc.Client
 	}
 
 	// PostRequestBody содержит поля для обработки тела входящего POST-запроса в формате JSON.
 	PostRequestBody struct {
-		URL string `json:"url"`
+		URL        string    `json:"url"`
+		Alias      string    `json:"alias,omitempty"`       // Необязательный пользовательский псевдоним короткого URL
+		ExpiresAt  time.Time `json:"expires_at,omitempty"`  // Необязательный момент окончания срока действия короткого URL
+		TTLSeconds int64     `json:"ttl_seconds,omitempty"` // Необязательное время жизни короткого URL в секундах
+		Password   string    `json:"password,omitempty"`    // Необязательный пароль для перехода по короткому URL
 	}
 
 	// PostResponseBody содержит поля для формирования тела ответа в формате JSON на POST-запрос.
@@ -39,15 +65,18 @@ type (
 	// PostRequestRecord содержит поля для обработки записи входящего
 	// POST-запроса в формате JSON на массовую загрузку данных.
 	PostRequestRecord struct {
-		ID  string `json:"correlation_id"`
-		URL string `json:"original_url"`
+		ID         string    `json:"correlation_id"`
+		URL        string    `json:"original_url"`
+		ExpiresAt  time.Time `json:"expires_at,omitempty"`  // Необязательный момент окончания срока действия короткого URL
+		TTLSeconds int64     `json:"ttl_seconds,omitempty"` // Необязательное время жизни короткого URL в секундах
 	}
 
 	// PostResponseRecord содержит поля для обработки записи возвращаемого тела ответа
 	// на POST-запрос в формате JSON на массовую загрузку данных.
 	PostResponseRecord struct {
-		ID       string `json:"correlation_id"`
-		ShortURL string `json:"short_url"`
+		ID        string `json:"correlation_id"`
+		ShortURL  string `json:"short_url"`
+		Duplicate bool   `json:"duplicate,omitempty"` // Признак того, что исходный длинный URL был сокращён ранее
 	}
 
 	// DeleteRequestBody содержит список записей из тела запроса на удаление данных.
@@ -59,33 +88,61 @@ type (
 	// PostResponseBatch содержит список записей из тела ответа на запрос массовой загрузки данных.
 	PostResponseBatch []PostResponseRecord
 
+	// ErrorResponse содержит поля для формирования тела ответа в формате JSON
+	// с описанием ошибки проверки исходного URL или его страницы назначения.
+	ErrorResponse struct {
+		Error   string `json:"error"`                    // Код ошибки
+		Reason  string `json:"reason"`                   // Причина отказа
+		Message string `json:"message"`                  // Описание ошибки
+		ID      string `json:"correlation_id,omitempty"` // Идентификатор записи запроса на массовую загрузку данных
+	}
+
 	shortAndLongURL struct {
-		ShortURL string `json:"short_url"`
-		LongURL  string `json:"original_url"`
+		ShortURL  string     `json:"short_url"`
+		LongURL   string     `json:"original_url"`
+		CreatedAt *time.Time `json:"created_at,omitempty"` // Момент создания короткого URL
+		Deleted   bool       `json:"deleted,omitempty"`    // Признак удаления короткого URL
+		ExpiresAt *time.Time `json:"expires_at,omitempty"` // Момент окончания срока действия короткого URL
 	}
 
 	shortAndLongURLs []shortAndLongURL
 
 	serviceStatistics struct {
-		URLs  int `json:"urls"`
-		Users int `json:"users"`
+		URLs  int                `json:"urls"`
+		Users int                `json:"users"`
+		Pool  *storage.PoolStats `json:"pool,omitempty"` // Статистика пула соединений, если хранилище его использует
 	}
 )
 
 var baseURL string
 
 // NewHandler создаёт верхнеуровневый обработчик HTTP-запросов.
-// А также связывает его с хранилищем данных и обработчиком данных авторизации,
+// А также связывает его с хранилищем данных, обработчиком данных авторизации, обработчиком событий перехода,
+// ограничителем попыток ввода пароля, обработчиком проверки исходных URL и проверкой безопасности страниц назначения,
 // выстраивает цепочки обработки для разных типов запросов и запрашиваемых путей.
-func NewHandler(s storage.Storager, bURL string, auth auth.Authenticator, trustedSubnet string) *Handler {
+// Если обработчик проверки исходных URL не задан, используются настройки проверки по умолчанию.
+// Если проверка безопасности страниц назначения не задана, страницы назначения не проверяются.
+// IP-адрес клиента для проверки доверенной подсети берётся из заголовков, только если запрос получен от доверенного прокси.
+// Если клиент провайдера OpenID Connect не задан, вход пользователей через провайдера недоступен.
+func NewHandler(s storage.Storager, bURL string, authenticator auth.Authenticator, trustedSubnet string, proxies *realip.Resolver, recorder *analytics.Recorder, limiter *ratelimit.ResourceLimiter, normalizer *urlnorm.Normalizer, checker safety.DestinationChecker, provider *oidc.Client) *Handler {
 	baseURL = bURL
 	log.Println("Base URL:", baseURL)
 
 	handler := &Handler{
 		chi.NewMux(),
 		s,
-		auth,
+		authenticator,
 		nil,
+		proxies,
+		recorder,
+		limiter,
+		normalizer,
+		checker,
+		provider,
+	}
+
+	if handler.normalizer == nil {
+		handler.normalizer = urlnorm.NewNormalizer(urlnorm.Options{})
 	}
 
 	_, ipNet, err := net.ParseCIDR(trustedSubnet)
@@ -100,13 +157,52 @@ func NewHandler(s storage.Storager, bURL string, auth auth.Authenticator, truste
 		handler.Use(gzipHandler)
 
 		r.Get("/{id}", handler.getLongURL)
-		r.Get("/api/user/urls", handler.getLongURLsByUser)
 		r.Get("/ping", handler.ping)
-		r.Post("/", handler.postLongURL)
-		r.Post("/api/shorten", handler.postLongURLinJSON)
-		r.Post("/api/shorten/batch", handler.postLongURLinJSONbatch)
-		r.Delete("/api/user/urls", handler.deleteURLs)
+		r.Post("/{id}", handler.postPassword)
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeCreate))
+
+			r.Post("/", handler.postLongURL)
+			r.Post("/api/shorten", handler.postLongURLinJSON)
+			r.Post("/api/shorten/batch", handler.postLongURLinJSONbatch)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeUpdate))
+
+			r.Patch("/api/user/urls/{id}", handler.patchURL)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeRead))
+
+			r.Get("/api/user/urls", handler.getLongURLsByUser)
+			r.Get("/api/user/urls/{id}", handler.getURL)
+			r.Get("/api/user/urls/{id}/stats", handler.getURLStats)
+			r.Get("/api/user/urls/deletions/{job}", handler.getDeletionJob)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeDelete))
+
+			r.Delete("/api/user/urls", handler.deleteURLs)
+			r.Post("/api/user/urls/{id}/restore", handler.restoreURL)
+		})
+
 		r.Get("/api/internal/stats", handler.getStatistics)
+		r.Post("/api/internal/compact", handler.postCompaction)
+		r.Put("/api/internal/urls/{id}/flag", handler.flagURL)
+		r.Delete("/api/internal/urls/{id}/flag", handler.flagURL)
+		r.Post("/api/internal/keys", handler.postAPIKey)
+		r.Get("/api/internal/keys", handler.getAPIKeys)
+		r.Delete("/api/internal/keys/{id}", handler.revokeAPIKey)
+
+		if handler.provider != nil {
+			r.Get("/auth/login", handler.signIn)
+			r.Get("/auth/callback", handler.callback)
+			r.Get("/auth/logout", handler.signOut)
+		}
 		r.MethodNotAllowed(handler.badRequest)
 	})
 
@@ -125,7 +221,10 @@ func (h *Handler) getLongURL(w http.ResponseWriter, r *http.Request) {
 	shortURL := strings.Trim(r.URL.Path, "/")
 	log.Println("Идентификатор короткого URL, полученный из GET-запроса:", shortURL)
 
-	result, err := h.storage.FindURL(shortURL)
+	result, err := h.storage.FindURL(r.Context(), shortURL)
+	if storageTimeout(w, err) {
+		return
+	}
 	if err != nil {
 		log.Println("Ошибка '", err, "'. Не найден URL с указанным коротким идентификатором:", shortURL)
 		http.Error(w, "URL с указанным коротким идентификатором не найден", http.StatusBadRequest)
@@ -138,39 +237,117 @@ func (h *Handler) getLongURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("Найден URL", result.LongURL, "для короткого идентификатора", shortURL)
-	w.Header().Set("Location", result.LongURL)
-	w.WriteHeader(http.StatusTemporaryRedirect)
+	if result.Expired(time.Now()) {
+		log.Println("Срок действия короткого идентификатора", shortURL, "истёк", result.ExpiresAt)
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+
+	if result.Protected() {
+		log.Println("Короткий идентификатор", shortURL, "защищён паролем")
+		renderPasswordForm(w, shortURL, "", http.StatusOK)
+		return
+	}
+
+	h.redirect(w, r, shortURL, result, http.StatusTemporaryRedirect)
 }
 
+func (h *Handler) getURLStats(w http.ResponseWriter, r *http.Request) {
+	log.Println("Полученный GET-запрос:", r.URL)
+
+	shortURL := chi.URLParam(r, "id")
+	result, err := h.storage.FindURL(r.Context(), shortURL)
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "'. Не найден URL с указанным коротким идентификатором:", shortURL)
+		http.Error(w, "URL с указанным коротким идентификатором не найден", http.StatusNotFound)
+		return
+	}
+
+	user := auth.UserFromContext(r.Context())
+	if result.User != user.ID {
+		log.Println("Короткий идентификатор", shortURL, "не принадлежит пользователю", user.ID)
+		http.Error(w, "статистика доступна только пользователю, создавшему короткий URL", http.StatusForbidden)
+		return
+	}
+
+	clicks, err := h.storage.GetClicks(r.Context(), shortURL)
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при получении переходов по короткому идентификатору:", shortURL)
+		http.Error(w, "ошибка при получении статистики: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	err = enc.Encode(analytics.Summarize(baseURL+shortURL, clicks))
+	if err != nil {
+		http.Error(w, "не удалось закодировать в JSON статистику переходов", http.StatusInternalServerError)
+	}
+}
+
+// getLongURLsByUser отвечает страницей списка коротких URL пользователя в формате JSON.
+// Параметры запроса: limit - размер страницы, cursor - курсор следующей страницы из заголовка X-Next-Cursor
+// предыдущего ответа, order - порядок сортировки по моменту создания (asc или desc),
+// status - фильтр по состоянию (active, deleted или expired, по умолчанию все, кроме удалённых), q - подстрока исходного URL.
 func (h *Handler) getLongURLsByUser(w http.ResponseWriter, r *http.Request) {
 	log.Println("Полученный GET-запрос:", r.URL)
 
-	urls := h.storage.GetURLsByUser(h.auth.GetUserID())
-	if len(urls) == 0 {
-		log.Println("Для пользователя с идентификатором '" + h.auth.GetUserID() + "' не найдены сохранённые URL")
-		w.WriteHeader(http.StatusNoContent)
+	query, err := newListQuery(r.URL.Query())
+	if err != nil {
+		log.Println("Неверные параметры запроса списка URL:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	log.Println("Для пользователя с идентификатором '"+h.auth.GetUserID()+"' найдено ", len(urls), "сохранённых URL:")
 
-	response := make(shortAndLongURLs, 0)
-	for i, shortURL := range urls {
-		result, err := h.storage.FindURL(shortURL)
-		if err != nil {
-			continue
-		}
+	user := auth.UserFromContext(r.Context())
+	page, err := h.storage.ListURLs(r.Context(), user.ID, query)
+	if storageTimeout(w, err) {
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при поиске URL пользователя с идентификатором", user.ID)
+		http.Error(w, "ошибка при поиске URL пользователя: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-		record := shortAndLongURL{baseURL + shortURL, result.LongURL}
-		log.Println("Запись", i, "короткий URL", record.ShortURL, "длинный URL", record.LongURL)
-		response = append(response, record)
+	if len(page.URLs) == 0 && query.Cursor == "" {
+		log.Println("Для пользователя с идентификатором '" + user.ID + "' не найдены сохранённые URL")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	log.Println("Для пользователя с идентификатором '"+user.ID+"' найдено ", len(page.URLs), "сохранённых URL")
+
+	response := make(shortAndLongURLs, 0, len(page.URLs))
+	for _, e := range page.URLs {
+		response = append(response, shortAndLongURL{
+			ShortURL:  baseURL + e.ShortURL,
+			LongURL:   e.LongURL,
+			CreatedAt: timeOrNil(e.CreatedAt),
+			Deleted:   e.Deleted,
+			ExpiresAt: timeOrNil(e.ExpiresAt),
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
 	w.WriteHeader(http.StatusOK)
 
 	enc := json.NewEncoder(w)
-	err := enc.Encode(response)
+	err = enc.Encode(response)
 	if err != nil {
 		http.Error(w, "не удалось закодировать в JSON список URL", http.StatusInternalServerError)
 	}
@@ -184,16 +361,16 @@ func (h *Handler) postLongURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	longURL := string(b)
-
-	if len(longURL) == 0 {
-		log.Println("Неверный формат URL")
-		http.Error(w, "неверный формат URL", http.StatusBadRequest)
+	longURL, ok := h.checkURL(w, r, string(b), "")
+	if !ok {
+		return
+	}
 
+	shortURL, err := h.storage.AddURL(r.Context(), longURL, auth.UserFromContext(r.Context()).ID, storage.URLOptions{})
+	if storageTimeout(w, err) {
 		return
 	}
 
-	shortURL, err := h.storage.AddURL(longURL, h.auth.GetUserID())
 	if err != nil && errors.Is(err, storage.DBErrorUnknown) {
 		log.Println("Ошибка '", err, "' при добавлении в БД URL:", longURL)
 		http.Error(w, "ошибка при добавлении в БД: "+err.Error(), http.StatusInternalServerError)
@@ -235,14 +412,53 @@ func (h *Handler) postLongURLinJSON(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Println("Пришедший в запросе исходный URL:", requestBody.URL)
-	if len(requestBody.URL) == 0 {
-		log.Println("Неверный формат URL")
-		http.Error(w, "неверный формат URL", http.StatusBadRequest)
+	var ok bool
+	requestBody.URL, ok = h.checkURL(w, r, requestBody.URL, "")
+	if !ok {
+		return
+	}
+
+	expiresAt, err := storage.NewExpiration(requestBody.ExpiresAt, requestBody.TTLSeconds, time.Now())
+	if err != nil {
+		log.Println("Ошибка '", err, "' при проверке срока действия URL:", requestBody.URL)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	options := storage.URLOptions{Alias: requestBody.Alias, ExpiresAt: expiresAt}
+	if requestBody.Password != "" {
+		options.PasswordHash, err = storage.HashPassword(requestBody.Password)
+		if err != nil {
+			log.Println("Ошибка '", err, "' при обработке пароля для URL:", requestBody.URL)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	var duplicateFound bool
-	shortURL, err := h.storage.AddURL(requestBody.URL, h.auth.GetUserID())
+	shortURL, err := h.storage.AddURL(r.Context(), requestBody.URL, auth.UserFromContext(r.Context()).ID, options)
+	if storageTimeout(w, err) {
+		return
+	}
+
+	if err != nil && errors.Is(err, storage.ErrAliasInvalid) {
+		log.Println("Ошибка '", err, "' при проверке псевдонима:", requestBody.Alias)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil && errors.Is(err, storage.ErrAliasTaken) {
+		log.Println("Псевдоним", requestBody.Alias, "уже занят")
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err != nil && errors.Is(err, storage.DBErrorDublicate) && options.Alias != "" && shortURL != options.Alias {
+		log.Println("Исходный URL", requestBody.URL, "уже сокращён как", shortURL, ", псевдоним", requestBody.Alias, "не создан")
+		http.Error(w, "исходный URL уже сокращён как "+baseURL+shortURL+", псевдоним не создан", http.StatusConflict)
+		return
+	}
+
 	if err != nil && errors.Is(err, storage.DBErrorUnknown) {
 		log.Println("Ошибка '", err, "' при добавлении в БД URL:", requestBody.URL)
 		http.Error(w, "ошибка при добавлении в БД: "+err.Error(), http.StatusInternalServerError)
@@ -281,12 +497,16 @@ func (h *Handler) postLongURLinJSON(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) ping(w http.ResponseWriter, r *http.Request) {
-	err := h.storage.Ping()
+	err := h.storage.Ping(r.Context())
+	if storageTimeout(w, err) {
+		return
+	}
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -314,10 +534,25 @@ func (h *Handler) postLongURLinJSONbatch(w http.ResponseWriter, r *http.Request)
 
 	var longURLs = make(storage.BatchURLs, 0, len(requestBody))
 	for _, requestRecord := range requestBody {
-		longURLs = append(longURLs, storage.RecordURL{ID: requestRecord.ID, URL: requestRecord.URL})
+		longURL, ok := h.checkURL(w, r, requestRecord.URL, requestRecord.ID)
+		if !ok {
+			return
+		}
+
+		expiresAt, err := storage.NewExpiration(requestRecord.ExpiresAt, requestRecord.TTLSeconds, time.Now())
+		if err != nil {
+			log.Println("Ошибка '", err, "' при проверке срока действия URL:", requestRecord.URL)
+			http.Error(w, "запись "+requestRecord.ID+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		longURLs = append(longURLs, storage.RecordURL{ID: requestRecord.ID, URL: longURL, ExpiresAt: expiresAt})
 	}
 
-	shortURLs, err := h.storage.AddURLs(longURLs, h.auth.GetUserID())
+	shortURLs, err := h.storage.AddURLs(r.Context(), longURLs, auth.UserFromContext(r.Context()).ID)
+	if storageTimeout(w, err) {
+		return
+	}
 	if err != nil {
 		log.Println("Ошибка '", err, "' при добавлении в БД URLs:", longURLs)
 		http.Error(w, "ошибка при добавлении в БД URLs: "+err.Error(), http.StatusInternalServerError)
@@ -326,7 +561,7 @@ func (h *Handler) postLongURLinJSONbatch(w http.ResponseWriter, r *http.Request)
 
 	var responseBody = make(PostResponseBatch, 0, len(shortURLs))
 	for _, shortURL := range shortURLs {
-		responseRecord := PostResponseRecord{ID: shortURL.ID, ShortURL: baseURL + shortURL.URL}
+		responseRecord := PostResponseRecord{ID: shortURL.ID, ShortURL: baseURL + shortURL.URL, Duplicate: shortURL.Duplicate}
 		responseBody = append(responseBody, responseRecord)
 	}
 
@@ -377,43 +612,198 @@ func (h *Handler) deleteURLs(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Список подлежащих удалению коротких идентификаторов URL:\n", requestBody)
 
-	_ = h.storage.DeleteURLs(requestBody, h.auth.GetUserID())
+	job, err := h.storage.DeleteURLs(r.Context(), requestBody, auth.UserFromContext(r.Context()).ID)
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при удалении URL:", requestBody)
+		http.Error(w, "ошибка при удалении URL: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	w.WriteHeader(http.StatusAccepted)
+	log.Println("Создано задание на удаление", job.ID)
+	w.Header().Set("Location", baseURL+"api/user/urls/deletions/"+job.ID)
+	writeDeletionJob(w, job, http.StatusAccepted)
 }
 
 func (h *Handler) getStatistics(w http.ResponseWriter, r *http.Request) {
 	log.Println("Обработка запроса на получение статистики сервиса")
 
+	if !h.trusted(w, r) {
+		return
+	}
+
+	var response serviceStatistics
+	var err error
+	response.URLs, response.Users, err = h.storage.GetStatistics(r.Context())
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при получении статистики сервиса")
+		http.Error(w, "ошибка при получении статистики сервиса: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if statser, ok := h.storage.(storage.PoolStatser); ok {
+		if stats, ok := statser.PoolStats(); ok {
+			response.Pool = &stats
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	err = enc.Encode(response)
+	if err != nil {
+		http.Error(w, "не удалось закодировать в JSON статистику сервиса", http.StatusInternalServerError)
+	}
+}
+
+// trusted проверяет, что запрос получен из доверенной IP-подсети, и отвечает кодом 403, если это не так.
+// IP-адрес клиента определяется по адресу соединения, а заголовок X-Real-IP учитывается, только если
+// запрос получен от доверенного обратного прокси. Возвращает признак того, что запрос можно обрабатывать.
+func (h *Handler) trusted(w http.ResponseWriter, r *http.Request) bool {
 	if h.trustedIpSubnet == nil {
 		log.Println("Доверенная IP-подсеть не задана")
 		w.WriteHeader(http.StatusForbidden)
-		return
+		return false
 	}
 
-	realIp := net.ParseIP(r.Header.Get("X-Real-IP"))
+	realIp := h.proxies.ClientIP(r)
 	if realIp == nil {
-		log.Println("Заголовок X-Real-IP не передан")
+		log.Println("Не удалось определить IP клиента")
 		w.WriteHeader(http.StatusForbidden)
-		return
+		return false
 	}
 	log.Println("Real IP:", realIp)
 
 	if !h.trustedIpSubnet.Contains(realIp) {
 		log.Println("IP клиента", realIp, "находится вне IP-подсети", h.trustedIpSubnet)
 		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+func (h *Handler) postCompaction(w http.ResponseWriter, r *http.Request) {
+	log.Println("Обработка запроса на сжатие файла хранилища")
+
+	if !h.trusted(w, r) {
+		return
+	}
+
+	compactor, ok := h.storage.(storage.Compactor)
+	if !ok {
+		http.Error(w, "сжатие не поддерживается используемым хранилищем", http.StatusNotImplemented)
+		return
+	}
+
+	result, err := compactor.Compact(r.Context())
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при сжатии файла хранилища")
+		http.Error(w, "ошибка при сжатии файла хранилища: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	var response serviceStatistics
-	response.URLs, response.Users = h.storage.GetStatistics()
+	enc := json.NewEncoder(w)
+	err = enc.Encode(result)
+	if err != nil {
+		log.Println("Не удалось закодировать в JSON результат сжатия файла хранилища:", err)
+	}
+}
+
+// checkURL проверяет исходный URL, приводит его к единому виду и проверяет безопасность его страницы назначения.
+// При недопустимом исходном URL отвечает кодом 400, при небезопасной странице назначения - кодом 403,
+// в обоих случаях с описанием ошибки в формате JSON.
+// Возвращает признак того, что исходный URL допустим и ответ ещё не отправлен.
+func (h *Handler) checkURL(w http.ResponseWriter, r *http.Request, raw, id string) (string, bool) {
+	longURL, err := h.normalizer.Normalize(raw)
+	if err != nil {
+		log.Println("Ошибка '", err, "' при проверке исходного URL:", raw)
+
+		response := ErrorResponse{Error: "invalid_url", Message: err.Error(), ID: id}
+		var urlErr *urlnorm.Error
+		if errors.As(err, &urlErr) {
+			response.Reason = string(urlErr.Reason)
+		}
+
+		writeError(w, response, http.StatusBadRequest)
+		return "", false
+	}
+
+	if h.checker == nil {
+		return longURL, true
+	}
+
+	err = h.checker.Check(r.Context(), longURL)
+	var safetyErr *safety.Error
+	if errors.As(err, &safetyErr) {
+		log.Println("Страница назначения исходного URL", longURL, "признана небезопасной:", err)
+		writeError(w, ErrorResponse{Error: "unsafe_url", Reason: string(safetyErr.Reason), Message: err.Error(), ID: id}, http.StatusForbidden)
+		return "", false
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "' при проверке страницы назначения исходного URL:", longURL)
+		http.Error(w, "ошибка при проверке страницы назначения: "+err.Error(), http.StatusInternalServerError)
+		return "", false
+	}
+
+	return longURL, true
+}
+
+// newListQuery формирует параметры запроса страницы списка коротких URL из параметров URL запроса.
+func newListQuery(values url.Values) (storage.ListQuery, error) {
+	var limit int
+	if raw := values.Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			return storage.ListQuery{}, storage.ErrInvalidLimit
+		}
+	}
+
+	return storage.NewListQuery(limit, values.Get("cursor"), values.Get("order"), values.Get("status"), values.Get("q"))
+}
+
+// timeOrNil возвращает ссылку на заданный момент времени или nil для нулевого значения.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// writeError отвечает заданным кодом с описанием ошибки в формате JSON.
+func writeError(w http.ResponseWriter, response ErrorResponse, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
 
 	enc := json.NewEncoder(w)
 	err := enc.Encode(response)
 	if err != nil {
-		http.Error(w, "не удалось закодировать в JSON статистику сервиса", http.StatusInternalServerError)
+		log.Println("Не удалось закодировать в JSON описание ошибки:", err)
 	}
 }
+
+// storageTimeout отвечает кодом 504, если операция с хранилищем прервана из-за истечения срока выполнения запроса.
+// Возвращает признак того, что ответ уже отправлен.
+func storageTimeout(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	log.Println("Превышено время ожидания ответа от хранилища:", err)
+	http.Error(w, "превышено время ожидания ответа от хранилища", http.StatusGatewayTimeout)
+	return true
+}
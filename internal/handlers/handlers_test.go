@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/oidc"
+	"github.com/StainlessSteelSnake/shurl/internal/oidc/oidctest"
+	"github.com/StainlessSteelSnake/shurl/internal/ratelimit"
+	"github.com/StainlessSteelSnake/shurl/internal/realip"
+	"github.com/StainlessSteelSnake/shurl/internal/safety"
 	"github.com/StainlessSteelSnake/shurl/internal/storage"
+	"github.com/StainlessSteelSnake/shurl/internal/urlnorm"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,28 +30,36 @@ type dummyStorage struct {
 	usersURLs map[string][]string
 }
 
-func (s *dummyStorage) AddURL(l, user string) (string, error) {
+func (s *dummyStorage) AddURL(ctx context.Context, l, user string, opts storage.URLOptions) (string, error) {
 	s.container[l] = l
 	s.usersURLs[user] = append(s.usersURLs[user], l)
 	return l, nil
 }
 
-func (s *dummyStorage) FindURL(sh string) (storage.MemoryRecord, error) {
+func (s *dummyStorage) FindURL(ctx context.Context, sh string) (storage.MemoryRecord, error) {
 	if l, ok := s.container[sh]; ok {
 		return storage.MemoryRecord{LongURL: l, User: "", Deleted: false}, nil
 	}
 	return storage.MemoryRecord{LongURL: "", User: "", Deleted: false}, errors.New("короткий URL с ID \" + string(sh) + \" не существует")
 }
 
-func (s *dummyStorage) GetURLsByUser(u string) []string {
-	return s.usersURLs[u]
+func (s *dummyStorage) GetURLsByUser(ctx context.Context, u string) ([]string, error) {
+	return s.usersURLs[u], nil
 }
 
-func (s *dummyStorage) GetStatistics() (int, int) {
-	return 1, 1
+func (s *dummyStorage) ListURLs(ctx context.Context, u string, q storage.ListQuery) (storage.ListPage, error) {
+	page := storage.ListPage{}
+	for _, sh := range s.usersURLs[u] {
+		page.URLs = append(page.URLs, storage.URLEntry{ShortURL: sh, MemoryRecord: storage.MemoryRecord{LongURL: s.container[sh], User: u}})
+	}
+	return page, nil
+}
+
+func (s *dummyStorage) GetStatistics(ctx context.Context) (int, int, error) {
+	return 1, 1, nil
 }
 
-func (s *dummyStorage) Ping() error {
+func (s *dummyStorage) Ping(ctx context.Context) error {
 	return nil
 }
 
@@ -48,7 +67,7 @@ func (s *dummyStorage) CloseFunc() func() {
 	return nil
 }
 
-func (s *dummyStorage) AddURLs(b storage.BatchURLs, user string) (storage.BatchURLs, error) {
+func (s *dummyStorage) AddURLs(ctx context.Context, b storage.BatchURLs, user string) (storage.BatchURLs, error) {
 	for _, record := range b {
 		s.container[record.ID] = record.URL
 		s.usersURLs[user] = append(s.usersURLs[user], record.URL)
@@ -57,8 +76,50 @@ func (s *dummyStorage) AddURLs(b storage.BatchURLs, user string) (storage.BatchU
 	return b, nil
 }
 
-func (s *dummyStorage) DeleteURLs(urls []string, user string) []string {
-	return urls
+func (s *dummyStorage) DeleteURLs(ctx context.Context, urls []string, user string) (storage.DeletionJob, error) {
+	return storage.DeletionJob{}, nil
+}
+
+func (s *dummyStorage) GetDeletionJob(ctx context.Context, id, user string) (storage.DeletionJob, error) {
+	return storage.DeletionJob{}, storage.ErrDeletionJobNotFound
+}
+
+func (s *dummyStorage) UpdateURL(ctx context.Context, sh, l, user string) error {
+	return nil
+}
+
+func (s *dummyStorage) RestoreURL(ctx context.Context, sh, user string) error {
+	return nil
+}
+
+func (s *dummyStorage) AddClicks(ctx context.Context, clicks []storage.Click) error {
+	return nil
+}
+
+func (s *dummyStorage) GetClicks(ctx context.Context, sh string) ([]storage.Click, error) {
+	return nil, nil
+}
+
+// timeoutStorage имитирует хранилище, операции с которым прерываются по истечении срока выполнения запроса.
+type timeoutStorage struct {
+	dummyStorage
+}
+
+func (s *timeoutStorage) FindURL(ctx context.Context, sh string) (storage.MemoryRecord, error) {
+	return storage.MemoryRecord{}, context.DeadlineExceeded
+}
+
+func (s *timeoutStorage) AddURL(ctx context.Context, l, user string, opts storage.URLOptions) (string, error) {
+	return "", fmt.Errorf("ошибка запроса к БД: %w", context.DeadlineExceeded)
+}
+
+// poolStorage имитирует хранилище, использующее пул соединений.
+type poolStorage struct {
+	dummyStorage
+}
+
+func (s *poolStorage) PoolStats() (storage.PoolStats, bool) {
+	return storage.PoolStats{TotalConns: 4, IdleConns: 3, AcquiredConns: 1, MaxConns: 10}, true
 }
 
 func TestGzipWriter_Write(t *testing.T) {
@@ -152,7 +213,7 @@ func BenchmarkNewHandler(b *testing.B) {
 		for _, tt := range tests {
 			b.Run(tt.name, func(b *testing.B) {
 				s := &dummyStorage{tt.storage, tt.user}
-				h := NewHandler(s, tt.baseURL, auth.NewAuth(), "")
+				h := NewHandler(s, tt.baseURL, auth.NewAuth(nil, auth.Options{}), "", nil, nil, nil, nil, nil, nil)
 
 				request := httptest.NewRequest(tt.method, tt.request, nil)
 				writer := httptest.NewRecorder()
@@ -214,7 +275,7 @@ func TestNewHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &dummyStorage{tt.storage, tt.user}
-			h := NewHandler(s, tt.baseURL, auth.NewAuth(), "")
+			h := NewHandler(s, tt.baseURL, auth.NewAuth(nil, auth.Options{}), "", nil, nil, nil, nil, nil, nil)
 
 			request := httptest.NewRequest(tt.method, tt.request, nil)
 			writer := httptest.NewRecorder()
@@ -367,7 +428,7 @@ func Test_postLongURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth()}
+			h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth(nil, auth.Options{}), normalizer: urlnorm.NewNormalizer(urlnorm.Options{})}
 
 			writer := httptest.NewRecorder()
 			requestBody := strings.NewReader(tt.longURL)
@@ -425,7 +486,7 @@ func Benchmark_postLongURL(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, tt := range tests {
 			b.Run(tt.name, func(b *testing.B) {
-				h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth()}
+				h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth(nil, auth.Options{}), normalizer: urlnorm.NewNormalizer(urlnorm.Options{})}
 
 				writer := httptest.NewRecorder()
 				requestBody := strings.NewReader(tt.longURL)
@@ -507,7 +568,7 @@ func Test_postLongURLinJSON(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth()}
+			h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth(nil, auth.Options{}), normalizer: urlnorm.NewNormalizer(urlnorm.Options{})}
 
 			writer := httptest.NewRecorder()
 			requestBody := strings.NewReader(tt.longURL)
@@ -592,7 +653,7 @@ func Benchmark_postLongURLinJSON(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, tt := range tests {
 			b.Run(tt.name, func(b *testing.B) {
-				h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth()}
+				h := Handler{storage: &dummyStorage{tt.storage, tt.user}, auth: auth.NewAuth(nil, auth.Options{}), normalizer: urlnorm.NewNormalizer(urlnorm.Options{})}
 
 				writer := httptest.NewRecorder()
 				requestBody := strings.NewReader(tt.longURL)
@@ -615,3 +676,1036 @@ func Benchmark_postLongURLinJSON(b *testing.B) {
 		}
 	}
 }
+
+func Test_postPassword(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	hash, err := storage.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shortURL, err := s.AddURL(context.Background(), "https://ya.ru", "user1", storage.URLOptions{PasswordHash: hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(s, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "", nil, nil, ratelimit.NewResourceLimiter(2, 4, time.Minute), nil, nil, nil)
+
+	const (
+		owner     = "198.51.100.1:1234"
+		attacker  = "203.0.113.5:1234"
+		attacker2 = "203.0.113.6:1234"
+		attacker3 = "203.0.113.7:1234"
+	)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		method     string
+		password   string
+		wantCode   int
+		wantURL    string
+	}{
+		{"Переход по защищённой ссылке выдаёт форму", owner, http.MethodGet, "", http.StatusOK, ""},
+		{"Неверный пароль", owner, http.MethodPost, "wrong", http.StatusUnauthorized, ""},
+		{"Верный пароль", owner, http.MethodPost, "secret", http.StatusSeeOther, "https://ya.ru"},
+		{"Успешные попытки не учитываются", owner, http.MethodPost, "secret", http.StatusSeeOther, "https://ya.ru"},
+		{"Подбор пароля другим клиентом", attacker, http.MethodPost, "wrong1", http.StatusUnauthorized, ""},
+		{"Повторный подбор пароля", attacker, http.MethodPost, "wrong2", http.StatusUnauthorized, ""},
+		{"Превышено количество попыток", attacker, http.MethodPost, "secret", http.StatusTooManyRequests, ""},
+		{"Верный пароль во время подбора другим клиентом", owner, http.MethodPost, "secret", http.StatusSeeOther, "https://ya.ru"},
+		{"Подбор пароля с другого адреса", attacker2, http.MethodPost, "wrong3", http.StatusUnauthorized, ""},
+		{"Превышено общее количество попыток для ссылки", attacker3, http.MethodPost, "wrong4", http.StatusTooManyRequests, ""},
+		{"Общий лимит действует для всех клиентов", owner, http.MethodPost, "secret", http.StatusTooManyRequests, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader(url.Values{"password": {tt.password}}.Encode())
+			}
+
+			request := httptest.NewRequest(tt.method, "/"+shortURL, body)
+			request.RemoteAddr = tt.remoteAddr
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+			assert.Equal(t, tt.wantURL, result.Header.Get("Location"))
+			if err := result.Body.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func Test_storageTimeout(t *testing.T) {
+	h := NewHandler(&timeoutStorage{}, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "", nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{"Поиск длинного URL", http.MethodGet, "/dummy", ""},
+		{"Сокращение URL", http.MethodPost, "/", "https://ya.ru"},
+		{"Сокращение URL в формате JSON", http.MethodPost, "/api/shorten", `{"url": "https://ya.ru"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			defer result.Body.Close()
+			assert.Equal(t, http.StatusGatewayTimeout, result.StatusCode)
+		})
+	}
+}
+
+// compactingStorage имитирует хранилище, поддерживающее сжатие.
+type compactingStorage struct {
+	dummyStorage
+}
+
+func (s *compactingStorage) Compact(ctx context.Context) (storage.CompactionResult, error) {
+	return storage.CompactionResult{LinesBefore: 3, LinesAfter: 1, SizeBefore: 300, SizeAfter: 100}, nil
+}
+
+func Test_postCompaction(t *testing.T) {
+	tests := []struct {
+		name     string
+		storage  storage.Storager
+		realIP   string
+		wantCode int
+		wantBody string
+	}{
+		{"Запрос вне доверенной подсети", &compactingStorage{}, "10.0.0.1", http.StatusForbidden, ""},
+		{"Запрос без заголовка X-Real-IP", &compactingStorage{}, "", http.StatusForbidden, ""},
+		{"Хранилище без поддержки сжатия", &dummyStorage{}, "192.168.1.10", http.StatusNotImplemented, ""},
+		{
+			"Успешное сжатие",
+			&compactingStorage{},
+			"192.168.1.10",
+			http.StatusOK,
+			`{"lines_before":3,"lines_after":1,"size_before":300,"size_after":100}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(tt.storage, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "192.168.1.0/24", testProxies(t), nil, nil, nil, nil, nil)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/internal/compact", nil)
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			defer result.Body.Close()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+
+			if tt.wantBody != "" {
+				body, err := io.ReadAll(result.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantBody, string(body))
+			}
+		})
+	}
+}
+
+func Test_postLongURLinJSONbatchDuplicates(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	shortURL, err := s.AddURL(context.Background(), "https://ya.ru", "user1", storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(s, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "", nil, nil, nil, nil, nil, nil)
+
+	body := `[{"correlation_id":"1","original_url":"https://ya.ru"},{"correlation_id":"2","original_url":"https://google.com"}]`
+	request := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", strings.NewReader(body))
+	writer := httptest.NewRecorder()
+
+	h.ServeHTTP(writer, request)
+
+	result := writer.Result()
+	defer result.Body.Close()
+	assert.Equal(t, http.StatusCreated, result.StatusCode)
+
+	var response PostResponseBatch
+	if err := json.NewDecoder(result.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, response, 2)
+	assert.Equal(t, PostResponseRecord{ID: "1", ShortURL: "http://localhost:8080/" + shortURL, Duplicate: true}, response[0])
+	assert.False(t, response[1].Duplicate)
+}
+
+func Test_postLongURLinJSONaliasDuplicate(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	shortURL, err := s.AddURL(context.Background(), "https://ya.ru", "user1", storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.AddURL(context.Background(), "https://go.dev", "user1", storage.URLOptions{Alias: "golang"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(s, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "", nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"Псевдоним для ранее сокращённого URL", `{"url":"https://ya.ru","alias":"yandex"}`, http.StatusConflict, "псевдоним не создан"},
+		{"Повторный запрос с тем же псевдонимом", `{"url":"https://go.dev","alias":"golang"}`, http.StatusConflict, `"result":"http://localhost:8080/golang"`},
+		{"Ранее сокращённый URL без псевдонима", `{"url":"https://ya.ru"}`, http.StatusConflict, `"result":"http://localhost:8080/` + shortURL + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(tt.body))
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			defer result.Body.Close()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+
+			body, err := io.ReadAll(result.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), tt.wantBody)
+		})
+	}
+
+	_, err = s.FindURL(context.Background(), "yandex")
+	assert.Error(t, err, "псевдоним не создаётся")
+}
+
+func Test_checkURL(t *testing.T) {
+	h := NewHandler(storage.NewMemoryStorage(), "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "", nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		target   string
+		body     string
+		wantCode int
+		want     ErrorResponse
+	}{
+		{
+			"Пустой URL",
+			"/", "",
+			http.StatusBadRequest,
+			ErrorResponse{Error: "invalid_url", Reason: "empty", Message: "исходный URL не задан"},
+		},
+		{
+			"Недопустимая схема в формате JSON",
+			"/api/shorten", `{"url": "javascript:alert(1)"}`,
+			http.StatusBadRequest,
+			ErrorResponse{Error: "invalid_url", Reason: "scheme_not_allowed", Message: "недопустимая схема URL: 'javascript'"},
+		},
+		{
+			"Слишком длинный URL в пакете",
+			"/api/shorten/batch", `[{"correlation_id":"1","original_url":"https://ya.ru"},{"correlation_id":"2","original_url":"https://ya.ru/` + strings.Repeat("a", 2048) + `"}]`,
+			http.StatusBadRequest,
+			ErrorResponse{Error: "invalid_url", Reason: "too_long", Message: "длина URL превышает допустимую", ID: "2"},
+		},
+		{"Нормализованный URL", "/api/shorten", `{"url": "HTTPS://YA.RU:443/?utm_source=mail"}`, http.StatusCreated, ErrorResponse{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			defer result.Body.Close()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+			if tt.wantCode != http.StatusBadRequest {
+				return
+			}
+
+			assert.Equal(t, "application/json", result.Header.Get("Content-Type"))
+
+			var response ErrorResponse
+			if err := json.NewDecoder(result.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, response)
+		})
+	}
+
+	sh, err := h.storage.AddURL(context.Background(), "https://ya.ru/", "user1", storage.URLOptions{})
+	assert.ErrorIs(t, err, storage.DBErrorDublicate, "сохранён нормализованный URL")
+	assert.NotEmpty(t, sh)
+}
+
+func Test_flagURL(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	shortURL, err := s.AddURL(context.Background(), "https://ya.ru", "user1", storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(s, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "192.168.1.0/24", testProxies(t), nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		realIP      string
+		wantCode    int
+		wantWarning bool
+	}{
+		{"Запрос вне доверенной подсети", http.MethodPut, "/api/internal/urls/" + shortURL + "/flag", "10.0.0.1", http.StatusForbidden, false},
+		{"Неизвестный короткий URL", http.MethodPut, "/api/internal/urls/unknown/flag", "192.168.1.10", http.StatusNotFound, false},
+		{"Установка пометки", http.MethodPut, "/api/internal/urls/" + shortURL + "/flag", "192.168.1.10", http.StatusNoContent, true},
+		{"Снятие пометки", http.MethodDelete, "/api/internal/urls/" + shortURL + "/flag", "192.168.1.10", http.StatusNoContent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, nil)
+			request.Header.Set("X-Real-IP", tt.realIP)
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+			if err := result.Body.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			writer = httptest.NewRecorder()
+			h.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/"+shortURL, nil))
+
+			result = writer.Result()
+			defer result.Body.Close()
+			if tt.wantWarning {
+				body, err := io.ReadAll(result.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, http.StatusOK, result.StatusCode)
+				assert.Empty(t, result.Header.Get("Location"))
+				assert.Contains(t, string(body), "https://ya.ru")
+				return
+			}
+			assert.Equal(t, http.StatusTemporaryRedirect, result.StatusCode)
+			assert.Equal(t, "https://ya.ru", result.Header.Get("Location"))
+		})
+	}
+}
+
+// blockingChecker имитирует проверку, признающую небезопасными все страницы назначения на заданном хосте.
+type blockingChecker struct {
+	host string
+}
+
+func (c blockingChecker) Check(ctx context.Context, longURL string) error {
+	if strings.Contains(longURL, c.host) {
+		return &safety.Error{Reason: safety.ReasonBlocklisted, Message: "домен заблокирован: " + c.host}
+	}
+	return nil
+}
+
+func Test_checkURLUnsafe(t *testing.T) {
+	h := NewHandler(storage.NewMemoryStorage(), "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "", nil, nil, nil, nil, blockingChecker{"evil.example"}, nil)
+
+	tests := []struct {
+		name     string
+		target   string
+		body     string
+		wantCode int
+		wantID   string
+	}{
+		{"Сокращение URL", "/", "https://evil.example/login", http.StatusForbidden, ""},
+		{"Сокращение URL в формате JSON", "/api/shorten", `{"url": "https://evil.example/login"}`, http.StatusForbidden, ""},
+		{
+			"Пакетное сокращение URL",
+			"/api/shorten/batch",
+			`[{"correlation_id":"1","original_url":"https://ya.ru"},{"correlation_id":"2","original_url":"https://evil.example"}]`,
+			http.StatusForbidden,
+			"2",
+		},
+		{"Безопасный URL", "/", "https://ya.ru/", http.StatusCreated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			defer result.Body.Close()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+			if tt.wantCode != http.StatusForbidden {
+				return
+			}
+
+			var response ErrorResponse
+			if err := json.NewDecoder(result.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "unsafe_url", response.Error)
+			assert.Equal(t, string(safety.ReasonBlocklisted), response.Reason)
+			assert.Equal(t, tt.wantID, response.ID)
+		})
+	}
+}
+
+// newTestUser авторизует нового пользователя и возвращает его идентификатор и cookie авторизации.
+// testProxies возвращает обработчик, доверяющий заголовкам от адреса, с которого httptest.NewRequest отправляет запросы.
+func testProxies(t *testing.T) *realip.Resolver {
+	t.Helper()
+
+	proxies, err := realip.NewResolver([]string{"192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return proxies
+}
+
+func newTestUser(t *testing.T, a auth.Authenticator) (string, []*http.Cookie) {
+	t.Helper()
+
+	var user string
+	writer := httptest.NewRecorder()
+	a.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = auth.UserFromContext(r.Context()).ID
+	})).ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	cookies := writer.Result().Cookies()
+	if len(cookies) == 0 || user == "" {
+		t.Fatal("не получены cookie авторизации")
+	}
+
+	return user, cookies
+}
+
+func Test_manageURL(t *testing.T) {
+	ctx := context.Background()
+	s := storage.NewMemoryStorage()
+	a := auth.NewAuth(nil, auth.Options{})
+	h := NewHandler(s, "http://localhost:8080/", a, "", nil, nil, nil, nil, nil, nil)
+
+	user, cookies := newTestUser(t, a)
+
+	owned, err := s.AddURL(ctx, "https://ya.ru", user, storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taken, err := s.AddURL(ctx, "https://google.com", user, storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := s.AddURL(ctx, "https://go.dev", "user2", storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+		wantURL  string
+	}{
+		{"Сведения о коротком URL", http.MethodGet, "/api/user/urls/" + owned, "", http.StatusOK, "https://ya.ru"},
+		{"Сведения о чужом коротком URL", http.MethodGet, "/api/user/urls/" + foreign, "", http.StatusForbidden, ""},
+		{"Сведения о неизвестном коротком URL", http.MethodGet, "/api/user/urls/unknown", "", http.StatusNotFound, ""},
+		{"Изменение исходного URL", http.MethodPatch, "/api/user/urls/" + owned, `{"original_url":"https://YA.ru/path?utm_source=x"}`, http.StatusOK, "https://ya.ru/path"},
+		{"Изменение на недопустимый исходный URL", http.MethodPatch, "/api/user/urls/" + owned, `{"original_url":"ftp://ya.ru"}`, http.StatusBadRequest, ""},
+		{"Изменение на уже сокращённый исходный URL", http.MethodPatch, "/api/user/urls/" + owned, `{"original_url":"https://google.com"}`, http.StatusConflict, ""},
+		{"Изменение чужого короткого URL", http.MethodPatch, "/api/user/urls/" + foreign, `{"original_url":"https://example.com"}`, http.StatusForbidden, ""},
+		{"Неверный формат тела запроса", http.MethodPatch, "/api/user/urls/" + owned, `{`, http.StatusBadRequest, ""},
+		{"Восстановление неудалённого короткого URL", http.MethodPost, "/api/user/urls/" + taken + "/restore", "", http.StatusConflict, ""},
+		{"Восстановление неизвестного короткого URL", http.MethodPost, "/api/user/urls/unknown/restore", "", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for _, c := range cookies {
+				request.AddCookie(c)
+			}
+			writer := httptest.NewRecorder()
+
+			h.ServeHTTP(writer, request)
+
+			result := writer.Result()
+			defer result.Body.Close()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+
+			if tt.wantURL != "" {
+				var metadata URLMetadata
+				assert.NoError(t, json.NewDecoder(result.Body).Decode(&metadata))
+				assert.Equal(t, tt.wantURL, metadata.LongURL)
+				assert.Equal(t, "http://localhost:8080/"+owned, metadata.ShortURL)
+			}
+		})
+	}
+}
+
+func Test_restoreURL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := storage.NewMemoryStorage()
+	s.DeletionQueueProcess(ctx)
+	a := auth.NewAuth(nil, auth.Options{})
+	h := NewHandler(s, "http://localhost:8080/", a, "", nil, nil, nil, nil, nil, nil)
+
+	writer := httptest.NewRecorder()
+	h.ServeHTTP(writer, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru")))
+	result := writer.Result()
+	cookies := result.Cookies()
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Body.Close()
+	shortURL := strings.TrimPrefix(string(body), "http://localhost:8080/")
+
+	send := func(method, target, body string) *http.Response {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		for _, c := range cookies {
+			request.AddCookie(c)
+		}
+		writer := httptest.NewRecorder()
+		h.ServeHTTP(writer, request)
+		return writer.Result()
+	}
+
+	result = send(http.MethodDelete, "/api/user/urls", `["`+shortURL+`"]`)
+	result.Body.Close()
+	assert.Equal(t, http.StatusAccepted, result.StatusCode)
+
+	assert.Eventually(t, func() bool {
+		record, err := s.FindURL(context.Background(), shortURL)
+		return err == nil && record.Deleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	result = send(http.MethodPatch, "/api/user/urls/"+shortURL, `{"original_url":"https://go.dev"}`)
+	result.Body.Close()
+	assert.Equal(t, http.StatusGone, result.StatusCode, "удалённый короткий URL нельзя изменить")
+
+	result = send(http.MethodGet, "/api/user/urls/"+shortURL, "")
+	var metadata URLMetadata
+	assert.NoError(t, json.NewDecoder(result.Body).Decode(&metadata))
+	result.Body.Close()
+	assert.True(t, metadata.Deleted)
+	assert.NotNil(t, metadata.DeletedAt)
+
+	result = send(http.MethodPost, "/api/user/urls/"+shortURL+"/restore", "")
+	metadata = URLMetadata{}
+	assert.NoError(t, json.NewDecoder(result.Body).Decode(&metadata))
+	result.Body.Close()
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.False(t, metadata.Deleted)
+	assert.Nil(t, metadata.DeletedAt)
+
+	result = send(http.MethodGet, "/"+shortURL, "")
+	result.Body.Close()
+	assert.Equal(t, http.StatusTemporaryRedirect, result.StatusCode, "восстановленный короткий URL снова перенаправляет")
+}
+
+func Test_getLongURLsByUserPages(t *testing.T) {
+	ctx := context.Background()
+	s := storage.NewMemoryStorage()
+	a := auth.NewAuth(nil, auth.Options{})
+	h := NewHandler(s, "http://localhost:8080/", a, "", nil, nil, nil, nil, nil, nil)
+
+	user, cookies := newTestUser(t, a)
+
+	for _, l := range []string{"https://ya.ru", "https://google.com", "https://ya.ru/maps"} {
+		if _, err := s.AddURL(ctx, l, user, storage.URLOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	send := func(target string) *http.Response {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		for _, c := range cookies {
+			request.AddCookie(c)
+		}
+		writer := httptest.NewRecorder()
+		h.ServeHTTP(writer, request)
+		return writer.Result()
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		wantCode int
+		wantURLs []string
+		wantNext bool
+	}{
+		{"Первая страница", "/api/user/urls?limit=2", http.StatusOK, []string{"https://ya.ru/maps", "https://google.com"}, true},
+		{"По возрастанию момента создания", "/api/user/urls?order=asc", http.StatusOK, []string{"https://ya.ru", "https://google.com", "https://ya.ru/maps"}, false},
+		{"Поиск по исходному URL", "/api/user/urls?q=YA.RU&order=asc", http.StatusOK, []string{"https://ya.ru", "https://ya.ru/maps"}, false},
+		{"Нет удалённых URL", "/api/user/urls?status=deleted", http.StatusNoContent, nil, false},
+		{"Неверный фильтр состояния", "/api/user/urls?status=unknown", http.StatusBadRequest, nil, false},
+		{"Неверный размер страницы", "/api/user/urls?limit=abc", http.StatusBadRequest, nil, false},
+		{"Неверный курсор", "/api/user/urls?cursor=abc", http.StatusBadRequest, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := send(tt.target)
+			defer result.Body.Close()
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+			assert.Equal(t, tt.wantNext, result.Header.Get("X-Next-Cursor") != "")
+
+			if tt.wantURLs == nil {
+				return
+			}
+
+			var records []shortAndLongURL
+			assert.NoError(t, json.NewDecoder(result.Body).Decode(&records))
+
+			got := make([]string, 0, len(records))
+			for _, r := range records {
+				assert.NotNil(t, r.CreatedAt)
+				got = append(got, r.LongURL)
+			}
+			assert.Equal(t, tt.wantURLs, got)
+		})
+	}
+
+	first := send("/api/user/urls?limit=2")
+	first.Body.Close()
+
+	result := send("/api/user/urls?limit=2&cursor=" + url.QueryEscape(first.Header.Get("X-Next-Cursor")))
+	defer result.Body.Close()
+	var records []shortAndLongURL
+	assert.NoError(t, json.NewDecoder(result.Body).Decode(&records))
+	assert.Len(t, records, 1)
+	assert.Equal(t, "https://ya.ru", records[0].LongURL, "вторая страница продолжает список")
+	assert.Empty(t, result.Header.Get("X-Next-Cursor"))
+}
+
+func Test_getDeletionJob(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := storage.NewMemoryStorage()
+	s.DeletionQueueProcess(ctx)
+	a := auth.NewAuth(nil, auth.Options{})
+	h := NewHandler(s, "http://localhost:8080/", a, "", nil, nil, nil, nil, nil, nil)
+
+	user, cookies := newTestUser(t, a)
+
+	owned, err := s.AddURL(ctx, "https://ya.ru", user, storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := s.AddURL(ctx, "https://google.com", "user2", storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method, target, body string) (*http.Response, DeletionJobResponse) {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		for _, c := range cookies {
+			request.AddCookie(c)
+		}
+		writer := httptest.NewRecorder()
+		h.ServeHTTP(writer, request)
+
+		result := writer.Result()
+		defer result.Body.Close()
+
+		var job DeletionJobResponse
+		if result.Header.Get("Content-Type") == "application/json" {
+			assert.NoError(t, json.NewDecoder(result.Body).Decode(&job))
+		}
+		return result, job
+	}
+
+	result, job := send(http.MethodDelete, "/api/user/urls", `["http://localhost:8080/`+owned+`", "`+foreign+`", "unknown"]`)
+	assert.Equal(t, http.StatusAccepted, result.StatusCode)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, "http://localhost:8080/api/user/urls/deletions/"+job.ID, result.Header.Get("Location"))
+	assert.Equal(t, []DeletionResultRecord{
+		{"http://localhost:8080/" + owned, "queued"},
+		{"http://localhost:8080/" + foreign, "forbidden"},
+		{"http://localhost:8080/unknown", "not_found"},
+	}, job.URLs)
+
+	location, err := url.Parse(result.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Eventually(t, func() bool {
+		result, job = send(http.MethodGet, location.Path, "")
+		return result.StatusCode == http.StatusOK && job.Done
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "deleted", job.URLs[0].Status)
+
+	result, _ = send(http.MethodGet, "/api/user/urls/deletions/unknown", "")
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+}
+
+func TestHandler_concurrentUsers(t *testing.T) {
+	const users, requests = 8, 10
+
+	a := auth.NewAuth(nil, auth.Options{})
+	h := NewHandler(storage.NewMemoryStorage(), "http://localhost:8080/", a, "", nil, nil, nil, nil, nil, nil)
+
+	errs := make(chan error, users)
+	for i := 0; i < users; i++ {
+		user, cookies := newTestUser(t, a)
+
+		go func(i int, user string, cookies []*http.Cookie) {
+			send := func(method, target, body string) *http.Response {
+				request := httptest.NewRequest(method, target, strings.NewReader(body))
+				for _, c := range cookies {
+					request.AddCookie(c)
+				}
+				writer := httptest.NewRecorder()
+				h.ServeHTTP(writer, request)
+				return writer.Result()
+			}
+
+			for j := 0; j < requests; j++ {
+				result := send(http.MethodPost, "/", fmt.Sprintf("https://example.com/%d/%d", i, j))
+				result.Body.Close()
+				if result.StatusCode != http.StatusCreated {
+					errs <- fmt.Errorf("пользователь %s: код ответа на сокращение %d", user, result.StatusCode)
+					return
+				}
+			}
+
+			result := send(http.MethodGet, "/api/user/urls", "")
+			defer result.Body.Close()
+
+			var urls shortAndLongURLs
+			if err := json.NewDecoder(result.Body).Decode(&urls); err != nil {
+				errs <- err
+				return
+			}
+			if len(urls) != requests {
+				errs <- fmt.Errorf("пользователь %s: получено %d URL вместо %d", user, len(urls), requests)
+				return
+			}
+			for _, u := range urls {
+				if !strings.HasPrefix(u.LongURL, fmt.Sprintf("https://example.com/%d/", i)) {
+					errs <- fmt.Errorf("пользователь %s получил чужой URL %s", user, u.LongURL)
+					return
+				}
+			}
+
+			errs <- nil
+		}(i, user, cookies)
+	}
+
+	for i := 0; i < users; i++ {
+		assert.NoError(t, <-errs)
+	}
+}
+
+func TestHandler_trustedSpoofedHeader(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	h := NewHandler(s, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{APIKeys: s}), "192.168.1.0/24", nil, nil, nil, nil, nil, nil)
+
+	shortURL, err := s.AddURL(context.Background(), "https://ya.ru/flag", "user1", storage.URLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		remoteAddr string
+		realIP     string
+		wantCode   int
+	}{
+		{"Подменённый заголовок X-Real-IP", http.MethodPost, "/api/internal/keys", `{"user_id":"victim"}`, "203.0.113.5:1234", "192.168.1.10", http.StatusForbidden},
+		{"Заголовок X-Real-IP без доверенных прокси", http.MethodPost, "/api/internal/keys", `{"user_id":"victim"}`, "192.168.2.1:1234", "192.168.1.10", http.StatusForbidden},
+		{"Запрос из доверенной подсети", http.MethodPost, "/api/internal/keys", `{"user_id":"victim"}`, "192.168.1.10:1234", "", http.StatusCreated},
+		{"Пометка с подменённым заголовком X-Real-IP", http.MethodPut, "/api/internal/urls/" + shortURL + "/flag", "", "203.0.113.5:1234", "192.168.1.10", http.StatusForbidden},
+		{"Снятие пометки с подменённым заголовком X-Real-IP", http.MethodDelete, "/api/internal/urls/" + shortURL + "/flag", "", "203.0.113.5:1234", "192.168.1.10", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			request.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			writer := httptest.NewRecorder()
+			h.ServeHTTP(writer, request)
+			result := writer.Result()
+			defer result.Body.Close()
+
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+		})
+	}
+
+	keys, err := s.ListAPIKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1, "ключ создаётся только по запросу из доверенной подсети")
+}
+
+func TestHandler_poolStats(t *testing.T) {
+	s := &poolStorage{dummyStorage{container: map[string]string{}, usersURLs: map[string][]string{}}}
+	h := NewHandler(s, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{}), "192.168.1.0/24", nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name       string
+		target     string
+		remoteAddr string
+		wantCode   int
+		wantBody   string
+	}{
+		{"Проверка доступности без статистики пула", "/ping", "203.0.113.5:1234", http.StatusOK, ""},
+		{"Статистика вне доверенной подсети", "/api/internal/stats", "203.0.113.5:1234", http.StatusForbidden, ""},
+		{"Статистика из доверенной подсети", "/api/internal/stats", "192.168.1.10:1234", http.StatusOK,
+			`{"urls":1,"users":1,"pool":{"total_conns":4,"idle_conns":3,"acquired_conns":1,"max_conns":10,` +
+				`"acquire_count":0,"empty_acquire_count":0,"canceled_acquire_count":0,"acquire_duration_ns":0}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			request.RemoteAddr = tt.remoteAddr
+
+			writer := httptest.NewRecorder()
+			h.ServeHTTP(writer, request)
+			result := writer.Result()
+			defer result.Body.Close()
+
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+
+			body, err := io.ReadAll(result.Body)
+			assert.NoError(t, err)
+			if tt.wantBody == "" {
+				assert.Empty(t, body)
+				return
+			}
+			assert.JSONEq(t, tt.wantBody, string(body))
+		})
+	}
+}
+
+func TestHandler_apiKeys(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	h := NewHandler(s, "http://localhost:8080/", auth.NewAuth(nil, auth.Options{APIKeys: s}), "192.168.1.0/24", testProxies(t), nil, nil, nil, nil, nil)
+
+	send := func(method, target, body string, header http.Header) *http.Response {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		for name, values := range header {
+			request.Header[name] = values
+		}
+		writer := httptest.NewRecorder()
+		h.ServeHTTP(writer, request)
+		return writer.Result()
+	}
+	trusted := http.Header{"X-Real-Ip": {"192.168.1.10"}}
+	bearer := func(key string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + key}}
+	}
+	createKey := func(body string) APIKeyMetadata {
+		result := send(http.MethodPost, "/api/internal/keys", body, trusted)
+		defer result.Body.Close()
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+
+		var key APIKeyMetadata
+		assert.NoError(t, json.NewDecoder(result.Body).Decode(&key))
+		assert.NotEmpty(t, key.Key)
+		return key
+	}
+	status := func(result *http.Response) int {
+		result.Body.Close()
+		return result.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, status(send(http.MethodPost, "/api/internal/keys", `{"user_id":"service"}`, nil)),
+		"ключи создаются только из доверенной подсети")
+	assert.Equal(t, http.StatusBadRequest, status(send(http.MethodPost, "/api/internal/keys", `{"user_id":"service","scope":"admin"}`, trusted)))
+	assert.Equal(t, http.StatusBadRequest, status(send(http.MethodPost, "/api/internal/keys", `{}`, trusted)))
+
+	full := createKey(`{"user_id":"service","name":"jobs"}`)
+	readOnly := createKey(`{"user_id":"service","scope":"read"}`)
+
+	batch := `[{"correlation_id":"1","original_url":"https://ya.ru/batch"}]`
+	assert.Equal(t, http.StatusCreated, status(send(http.MethodPost, "/api/shorten/batch", batch, bearer(full.Key))))
+	assert.Equal(t, http.StatusForbidden, status(send(http.MethodPost, "/api/shorten/batch", batch, bearer(readOnly.Key))),
+		"ключ только для чтения не позволяет создавать короткие URL")
+
+	result := send(http.MethodGet, "/api/user/urls", "", bearer(readOnly.Key))
+	var urls shortAndLongURLs
+	assert.NoError(t, json.NewDecoder(result.Body).Decode(&urls))
+	result.Body.Close()
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Len(t, urls, 1, "ключи одного пользователя видят его короткие URL")
+
+	createOnly := createKey(`{"user_id":"service","scope":"create"}`)
+	if len(urls) == 1 {
+		id := strings.TrimPrefix(urls[0].ShortURL, "http://localhost:8080/")
+		patch := `{"original_url":"https://evil.example/"}`
+		assert.Equal(t, http.StatusForbidden, status(send(http.MethodPatch, "/api/user/urls/"+id, patch, bearer(createOnly.Key))),
+			"ключ только для создания не позволяет менять существующие короткие URL")
+		assert.Equal(t, http.StatusOK, status(send(http.MethodPatch, "/api/user/urls/"+id, `{"original_url":"https://ya.ru/patched"}`, bearer(full.Key))))
+	}
+
+	result = send(http.MethodGet, "/api/internal/keys", "", trusted)
+	var list []APIKeyMetadata
+	assert.NoError(t, json.NewDecoder(result.Body).Decode(&list))
+	result.Body.Close()
+	if assert.Len(t, list, 3) {
+		for _, key := range list {
+			assert.Empty(t, key.Key, "ключи не возвращаются в списке")
+		}
+	}
+
+	assert.Equal(t, http.StatusNoContent, status(send(http.MethodDelete, "/api/internal/keys/"+full.ID, "", trusted)))
+	assert.Equal(t, http.StatusNotFound, status(send(http.MethodDelete, "/api/internal/keys/unknown", "", trusted)))
+	assert.Equal(t, http.StatusUnauthorized, status(send(http.MethodPost, "/api/shorten/batch", batch, bearer(full.Key))),
+		"отозванный ключ не принимается")
+}
+
+func TestHandler_login(t *testing.T) {
+	provider, err := oidctest.NewProvider("shurl", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer provider.Close()
+	provider.SetUser("alice", "alice@example.com")
+
+	s := storage.NewMemoryStorage()
+	a := auth.NewAuth(nil, auth.Options{})
+	client := oidc.NewClient(oidc.Config{
+		Issuer:       provider.Issuer(),
+		ClientID:     "shurl",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/callback",
+	})
+	h := NewHandler(s, "http://localhost:8080/", a, "", nil, nil, nil, nil, nil, client)
+
+	send := func(method, target, body string, cookies []*http.Cookie) *http.Response {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+		writer := httptest.NewRecorder()
+		h.ServeHTTP(writer, request)
+		result := writer.Result()
+		result.Body.Close()
+		return result
+	}
+	cookie := func(result *http.Response, name string) *http.Cookie {
+		var found *http.Cookie
+		for _, c := range result.Cookies() {
+			if c.Name == name {
+				found = c
+			}
+		}
+		return found
+	}
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	// login выполняет вход пользователя с заданными cookie и возвращает ответ сервиса на возврат от провайдера.
+	login := func(returnTo string, cookies []*http.Cookie) *http.Response {
+		result := send(http.MethodGet, "/auth/login?return_to="+url.QueryEscape(returnTo), "", cookies)
+		assert.Equal(t, http.StatusFound, result.StatusCode)
+		loginCookie := cookie(result, "login")
+		if loginCookie == nil {
+			t.Fatal("не получены cookie входа")
+		}
+		assert.True(t, loginCookie.HttpOnly)
+
+		response, err := noRedirect.Get(result.Header.Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		callback, err := url.Parse(response.Header.Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "/auth/callback", callback.Path)
+
+		return send(http.MethodGet, callback.RequestURI(), "", append(cookies, loginCookie))
+	}
+	userURLs := func(cookies []*http.Cookie) int {
+		request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+		for _, c := range cookies {
+			request.AddCookie(c)
+		}
+		writer := httptest.NewRecorder()
+		h.ServeHTTP(writer, request)
+		result := writer.Result()
+		defer result.Body.Close()
+
+		var urls shortAndLongURLs
+		_ = json.NewDecoder(result.Body).Decode(&urls)
+		return len(urls)
+	}
+
+	_, cookies := newTestUser(t, a)
+	assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/", "https://ya.ru/before-login", cookies).StatusCode)
+
+	result := login("/api/user/urls?page=1", cookies)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.Equal(t, "/api/user/urls?page=1", result.Header.Get("Location"))
+	named := cookie(result, "authentication")
+	if named == nil {
+		t.Fatal("не получены cookie пользователя, выполнившего вход")
+	}
+	assert.Equal(t, 1, userURLs([]*http.Cookie{named}), "короткие URL анонимного пользователя переносятся при входе")
+	assert.Equal(t, 0, userURLs(cookies), "у анонимного пользователя не остаётся коротких URL")
+
+	assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/", "https://ya.ru/other-device", cookies).StatusCode)
+	result = login("//evil.example", cookies)
+	assert.Equal(t, defaultReturnTo, result.Header.Get("Location"), "перенаправление на посторонний сайт не допускается")
+	again := cookie(result, "authentication")
+	if assert.NotNil(t, again) {
+		assert.Equal(t, 2, userURLs([]*http.Cookie{again}), "при повторном входе пользователь получает тот же идентификатор")
+	}
+
+	result = login("/", []*http.Cookie{named})
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.Equal(t, 2, userURLs([]*http.Cookie{cookie(result, "authentication")}))
+
+	provider.SetUser("bob", "")
+	result = login("/", []*http.Cookie{named})
+	assert.Equal(t, 0, userURLs([]*http.Cookie{cookie(result, "authentication")}), "URL пользователя, выполнившего вход, не переносятся другому")
+
+	result = send(http.MethodGet, "/auth/callback?code=code&state=forged", "", cookies)
+	assert.Equal(t, http.StatusBadRequest, result.StatusCode, "возврат без запроса на вход не принимается")
+
+	result = send(http.MethodGet, "/auth/logout", "", []*http.Cookie{named})
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.True(t, strings.HasPrefix(result.Header.Get("Location"), provider.Issuer()+"/logout"))
+	if deleted := cookie(result, "authentication"); assert.NotNil(t, deleted) {
+		assert.Negative(t, deleted.MaxAge)
+	}
+
+	withoutLogin := NewHandler(s, "http://localhost:8080/", a, "", nil, nil, nil, nil, nil, nil)
+	writer := httptest.NewRecorder()
+	withoutLogin.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	assert.Equal(t, http.StatusNotFound, writer.Code, "без провайдера вход недоступен")
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/oidc"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+const (
+	cookieLogin     = "login"          // Cookie с параметрами незавершённого входа через провайдера OpenID Connect
+	loginCookiePath = "/auth/"         // Путь, для которого передаётся cookie входа
+	loginCookieAge  = 10 * 60          // Время на вход у провайдера в секундах
+	defaultReturnTo = "/api/user/urls" // Адрес, на который пользователь перенаправляется после входа по умолчанию
+)
+
+// loginState содержит параметры запроса на вход, которые сверяются с ответом провайдера.
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	ReturnTo string `json:"return_to"`
+}
+
+// signIn перенаправляет пользователя на страницу входа провайдера OpenID Connect.
+// Параметры запроса на вход сохраняются в cookie и проверяются при возврате пользователя от провайдера.
+// Адрес, на который пользователь вернётся после входа, передаётся в параметре return_to.
+func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
+	log.Println("Обработка запроса на вход через провайдера OpenID Connect")
+
+	state := loginState{ReturnTo: localPath(r.URL.Query().Get("return_to"))}
+	for _, value := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		random, err := oidc.RandomString()
+		if err != nil {
+			log.Println("Ошибка при создании параметров входа:", err)
+			http.Error(w, "ошибка при создании параметров входа: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		*value = random
+	}
+
+	authURL, err := h.provider.AuthCodeURL(r.Context(), state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		log.Println("Ошибка при обращении к провайдеру OpenID Connect:", err)
+		http.Error(w, "ошибка при обращении к провайдеру OpenID Connect", http.StatusBadGateway)
+		return
+	}
+
+	value, err := json.Marshal(state)
+	if err != nil {
+		log.Println("Ошибка при сохранении параметров входа:", err)
+		http.Error(w, "ошибка при сохранении параметров входа: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	setLoginCookie(w, r, base64.RawURLEncoding.EncodeToString(value), loginCookieAge)
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// callback завершает вход пользователя, вернувшегося от провайдера OpenID Connect с кодом авторизации.
+// Короткие URL анонимного пользователя, выполнявшего вход, переносятся пользователю, выполнившему вход,
+// после чего ему выдаётся новый токен и он перенаправляется на адрес, заданный при входе.
+func (h *Handler) callback(w http.ResponseWriter, r *http.Request) {
+	log.Println("Обработка возврата пользователя от провайдера OpenID Connect")
+
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		log.Println("Провайдер OpenID Connect отказал во входе:", reason, query.Get("error_description"))
+		http.Error(w, "провайдер отказал во входе: "+reason, http.StatusUnauthorized)
+		return
+	}
+
+	state, err := readLoginCookie(r)
+	if err != nil || state.State == "" || subtle.ConstantTimeCompare([]byte(state.State), []byte(query.Get("state"))) != 1 {
+		log.Println("Параметры возврата от провайдера не совпадают с запросом на вход:", err)
+		http.Error(w, "параметры возврата от провайдера не совпадают с запросом на вход", http.StatusBadRequest)
+		return
+	}
+	setLoginCookie(w, r, "", -1)
+
+	identity, err := h.provider.Exchange(r.Context(), query.Get("code"), state.Verifier, state.Nonce)
+	if errors.Is(err, oidc.ErrInvalidToken) {
+		log.Println("Ошибка при проверке ID-токена:", err)
+		http.Error(w, "ошибка при проверке ID-токена: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка при обращении к провайдеру OpenID Connect:", err)
+		http.Error(w, "ошибка при обращении к провайдеру OpenID Connect", http.StatusBadGateway)
+		return
+	}
+
+	id := identity.UserID()
+	log.Println("Пользователь", identity.Subject, "провайдера", identity.Issuer, "соответствует пользователю", id)
+
+	current := auth.UserFromContext(r.Context())
+	merger, ok := h.storage.(storage.UserMerger)
+	if ok && current.Token != "" && current.Provider == "" && current.ID != id {
+		merged, err := merger.MergeUser(r.Context(), current.ID, id)
+		if storageTimeout(w, err) {
+			return
+		}
+		if err != nil {
+			log.Println("Ошибка '", err, "' при переносе коротких URL пользователя", current.ID)
+			http.Error(w, "ошибка при переносе коротких URL пользователя: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Println("Пользователю", id, "перенесено коротких URL пользователя", current.ID+":", merged)
+	}
+
+	_, err = h.auth.SignIn(w, id, identity.Issuer)
+	if err != nil {
+		log.Println("Ошибка при выпуске токена пользователя:", err)
+		http.Error(w, "ошибка при выпуске токена пользователя: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, state.ReturnTo, http.StatusFound)
+}
+
+// signOut удаляет cookie с токеном пользователя и перенаправляет его на страницу завершения сеанса провайдера,
+// если провайдер её поддерживает.
+func (h *Handler) signOut(w http.ResponseWriter, r *http.Request) {
+	log.Println("Обработка запроса на выход пользователя")
+
+	h.auth.SignOut(w)
+
+	logoutURL, err := h.provider.LogoutURL(r.Context())
+	if err != nil {
+		log.Println("Ошибка при обращении к провайдеру OpenID Connect:", err)
+	}
+	if logoutURL == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	http.Redirect(w, r, logoutURL, http.StatusFound)
+}
+
+// setLoginCookie сохраняет параметры входа в cookie с заданным сроком хранения в секундах.
+// Отрицательный срок хранения означает удаление cookie.
+func setLoginCookie(w http.ResponseWriter, r *http.Request, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieLogin,
+		Value:    value,
+		Path:     loginCookiePath,
+		MaxAge:   maxAge,
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// readLoginCookie возвращает параметры входа, сохранённые в cookie.
+func readLoginCookie(r *http.Request) (loginState, error) {
+	var state loginState
+
+	cookie, err := r.Cookie(cookieLogin)
+	if err != nil {
+		return state, err
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return state, err
+	}
+
+	err = json.Unmarshal(value, &state)
+	return state, err
+}
+
+// localPath возвращает переданный путь, если он указывает на страницу сервиса, иначе путь по умолчанию.
+// Это не позволяет использовать вход для перенаправления пользователя на посторонний сайт.
+func localPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return defaultReturnTo
+	}
+
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return defaultReturnTo
+	}
+
+	return path
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// Типы данных для управления пользователем своими короткими URL.
+type (
+	// URLMetadata содержит поля для формирования тела ответа в формате JSON со сведениями о коротком URL.
+	URLMetadata struct {
+		ShortURL  string     `json:"short_url"`
+		LongURL   string     `json:"original_url"`
+		Deleted   bool       `json:"deleted"`
+		DeletedAt *time.Time `json:"deleted_at,omitempty"` // Момент удаления короткого URL
+		ExpiresAt *time.Time `json:"expires_at,omitempty"` // Момент окончания срока действия короткого URL
+		Protected bool       `json:"protected"`            // Признак защиты короткого URL паролем
+		Flagged   bool       `json:"flagged"`              // Признак того, что короткий URL ведёт на подозрительную страницу
+	}
+
+	// PatchRequestBody содержит поля для обработки тела запроса на изменение исходного URL в формате JSON.
+	PatchRequestBody struct {
+		URL string `json:"original_url"`
+	}
+)
+
+// newURLMetadata формирует сведения о коротком URL по записи хранилища.
+func newURLMetadata(sh string, mr storage.MemoryRecord) URLMetadata {
+	return URLMetadata{
+		ShortURL:  baseURL + sh,
+		LongURL:   mr.LongURL,
+		Deleted:   mr.Deleted,
+		DeletedAt: timeOrNil(mr.DeletedAt),
+		ExpiresAt: timeOrNil(mr.ExpiresAt),
+		Protected: mr.Protected(),
+		Flagged:   mr.Flagged,
+	}
+}
+
+// getURL отвечает сведениями о коротком URL, принадлежащем пользователю, в формате JSON.
+func (h *Handler) getURL(w http.ResponseWriter, r *http.Request) {
+	log.Println("Полученный GET-запрос:", r.URL)
+
+	shortURL := chi.URLParam(r, "id")
+	h.writeURLMetadata(w, r, shortURL, http.StatusOK)
+}
+
+// patchURL заменяет исходный URL короткого URL, принадлежащего пользователю.
+// Новый исходный URL проходит те же проверки, что и при сокращении.
+func (h *Handler) patchURL(w http.ResponseWriter, r *http.Request) {
+	shortURL := chi.URLParam(r, "id")
+	log.Println("Изменение исходного URL для короткого идентификатора", shortURL)
+
+	b, err := decodeRequest(r)
+	if err != nil {
+		log.Println("Неверный формат данных в запросе:", err)
+		http.Error(w, "неверный формат данных в запросе: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	requestBody := PatchRequestBody{}
+	err = json.Unmarshal(b, &requestBody)
+	if err != nil {
+		log.Println("Неверный формат данных в запросе:", err)
+		http.Error(w, "неверный формат данных в запросе: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	longURL, ok := h.checkURL(w, r, requestBody.URL, "")
+	if !ok {
+		return
+	}
+
+	err = h.storage.UpdateURL(r.Context(), shortURL, longURL, auth.UserFromContext(r.Context()).ID)
+	if manageError(w, shortURL, err) {
+		return
+	}
+
+	h.writeURLMetadata(w, r, shortURL, http.StatusOK)
+}
+
+// restoreURL отменяет удаление короткого URL, принадлежащего пользователю, если срок восстановления не истёк.
+func (h *Handler) restoreURL(w http.ResponseWriter, r *http.Request) {
+	shortURL := chi.URLParam(r, "id")
+	log.Println("Восстановление удалённого короткого идентификатора", shortURL)
+
+	err := h.storage.RestoreURL(r.Context(), shortURL, auth.UserFromContext(r.Context()).ID)
+	if manageError(w, shortURL, err) {
+		return
+	}
+
+	h.writeURLMetadata(w, r, shortURL, http.StatusOK)
+}
+
+// writeURLMetadata отвечает заданным кодом и сведениями о коротком URL, если он принадлежит пользователю.
+func (h *Handler) writeURLMetadata(w http.ResponseWriter, r *http.Request, shortURL string, code int) {
+	result, err := h.storage.FindURL(r.Context(), shortURL)
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "'. Не найден URL с указанным коротким идентификатором:", shortURL)
+		http.Error(w, "URL с указанным коротким идентификатором не найден", http.StatusNotFound)
+		return
+	}
+
+	user := auth.UserFromContext(r.Context())
+	if result.User != user.ID {
+		log.Println("Короткий идентификатор", shortURL, "не принадлежит пользователю", user.ID)
+		http.Error(w, "сведения доступны только пользователю, создавшему короткий URL", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	err = enc.Encode(newURLMetadata(shortURL, result))
+	if err != nil {
+		log.Println("Не удалось закодировать в JSON сведения о коротком URL:", err)
+	}
+}
+
+// manageError отвечает кодом, соответствующим ошибке изменения или восстановления короткого URL.
+// Возвращает признак того, что ответ уже отправлен.
+func manageError(w http.ResponseWriter, shortURL string, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if storageTimeout(w, err) {
+		return true
+	}
+
+	log.Println("Ошибка '", err, "' при изменении короткого идентификатора:", shortURL)
+
+	switch {
+	case errors.Is(err, storage.ErrURLNotFound):
+		http.Error(w, "URL с указанным коротким идентификатором не найден", http.StatusNotFound)
+	case errors.Is(err, storage.ErrNotOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, storage.ErrURLDeleted), errors.Is(err, storage.ErrURLExpired), errors.Is(err, storage.ErrRestorePeriodExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, storage.ErrURLNotDeleted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, storage.DBErrorDublicate):
+		http.Error(w, "исходный URL уже сокращён", http.StatusConflict)
+	default:
+		http.Error(w, "ошибка при изменении короткого URL: "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return true
+}
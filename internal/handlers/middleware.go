@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/StainlessSteelSnake/shurl/internal/auth"
 )
 
 type gzipWriter struct {
@@ -61,3 +63,19 @@ func decodeRequest(r *http.Request) ([]byte, error) {
 
 	return io.ReadAll(reader)
 }
+
+// requireScope пропускает запрос, только если пользователю разрешены действия из заданной области,
+// например если запрос выполнен по ключу API с такой областью действия.
+func requireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !auth.UserFromContext(r.Context()).Allowed(scope) {
+				log.Println("Пользователю не разрешены действия из области", scope)
+				http.Error(w, "действие не разрешено для области действия ключа API", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/StainlessSteelSnake/shurl/internal/storage"
+)
+
+// passwordFormTemplate содержит HTML-форму для ввода пароля к защищённому короткому URL.
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Ссылка защищена паролем</title>
+</head>
+<body>
+<h1>Ссылка защищена паролем</h1>
+{{if .Error}}<p style="color: red">{{.Error}}</p>{{end}}
+<form method="post" action="/{{.ShortURL}}">
+<input type="password" name="password" autofocus required>
+<button type="submit">Перейти</button>
+</form>
+</body>
+</html>
+`))
+
+type passwordForm struct {
+	ShortURL string
+	Error    string
+}
+
+// renderPasswordForm выводит форму для ввода пароля к защищённому короткому URL с заданным кодом ответа.
+func renderPasswordForm(w http.ResponseWriter, shortURL string, formError string, code int) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+
+	err := passwordFormTemplate.Execute(w, passwordForm{ShortURL: shortURL, Error: formError})
+	if err != nil {
+		log.Println("Ошибка при формировании формы ввода пароля:", err)
+	}
+}
+
+// postPassword проверяет пароль, введённый в форму для защищённого короткого URL,
+// и при совпадении перенаправляет на исходный длинный URL.
+// Для перенаправления используется код 303, чтобы браузер не отправлял форму с паролем по исходному URL.
+func (h *Handler) postPassword(w http.ResponseWriter, r *http.Request) {
+	shortURL := chi.URLParam(r, "id")
+	log.Println("Проверка пароля для короткого идентификатора:", shortURL)
+
+	result, err := h.storage.FindURL(r.Context(), shortURL)
+	if storageTimeout(w, err) {
+		return
+	}
+	if err != nil {
+		log.Println("Ошибка '", err, "'. Не найден URL с указанным коротким идентификатором:", shortURL)
+		http.Error(w, "URL с указанным коротким идентификатором не найден", http.StatusBadRequest)
+		return
+	}
+
+	if result.Deleted || result.Expired(time.Now()) {
+		log.Println("URL", result.LongURL, "для короткого идентификатора", shortURL, "недоступен")
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+
+	clientIP := h.proxies.ClientIP(r).String()
+	if h.limiter != nil {
+		allowed, retryAfter := h.limiter.Allow(shortURL, clientIP, time.Now())
+		if !allowed {
+			log.Println("Превышено количество попыток ввода пароля для короткого идентификатора", shortURL)
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			http.Error(w, "слишком много попыток ввода пароля, повторите позже", http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		log.Println("Неверный формат данных в запросе:", err)
+		http.Error(w, "неверный формат данных в запросе: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !result.CheckPassword(r.PostFormValue("password")) {
+		log.Println("Введён неверный пароль для короткого идентификатора", shortURL)
+		renderPasswordForm(w, shortURL, "Неверный пароль", http.StatusUnauthorized)
+		return
+	}
+	if h.limiter != nil {
+		h.limiter.Release(shortURL, clientIP)
+	}
+
+	h.redirect(w, r, shortURL, result, http.StatusSeeOther)
+}
+
+// redirect регистрирует переход по короткому URL и перенаправляет клиента на исходный длинный URL.
+// Для коротких URL, помеченных как ведущие на подозрительную страницу, вместо перенаправления выводится предупреждение.
+func (h *Handler) redirect(w http.ResponseWriter, r *http.Request, shortURL string, result storage.MemoryRecord, code int) {
+	log.Println("Найден URL", result.LongURL, "для короткого идентификатора", shortURL)
+	if result.Flagged {
+		log.Println("Короткий идентификатор", shortURL, "помечен как ведущий на подозрительную страницу")
+		renderWarning(w, result.LongURL)
+		return
+	}
+
+	if h.recorder != nil {
+		h.recorder.Record(h.recorder.NewClick(r, shortURL, time.Now()))
+	}
+
+	w.Header().Set("Location", result.LongURL)
+	w.WriteHeader(code)
+}
@@ -0,0 +1,456 @@
+// Пакет oidc реализует вход пользователей через внешнего провайдера OpenID Connect
+// по схеме authorization code с PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration" // Путь к описанию провайдера относительно его идентификатора
+	userIDLength  = 16                                  // Длина идентификатора пользователя сервиса в байтах
+	randomLength  = 32                                  // Длина случайных значений state, nonce и code_verifier в байтах
+	clockSkew     = time.Minute                         // Допустимое расхождение часов сервиса и провайдера
+	maxBodySize   = 1 << 20                             // Максимальный размер ответа провайдера
+)
+
+// Ошибки входа через провайдера OpenID Connect.
+var (
+	ErrProvider     = errors.New("ошибка при обращении к провайдеру OpenID Connect")
+	ErrInvalidToken = errors.New("неверный ID-токен")
+)
+
+// Типы данных для входа через провайдера OpenID Connect.
+type (
+	// Config содержит параметры клиента, зарегистрированного у провайдера.
+	Config struct {
+		Issuer       string       // Идентификатор провайдера, по которому запрашивается его описание
+		ClientID     string       // Идентификатор клиента
+		ClientSecret string       // Секрет клиента
+		RedirectURL  string       // Адрес, на который провайдер перенаправляет пользователя после входа
+		Scopes       []string     // Запрашиваемые области доступа, openid добавляется всегда
+		HTTPClient   *http.Client // Клиент для запросов к провайдеру, по умолчанию http.DefaultClient
+	}
+
+	// Identity содержит данные пользователя, подтверждённые провайдером.
+	Identity struct {
+		Issuer  string // Провайдер, подтвердивший личность пользователя
+		Subject string // Идентификатор пользователя у провайдера
+		Email   string // Адрес электронной почты, если провайдер его передал
+		Name    string // Имя пользователя, если провайдер его передал
+	}
+
+	// Client выполняет вход пользователей через провайдера. Описание провайдера и его ключи подписи
+	// запрашиваются при первом обращении и кешируются, ключи перезапрашиваются, если токен подписан неизвестным ключом.
+	Client struct {
+		config   Config
+		locker   sync.Mutex
+		provider *providerMetadata
+		keys     map[string]*rsa.PublicKey
+	}
+
+	// providerMetadata содержит используемые поля описания провайдера.
+	providerMetadata struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+		EndSessionEndpoint    string `json:"end_session_endpoint,omitempty"`
+	}
+
+	// tokenResponse содержит используемые поля ответа провайдера на обмен кода авторизации.
+	tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error,omitempty"`
+		ErrorDescription string `json:"error_description,omitempty"`
+	}
+
+	// jsonWebKey содержит открытый ключ RSA провайдера.
+	jsonWebKey struct {
+		KeyType string `json:"kty"`
+		KeyID   string `json:"kid"`
+		N       string `json:"n"`
+		E       string `json:"e"`
+	}
+
+	// tokenHeader содержит заголовок ID-токена.
+	tokenHeader struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+
+	// tokenClaims содержит проверяемые данные ID-токена.
+	tokenClaims struct {
+		Issuer    string   `json:"iss"`
+		Subject   string   `json:"sub"`
+		Audience  audience `json:"aud"`
+		ExpiresAt int64    `json:"exp"`
+		IssuedAt  int64    `json:"iat"`
+		Nonce     string   `json:"nonce"`
+		Email     string   `json:"email"`
+		Name      string   `json:"name"`
+	}
+
+	// audience содержит получателей ID-токена, которые передаются строкой или массивом строк.
+	audience []string
+)
+
+// NewClient создаёт клиент для входа через провайдера с заданными параметрами.
+func NewClient(config Config) *Client {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	return &Client{config: config}
+}
+
+// RandomString возвращает случайную строку для параметров state, nonce и code_verifier.
+func RandomString() (string, error) {
+	b := make([]byte, randomLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// UserID возвращает идентификатор пользователя сервиса, соответствующий пользователю провайдера.
+// Идентификатор вычисляется по провайдеру и идентификатору пользователя у него, поэтому не меняется
+// между входами и не требует хранения соответствия.
+func (i Identity) UserID() string {
+	sum := sha256.Sum256([]byte(i.Issuer + "\x00" + i.Subject))
+	return hex.EncodeToString(sum[:userIDLength])
+}
+
+// AuthCodeURL возвращает адрес страницы входа провайдера. Параметры state и nonce связывают ответ провайдера
+// с запросом на вход, а verifier передаётся провайдеру в виде хеша по схеме PKCE.
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	provider, err := c.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.config.ClientID},
+		"redirect_uri":          {c.config.RedirectURL},
+		"scope":                 {strings.Join(c.scopes(), " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	return withQuery(provider.AuthorizationEndpoint, query), nil
+}
+
+// Exchange обменивает код авторизации на ID-токен, проверяет его и возвращает данные пользователя.
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	provider, err := c.metadata(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.config.RedirectURL},
+		"client_id":     {c.config.ClientID},
+		"code_verifier": {verifier},
+	}
+	if c.config.ClientSecret != "" {
+		form.Set("client_secret", c.config.ClientSecret)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var response tokenResponse
+	status, err := c.do(request, &response)
+	if err != nil {
+		return Identity{}, err
+	}
+	if status != http.StatusOK || response.Error != "" {
+		return Identity{}, fmt.Errorf("%w: код авторизации не принят: %d %s %s", ErrProvider, status, response.Error, response.ErrorDescription)
+	}
+
+	return c.verify(ctx, response.IDToken, nonce, time.Now())
+}
+
+// LogoutURL возвращает адрес для завершения сеанса у провайдера или пустую строку, если провайдер его не поддерживает.
+func (c *Client) LogoutURL(ctx context.Context) (string, error) {
+	provider, err := c.metadata(ctx)
+	if err != nil || provider.EndSessionEndpoint == "" {
+		return "", err
+	}
+
+	return withQuery(provider.EndSessionEndpoint, url.Values{"client_id": {c.config.ClientID}}), nil
+}
+
+// scopes возвращает запрашиваемые области доступа, включая обязательную openid.
+func (c *Client) scopes() []string {
+	scopes := []string{"openid"}
+	for _, scope := range c.config.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// metadata возвращает описание провайдера, запрашивая его при первом обращении.
+func (c *Client) metadata(ctx context.Context) (*providerMetadata, error) {
+	c.locker.Lock()
+	provider := c.provider
+	c.locker.Unlock()
+	if provider != nil {
+		return provider, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.Issuer+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	provider = &providerMetadata{}
+	status, err := c.do(request, provider)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: описание провайдера недоступно: %d", ErrProvider, status)
+	}
+	if provider.Issuer != c.config.Issuer {
+		return nil, fmt.Errorf("%w: провайдер %s описан как %s", ErrProvider, c.config.Issuer, provider.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: в описании провайдера нет обязательных адресов", ErrProvider)
+	}
+
+	c.locker.Lock()
+	c.provider = provider
+	c.locker.Unlock()
+
+	return provider, nil
+}
+
+// key возвращает открытый ключ провайдера с заданным идентификатором.
+// Если ключ неизвестен, ключи провайдера запрашиваются повторно, например после их смены.
+func (c *Client) key(ctx context.Context, provider *providerMetadata, id string) (*rsa.PublicKey, error) {
+	c.locker.Lock()
+	key, ok := c.keys[id]
+	c.locker.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	status, err := c.do(request, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%w: ключи провайдера недоступны: %d", ErrProvider, status)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.KeyType != "RSA" {
+			continue
+		}
+
+		public, err := k.publicKey()
+		if err != nil {
+			return nil, err
+		}
+		keys[k.KeyID] = public
+	}
+
+	c.locker.Lock()
+	c.keys = keys
+	c.locker.Unlock()
+
+	key, ok = keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: подписан неизвестным ключом %s", ErrInvalidToken, id)
+	}
+
+	return key, nil
+}
+
+// verify проверяет подпись, провайдера, получателя, срок действия и nonce ID-токена и возвращает данные пользователя.
+func (c *Client) verify(ctx context.Context, token, nonce string, now time.Time) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, fmt.Errorf("%w: неправильный формат", ErrInvalidToken)
+	}
+
+	var header tokenHeader
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return Identity{}, err
+	}
+	if header.Algorithm != "RS256" {
+		return Identity{}, fmt.Errorf("%w: неподдерживаемый алгоритм подписи %s", ErrInvalidToken, header.Algorithm)
+	}
+
+	provider, err := c.metadata(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	key, err := c.key(ctx, provider, header.KeyID)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	sign, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: неправильный формат подписи", ErrInvalidToken)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sign)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: неправильная подпись", ErrInvalidToken)
+	}
+
+	var claims tokenClaims
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	switch {
+	case claims.Issuer != provider.Issuer:
+		return Identity{}, fmt.Errorf("%w: выпущен другим провайдером %s", ErrInvalidToken, claims.Issuer)
+	case !claims.Audience.contains(c.config.ClientID):
+		return Identity{}, fmt.Errorf("%w: выпущен для другого клиента", ErrInvalidToken)
+	case claims.Subject == "":
+		return Identity{}, fmt.Errorf("%w: не указан пользователь", ErrInvalidToken)
+	case !now.Before(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)):
+		return Identity{}, fmt.Errorf("%w: истёк срок действия", ErrInvalidToken)
+	case claims.Nonce != nonce:
+		return Identity{}, fmt.Errorf("%w: nonce не совпадает с запросом на вход", ErrInvalidToken)
+	}
+
+	return Identity{Issuer: claims.Issuer, Subject: claims.Subject, Email: claims.Email, Name: claims.Name}, nil
+}
+
+// do выполняет запрос к провайдеру и разбирает ответ в формате JSON.
+func (c *Client) do(request *http.Request, v interface{}) (int, error) {
+	response, err := c.config.HTTPClient.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxBodySize))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, v); err != nil && response.StatusCode == http.StatusOK {
+			return 0, fmt.Errorf("%w: %v", ErrProvider, err)
+		}
+	}
+
+	return response.StatusCode, nil
+}
+
+// publicKey преобразует ключ в формате JWK в открытый ключ RSA.
+func (k jsonWebKey) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("%w: неправильный ключ провайдера %s", ErrProvider, k.KeyID)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("%w: неправильный ключ провайдера %s", ErrProvider, k.KeyID)
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+// UnmarshalJSON разбирает получателей ID-токена, переданных строкой или массивом строк.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	err := json.Unmarshal(data, &multiple)
+	*a = multiple
+	return err
+}
+
+// contains проверяет, указан ли клиент среди получателей ID-токена.
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeSegment разбирает часть ID-токена в формате base64url(JSON).
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: неправильный формат", ErrInvalidToken)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("%w: неправильный формат", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+// withQuery добавляет параметры запроса к адресу, сохраняя уже указанные в нём параметры.
+func withQuery(endpoint string, query url.Values) string {
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+
+	return endpoint + separator + query.Encode()
+}
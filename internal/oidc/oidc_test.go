@@ -0,0 +1,171 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/StainlessSteelSnake/shurl/internal/oidc/oidctest"
+)
+
+// login выполняет вход через провайдера и возвращает код авторизации и state из адреса возврата.
+func login(t *testing.T, authURL string) (string, string) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	response, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestClient_Exchange(t *testing.T) {
+	ctx := context.Background()
+	provider, err := oidctest.NewProvider("shurl", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer provider.Close()
+	provider.SetUser("alice", "alice@example.com")
+
+	client := NewClient(Config{
+		Issuer:       provider.Issuer() + "/",
+		ClientID:     "shurl",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/callback",
+		Scopes:       []string{"openid", "email"},
+	})
+
+	authURL, err := client.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	assert.NoError(t, err)
+	assert.Contains(t, authURL, "scope=openid+email")
+	assert.NotContains(t, authURL, "verifier", "code_verifier передаётся провайдеру только в виде хеша")
+
+	code, state := login(t, authURL)
+	assert.Equal(t, "state", state)
+
+	tests := []struct {
+		name     string
+		code     string
+		verifier string
+		nonce    string
+		want     Identity
+		wantErr  error
+	}{
+		{"Неверный code_verifier", code, "other", "nonce", Identity{}, ErrProvider},
+		{"Неизвестный код", "unknown", "verifier", "nonce", Identity{}, ErrProvider},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Exchange(ctx, tt.code, tt.verifier, tt.nonce)
+			assert.True(t, errors.Is(err, tt.wantErr), err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	code, _ = login(t, authURL)
+	identity, err := client.Exchange(ctx, code, "verifier", "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{Issuer: provider.Issuer(), Subject: "alice", Email: "alice@example.com"}, identity)
+
+	_, err = client.Exchange(ctx, code, "verifier", "nonce")
+	assert.True(t, errors.Is(err, ErrProvider), "код авторизации принимается только один раз")
+
+	logoutURL, err := client.LogoutURL(ctx)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(logoutURL, provider.Issuer()+"/logout?"))
+}
+
+func TestClient_verify(t *testing.T) {
+	ctx := context.Background()
+	provider, err := oidctest.NewProvider("shurl", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer provider.Close()
+
+	client := NewClient(Config{Issuer: provider.Issuer(), ClientID: "shurl"})
+	now := time.Now()
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":   provider.Issuer(),
+			"sub":   "alice",
+			"aud":   []string{"other", "shurl"},
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+			"nonce": "nonce",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		value   interface{}
+		nonce   string
+		wantErr bool
+	}{
+		{"Действительный токен", "", nil, "nonce", false},
+		{"Другой nonce", "", nil, "other", true},
+		{"Другой получатель", "aud", "other", "nonce", true},
+		{"Другой провайдер", "iss", "https://evil.example", "nonce", true},
+		{"Истёк срок действия", "exp", now.Add(-time.Hour).Unix(), "nonce", true},
+		{"Не указан пользователь", "sub", "", "nonce", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			if tt.key != "" {
+				claims[tt.key] = tt.value
+			}
+
+			token, err := provider.IDToken(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identity, err := client.verify(ctx, token, tt.nonce, now)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidToken), err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "alice", identity.Subject)
+		})
+	}
+
+	token, err := provider.IDToken(valid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	_, err = client.verify(ctx, parts[0]+"."+parts[1]+"."+parts[1], "nonce", now)
+	assert.True(t, errors.Is(err, ErrInvalidToken), "токен с неверной подписью не принимается")
+
+	_, err = client.verify(ctx, "eyJhbGciOiJub25lIn0."+parts[1]+".", "nonce", now)
+	assert.True(t, errors.Is(err, ErrInvalidToken), "токен без подписи не принимается")
+}
+
+func TestIdentity_UserID(t *testing.T) {
+	alice := Identity{Issuer: "https://idp.example", Subject: "alice", Email: "alice@example.com"}
+
+	assert.Equal(t, alice.UserID(), Identity{Issuer: "https://idp.example", Subject: "alice"}.UserID(), "идентификатор не зависит от изменяемых данных пользователя")
+	assert.NotEqual(t, alice.UserID(), Identity{Issuer: "https://other.example", Subject: "alice"}.UserID())
+	assert.NotEqual(t, alice.UserID(), Identity{Issuer: "https://idp.example", Subject: "bob"}.UserID())
+	assert.Len(t, alice.UserID(), 2*userIDLength)
+}
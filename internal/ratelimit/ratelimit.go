@@ -0,0 +1,132 @@
+// Пакет ratelimit ограничивает частоту попыток выполнения операций по заданному ключу.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Настройки ограничения частоты попыток по умолчанию.
+const (
+	// DefaultAttempts задаёт количество попыток одного клиента, разрешённых в течение одного окна.
+	DefaultAttempts = 5
+	// DefaultResourceAttempts задаёт количество попыток всех клиентов к одному ресурсу,
+	// разрешённых в течение одного окна.
+	DefaultResourceAttempts = 50
+	// DefaultWindow задаёт продолжительность окна, в течение которого подсчитываются попытки.
+	DefaultWindow = time.Minute
+)
+
+// Типы данных для ограничения частоты попыток.
+type (
+	// Limiter подсчитывает попытки по каждому ключу в фиксированных окнах времени
+	// и запрещает новые попытки после исчерпания лимита до окончания окна.
+	Limiter struct {
+		locker   sync.Mutex
+		attempts int
+		window   time.Duration
+		windows  map[string]*attemptWindow
+	}
+
+	// ResourceLimiter ограничивает попытки доступа к ресурсу как для каждого клиента в отдельности,
+	// так и для всех клиентов вместе, чтобы перебор с множества адресов тоже упирался в лимит.
+	ResourceLimiter struct {
+		clients   *Limiter
+		resources *Limiter
+	}
+
+	attemptWindow struct {
+		start time.Time
+		count int
+	}
+)
+
+// NewLimiter создаёт ограничитель, разрешающий заданное количество попыток в течение окна.
+func NewLimiter(attempts int, window time.Duration) *Limiter {
+	return &Limiter{
+		attempts: attempts,
+		window:   window,
+		windows:  map[string]*attemptWindow{},
+	}
+}
+
+// NewResourceLimiter создаёт ограничитель, разрешающий заданное количество попыток одного клиента
+// и всех клиентов вместе к одному ресурсу в течение окна.
+func NewResourceLimiter(clientAttempts, resourceAttempts int, window time.Duration) *ResourceLimiter {
+	return &ResourceLimiter{
+		clients:   NewLimiter(clientAttempts, window),
+		resources: NewLimiter(resourceAttempts, window),
+	}
+}
+
+// Key возвращает ключ для подсчёта попыток доступа клиента к ресурсу, чтобы попытки одного клиента
+// не ограничивали доступ к ресурсу другим клиентам.
+func Key(resource, client string) string {
+	return resource + "|" + client
+}
+
+// Allow регистрирует попытку по ключу и сообщает, разрешена ли она.
+// Если попытка запрещена, возвращается время до окончания текущего окна.
+// Попытка учитывается сразу, чтобы параллельные попытки не превышали лимит,
+// а успешную попытку нужно вернуть методом Release.
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.cleanup(now)
+		w = &attemptWindow{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.attempts {
+		return false, l.window - now.Sub(w.start)
+	}
+
+	w.count++
+	return true, 0
+}
+
+// Release возвращает попытку по ключу, оказавшуюся успешной, чтобы учитывались только неудачные попытки.
+func (l *Limiter) Release(key string) {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+
+	if w, ok := l.windows[key]; ok && w.count > 0 {
+		w.count--
+	}
+}
+
+// cleanup удаляет окна, время которых истекло, чтобы не накапливать ключи без попыток.
+func (l *Limiter) cleanup(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// Allow регистрирует попытку клиента получить доступ к ресурсу и сообщает, разрешена ли она.
+// Попытка, запрещённая общим лимитом ресурса, не учитывается в лимите клиента.
+func (l *ResourceLimiter) Allow(resource, client string, now time.Time) (bool, time.Duration) {
+	key := Key(resource, client)
+	allowed, retryAfter := l.clients.Allow(key, now)
+	if !allowed {
+		return false, retryAfter
+	}
+
+	allowed, retryAfter = l.resources.Allow(resource, now)
+	if !allowed {
+		l.clients.Release(key)
+		return false, retryAfter
+	}
+
+	return true, 0
+}
+
+// Release возвращает успешную попытку клиента в оба лимита.
+func (l *ResourceLimiter) Release(resource, client string) {
+	l.clients.Release(Key(resource, client))
+	l.resources.Release(resource)
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(2, time.Minute)
+
+	allowed, _ := l.Allow("dummy", now)
+	assert.True(t, allowed)
+	allowed, _ = l.Allow("dummy", now.Add(time.Second))
+	assert.True(t, allowed)
+
+	allowed, retryAfter := l.Allow("dummy", now.Add(2*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 58*time.Second, retryAfter)
+
+	allowed, _ = l.Allow("dummy1", now.Add(2*time.Second))
+	assert.True(t, allowed, "попытки по другому ключу учитываются отдельно")
+
+	allowed, _ = l.Allow("dummy", now.Add(time.Minute))
+	assert.True(t, allowed, "после окончания окна попытки снова разрешены")
+}
+
+func TestLimiter_Release(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(2, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		allowed, _ := l.Allow(Key("dummy", "client"), now)
+		assert.True(t, allowed, "успешные попытки не учитываются")
+		l.Release(Key("dummy", "client"))
+	}
+
+	l.Allow(Key("dummy", "attacker"), now)
+	l.Allow(Key("dummy", "attacker"), now)
+	allowed, _ := l.Allow(Key("dummy", "attacker"), now)
+	assert.False(t, allowed)
+
+	allowed, _ = l.Allow(Key("dummy", "client"), now)
+	assert.True(t, allowed, "попытки другого клиента не ограничивают доступ к ресурсу")
+}
+
+func TestResourceLimiter_Allow(t *testing.T) {
+	now := time.Now()
+	l := NewResourceLimiter(2, 3, time.Minute)
+
+	allowed, _ := l.Allow("dummy", "client", now)
+	assert.True(t, allowed)
+	l.Release("dummy", "client")
+
+	l.Allow("dummy", "attacker1", now)
+	l.Allow("dummy", "attacker1", now)
+	allowed, _ = l.Allow("dummy", "attacker1", now)
+	assert.False(t, allowed, "лимит клиента исчерпан")
+
+	allowed, _ = l.Allow("dummy", "attacker2", now)
+	assert.True(t, allowed)
+	allowed, retryAfter := l.Allow("dummy", "attacker3", now.Add(time.Second))
+	assert.False(t, allowed, "общий лимит ресурса исчерпан попытками с разных адресов")
+	assert.Equal(t, 59*time.Second, retryAfter)
+
+	allowed, _ = l.Allow("dummy1", "attacker3", now.Add(time.Second))
+	assert.True(t, allowed, "попытки к другому ресурсу учитываются отдельно")
+
+	assert.Equal(t, 0, l.clients.windows[Key("dummy", "attacker3")].count, "попытка, запрещённая общим лимитом, не учитывается в лимите клиента")
+}
@@ -0,0 +1,101 @@
+// Пакет realip определяет IP-адрес клиента с учётом доверенных обратных прокси.
+package realip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Resolver определяет IP-адрес клиента. Заголовки X-Real-IP и X-Forwarded-For учитываются,
+// только если запрос получен от доверенного обратного прокси, иначе используется адрес соединения,
+// поскольку заголовки может подставить сам клиент.
+type Resolver struct {
+	proxies []*net.IPNet
+}
+
+// NewResolver создаёт обработчик, доверяющий заголовкам от обратных прокси из заданных IP-подсетей.
+// Отдельный IP-адрес считается подсетью из одного адреса. Если подсети не заданы, заголовки не учитываются.
+func NewResolver(proxies []string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("неверный адрес доверенного прокси: %s", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			r.proxies = append(r.proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("неверная подсеть доверенных прокси %s: %w", proxy, err)
+		}
+		r.proxies = append(r.proxies, ipNet)
+	}
+
+	return r, nil
+}
+
+// ClientIP возвращает IP-адрес клиента, отправившего запрос, или nil, если адрес определить не удалось.
+// Если запрос получен от доверенного прокси, адрес берётся из заголовка X-Real-IP, а если он не передан,
+// из заголовка X-Forwarded-For: последний адрес в цепочке, не принадлежащий доверенным прокси.
+func (r *Resolver) ClientIP(req *http.Request) net.IP {
+	remote := remoteIP(req.RemoteAddr)
+	if remote == nil || !r.trusted(remote) {
+		return remote
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+		if !r.trusted(ip) {
+			return ip
+		}
+	}
+
+	return remote
+}
+
+// trusted проверяет, принадлежит ли адрес доверенному прокси.
+func (r *Resolver) trusted(ip net.IP) bool {
+	if r == nil {
+		return false
+	}
+
+	for _, proxy := range r.proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteIP разбирает IP-адрес из адреса соединения вида host:port.
+func remoteIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	return net.ParseIP(host)
+}
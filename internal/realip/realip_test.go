@@ -0,0 +1,53 @@
+package realip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_ClientIP(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		resolver   *Resolver
+		remoteAddr string
+		header     http.Header
+		want       string
+	}{
+		{"Прямой запрос", resolver, "203.0.113.5:1234", nil, "203.0.113.5"},
+		{"Подменённый заголовок от клиента", resolver, "203.0.113.5:1234", http.Header{"X-Real-Ip": {"192.168.1.10"}}, "203.0.113.5"},
+		{"Заголовок от доверенного прокси", resolver, "192.0.2.1:1234", http.Header{"X-Real-Ip": {"192.168.1.10"}}, "192.168.1.10"},
+		{"Цепочка прокси", resolver, "10.0.0.2:1234", http.Header{"X-Forwarded-For": {"1.1.1.1, 198.51.100.7, 10.0.0.3"}}, "198.51.100.7"},
+		{"Прокси без заголовков", resolver, "10.0.0.2:1234", nil, "10.0.0.2"},
+		{"Доверенные прокси не заданы", nil, "192.0.2.1:1234", http.Header{"X-Real-Ip": {"192.168.1.10"}}, "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.header {
+				request.Header[name] = values
+			}
+
+			assert.Equal(t, tt.want, tt.resolver.ClientIP(request).String())
+		})
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	_, err := NewResolver([]string{"proxy.example"})
+	assert.Error(t, err)
+
+	_, err = NewResolver([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = NewResolver(nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,353 @@
+// Пакет safety проверяет безопасность страниц назначения исходных URL перед их сокращением.
+package safety
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// BlocklistCheckInterval задаёт периодичность проверки изменений файла со списком заблокированных доменов.
+const BlocklistCheckInterval = 30 * time.Second
+
+// Причины отказа в сокращении исходного URL.
+const (
+	ReasonBlocklisted    Reason = "blocklisted"     // Домен исходного URL входит в список заблокированных
+	ReasonPrivateAddress Reason = "private_address" // Исходный URL ведёт на внутренний или локальный адрес
+	ReasonSelfReference  Reason = "self_reference"  // Исходный URL ведёт на сам сервис и образует цикл перенаправлений
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Типы данных для проверки страниц назначения.
+type (
+	// Reason содержит машиночитаемую причину отказа в сокращении исходного URL.
+	Reason string
+
+	// Error содержит описание причины, по которой страница назначения признана небезопасной.
+	Error struct {
+		Reason  Reason // Причина отказа
+		Message string // Описание ошибки для пользователя
+	}
+
+	// DestinationChecker проверяет страницу назначения исходного URL перед сокращением.
+	// Если страница назначения небезопасна, возвращается ошибка типа *Error.
+	DestinationChecker interface {
+		Check(ctx context.Context, longURL string) error
+	}
+
+	// Blocklist проверяет страницы назначения по списку заблокированных доменов, загружаемому из файла,
+	// а также запрещает ссылки на внутренние и локальные IP-адреса и на сам сервис.
+	// Файл со списком перечитывается при изменении, см. ReloadProcess.
+	Blocklist struct {
+		baseHost string
+		basePort string
+		filePath string
+		locker   sync.RWMutex
+		domains  map[string]bool
+		modTime  time.Time
+	}
+)
+
+// Error возвращает описание причины, по которой страница назначения признана небезопасной.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewBlocklist создаёт проверку страниц назначения для сервиса с заданным корневым URL
+// и загружает список заблокированных доменов из файла, если путь к нему задан.
+// Файл содержит по одному домену в строке, пустые строки и строки, начинающиеся с #, пропускаются.
+// Домен в списке блокирует также все свои поддомены.
+func NewBlocklist(baseURL, filePath string) (*Blocklist, error) {
+	b := &Blocklist{filePath: filePath, domains: map[string]bool{}}
+
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		b.baseHost = strings.ToLower(u.Hostname())
+		b.basePort = effectivePort(u)
+	}
+
+	if filePath == "" {
+		return b, nil
+	}
+
+	_, err := b.Reload()
+	return b, err
+}
+
+// Check проверяет, что исходный URL не ведёт на заблокированный домен, внутренний или локальный адрес
+// либо на сам сервис.
+func (b *Blocklist) Check(ctx context.Context, longURL string) error {
+	u, err := url.Parse(longURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	if host == b.baseHost && effectivePort(u) == b.basePort {
+		return &Error{ReasonSelfReference, "ссылка на сам сервис образует цикл перенаправлений"}
+	}
+
+	if ip, numeric := parseIPHost(host); numeric {
+		if ip == nil || !publicIP(ip) {
+			return &Error{ReasonPrivateAddress, "ссылка на внутренний или локальный адрес запрещена: " + host}
+		}
+		return nil
+	}
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return &Error{ReasonPrivateAddress, "ссылка на внутренний или локальный адрес запрещена: " + host}
+	}
+
+	if domain, ok := b.blocked(host); ok {
+		log.Println("Домен", host, "заблокирован записью", domain)
+		return &Error{ReasonBlocklisted, "домен заблокирован: " + host}
+	}
+
+	return nil
+}
+
+// blocked проверяет, входит ли имя хоста или один из его родительских доменов в список заблокированных.
+// Возвращает найденную запись списка.
+func (b *Blocklist) blocked(host string) (string, bool) {
+	b.locker.RLock()
+	defer b.locker.RUnlock()
+
+	for domain := host; domain != ""; {
+		if b.domains[domain] {
+			return domain, true
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+
+	return "", false
+}
+
+// Reload перечитывает файл со списком заблокированных доменов, если он изменился с момента прошлой загрузки.
+// Возвращает признак того, что список был перезагружен. При ошибке чтения сохраняется прежний список.
+func (b *Blocklist) Reload() (bool, error) {
+	info, err := os.Stat(b.filePath)
+	if err != nil {
+		return false, err
+	}
+
+	b.locker.RLock()
+	unchanged := info.ModTime().Equal(b.modTime)
+	b.locker.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	f, err := os.Open(b.filePath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	domains, err := readDomains(f)
+	if err != nil {
+		return false, err
+	}
+
+	b.locker.Lock()
+	b.domains = domains
+	b.modTime = info.ModTime()
+	b.locker.Unlock()
+
+	log.Println("Загружен список заблокированных доменов из файла", b.filePath, ", записей:", len(domains))
+	return true, nil
+}
+
+// ReloadProcess периодически проверяет изменения файла со списком заблокированных доменов и перечитывает его.
+func (b *Blocklist) ReloadProcess(ctx context.Context, interval time.Duration) {
+	if b.filePath == "" {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if _, err := b.Reload(); err != nil {
+				log.Println("Ошибка при загрузке списка заблокированных доменов:", err)
+			}
+		}
+	}()
+}
+
+// readDomains читает список доменов, приводя их к нижнему регистру и кодировке punycode.
+func readDomains(r io.Reader) (map[string]bool, error) {
+	domains := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(strings.ToLower(line), "."))
+		if err != nil {
+			log.Println("Пропущена неверная запись в списке заблокированных доменов:", line, err)
+			continue
+		}
+
+		domains[domain] = true
+	}
+
+	return domains, scanner.Err()
+}
+
+// parseIPHost разбирает имя хоста, заданное IP-адресом, так же как это делают браузеры и системные библиотеки:
+// IPv4-адрес может быть записан одним числом, меньшим количеством частей и частями в шестнадцатеричной
+// или восьмеричной записи, например 2130706433, 0x7f.0.0.1 или 127.1 означают 127.0.0.1.
+// Возвращает признак того, что хост задан IP-адресом, и сам адрес или nil, если адрес записан неверно.
+func parseIPHost(host string) (net.IP, bool) {
+	if strings.Contains(host, ":") {
+		// Адреса IPv6 с указанием зоны ведут на адреса локального сетевого интерфейса.
+		if i := strings.IndexByte(host, '%'); i >= 0 {
+			return nil, true
+		}
+		return net.ParseIP(host), true
+	}
+
+	parts := strings.Split(host, ".")
+	if _, ok := parseIPv4Part(parts[len(parts)-1]); !ok && !numericPart(parts[len(parts)-1]) {
+		return nil, false
+	}
+	if len(parts) > net.IPv4len {
+		return nil, true
+	}
+
+	var value uint64
+	for i, part := range parts {
+		n, ok := parseIPv4Part(part)
+		if !ok {
+			return nil, true
+		}
+
+		if i < len(parts)-1 {
+			if n > 0xff {
+				return nil, true
+			}
+			value = value<<8 | n
+			continue
+		}
+
+		rest := uint(net.IPv4len - i)
+		if n >= 1<<(8*rest) {
+			return nil, true
+		}
+		value = value<<(8*rest) | n
+	}
+
+	return net.IPv4(byte(value>>24), byte(value>>16), byte(value>>8), byte(value)), true
+}
+
+// parseIPv4Part разбирает часть IPv4-адреса в десятичной, шестнадцатеричной (0x) или восьмеричной (0) записи.
+func parseIPv4Part(part string) (uint64, bool) {
+	base := 10
+	switch {
+	case part == "":
+		return 0, false
+	case strings.HasPrefix(part, "0x"):
+		part, base = part[2:], 16
+		if part == "" {
+			return 0, true
+		}
+	case len(part) > 1 && part[0] == '0':
+		part, base = part[1:], 8
+	}
+
+	n, err := strconv.ParseUint(part, base, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// numericPart проверяет, что часть имени хоста похожа на число, а не на метку доменного имени.
+// Такой хост считается IP-адресом, даже если число записано неверно.
+func numericPart(part string) bool {
+	if part == "" || part[0] < '0' || part[0] > '9' {
+		return false
+	}
+
+	return strings.Trim(strings.TrimPrefix(part, "0x"), "0123456789abcdef") == ""
+}
+
+// reservedNetworks содержит IP-подсети, не являющиеся публичными, которые не распознаются методами net.IP.
+var reservedNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",       // Текущая сеть
+		"100.64.0.0/10",   // Разделяемые адреса операторов связи (CGNAT)
+		"192.0.0.0/24",    // Служебные адреса IETF
+		"198.18.0.0/15",   // Адреса для тестирования производительности сетей
+		"224.0.0.0/4",     // Групповые адреса
+		"240.0.0.0/4",     // Зарезервированные адреса и широковещательный адрес
+		"64:ff9b::/96",    // Трансляция адресов IPv6 в IPv4 (NAT64)
+		"64:ff9b:1::/48",  // Трансляция адресов IPv6 в IPv4 в локальной сети
+		"ff00::/8",        // Групповые адреса IPv6
+		"::ffff:0:0:0/96", // Адреса IPv4, транслированные в IPv6 (SIIT)
+		"2002::/16",       // Туннелирование 6to4, содержащее адрес IPv4
+		"2001::/32",       // Туннелирование Teredo, содержащее адрес IPv4
+	}
+
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, networks[i], _ = net.ParseCIDR(cidr)
+	}
+
+	return networks
+}()
+
+// publicIP проверяет, что IP-адрес не является внутренним, локальным, неопределённым или зарезервированным.
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// effectivePort возвращает порт URL, а если он не задан - порт по умолчанию для схемы.
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	return defaultPorts[strings.ToLower(u.Scheme)]
+}
@@ -0,0 +1,92 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlocklist_Check(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "blocklist.txt")
+	content := "# фишинговые домены\n\nEvil.example\nпример.рф\n"
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0600))
+
+	b, err := NewBlocklist("http://localhost:8080/", filePath)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		longURL    string
+		wantReason Reason
+	}{
+		{"Обычный URL", "https://ya.ru/", ""},
+		{"Заблокированный домен", "https://evil.example/login", ReasonBlocklisted},
+		{"Поддомен заблокированного домена", "https://login.evil.example/", ReasonBlocklisted},
+		{"Домен с похожим именем", "https://notevil.example/", ""},
+		{"Интернационализированный домен", "http://xn--e1afmkfd.xn--p1ai/", ReasonBlocklisted},
+		{"Локальный IP-адрес", "http://127.0.0.1/admin", ReasonPrivateAddress},
+		{"Внутренний IP-адрес", "http://10.1.2.3/", ReasonPrivateAddress},
+		{"Локальный IPv6-адрес", "http://[::1]/", ReasonPrivateAddress},
+		{"Публичный IP-адрес", "http://8.8.8.8/", ""},
+		{"Локальный IP-адрес одним числом", "http://2130706433/", ReasonPrivateAddress},
+		{"Локальный IP-адрес в шестнадцатеричной записи", "http://0x7f.0.0.1/", ReasonPrivateAddress},
+		{"Локальный IP-адрес одним шестнадцатеричным числом", "http://0x7f000001/", ReasonPrivateAddress},
+		{"Локальный IP-адрес в восьмеричной записи", "http://0177.0.0.1/", ReasonPrivateAddress},
+		{"Сокращённый локальный IP-адрес", "http://127.1/", ReasonPrivateAddress},
+		{"Внутренний IP-адрес из трёх частей", "http://10.1.515/", ReasonPrivateAddress},
+		{"Неверно записанный IP-адрес", "http://1.2.3.256/", ReasonPrivateAddress},
+		{"Адрес CGNAT", "http://100.64.1.1/", ReasonPrivateAddress},
+		{"Адрес текущей сети", "http://0.1.2.3/", ReasonPrivateAddress},
+		{"Локальный IPv4-адрес в записи IPv6", "http://[::ffff:127.0.0.1]/", ReasonPrivateAddress},
+		{"Адрес IPv6 с зоной", "http://[fe80::1%25eth0]/", ReasonPrivateAddress},
+		{"Публичный IP-адрес одним числом", "http://134744072/", ""},
+		{"Домен с числовой меткой", "https://1.example/", ""},
+		{"Имя localhost", "http://app.localhost/", ReasonPrivateAddress},
+		{"Ссылка на сам сервис", "http://localhost:8080/abc", ReasonSelfReference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := b.Check(context.Background(), tt.longURL)
+			if tt.wantReason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var safetyErr *Error
+			assert.True(t, errors.As(err, &safetyErr))
+			assert.Equal(t, tt.wantReason, safetyErr.Reason)
+		})
+	}
+}
+
+func TestBlocklist_Reload(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "blocklist.txt")
+	assert.NoError(t, os.WriteFile(filePath, []byte("evil.example\n"), 0600))
+
+	b, err := NewBlocklist("https://shurl.example/", filePath)
+	assert.NoError(t, err)
+
+	reloaded, err := b.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded, "неизменённый файл не перечитывается")
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("phishing.example\n"), 0600))
+	assert.NoError(t, os.Chtimes(filePath, time.Now(), time.Now().Add(time.Minute)))
+
+	reloaded, err = b.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+
+	assert.NoError(t, b.Check(context.Background(), "https://evil.example/"))
+	assert.Error(t, b.Check(context.Background(), "https://phishing.example/"))
+
+	assert.NoError(t, os.Remove(filePath))
+	_, err = b.Reload()
+	assert.Error(t, err)
+	assert.Error(t, b.Check(context.Background(), "https://phishing.example/"), "при ошибке чтения сохраняется прежний список")
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ограничения на пользовательские псевдонимы коротких URL.
+const (
+	// AliasMinLength задаёт минимальную длину псевдонима.
+	AliasMinLength = 3
+	// AliasMaxLength задаёт максимальную длину псевдонима.
+	AliasMaxLength = 32
+)
+
+// Ошибки при работе с пользовательскими псевдонимами коротких URL.
+var (
+	// ErrAliasInvalid возвращается, если псевдоним не прошёл проверку формата.
+	ErrAliasInvalid = errors.New("недопустимый псевдоним короткого URL")
+	// ErrAliasTaken возвращается, если псевдоним уже используется другим коротким URL.
+	ErrAliasTaken = errors.New("псевдоним короткого URL уже занят")
+)
+
+// reservedAliases содержит слова, которые нельзя использовать в качестве псевдонима,
+// поскольку они совпадают с путями обработчиков сервиса.
+var reservedAliases = []string{"api", "ping", "debug"}
+
+// ValidateAlias проверяет, что псевдоним состоит только из латинских букв, цифр, символов '-' и '_',
+// укладывается в допустимую длину и не совпадает с зарезервированными словами.
+func ValidateAlias(alias string) error {
+	if len(alias) < AliasMinLength || len(alias) > AliasMaxLength {
+		return fmt.Errorf("%w: длина должна быть от %d до %d символов", ErrAliasInvalid, AliasMinLength, AliasMaxLength)
+	}
+
+	for _, c := range alias {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return fmt.Errorf("%w: недопустимый символ '%c'", ErrAliasInvalid, c)
+		}
+	}
+
+	for _, reserved := range reservedAliases {
+		if strings.EqualFold(alias, reserved) {
+			return fmt.Errorf("%w: слово '%s' зарезервировано", ErrAliasInvalid, reserved)
+		}
+	}
+
+	return nil
+}
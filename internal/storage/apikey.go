@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+// apiKeysFileSuffix задаёт суффикс имени файла, в котором хранятся ключи API.
+const apiKeysFileSuffix = ".keys"
+
+// ErrAPIKeyNotFound возвращается, если ключ API с заданным идентификатором не существует.
+var ErrAPIKeyNotFound = errors.New("ключ API не найден")
+
+// Типы данных для хранения ключей API.
+type (
+	// APIKey содержит ключ API для обращения к сервису от имени пользователя без cookie.
+	// Секрет ключа не хранится, хранится только его хеш.
+	APIKey struct {
+		ID        string    `json:"id"`              // Идентификатор ключа, передаваемый вместе с секретом
+		User      string    `json:"user_id"`         // Пользователь, от имени которого выполняются запросы
+		Name      string    `json:"name,omitempty"`  // Описание ключа
+		Hash      string    `json:"hash"`            // Хеш секрета ключа
+		Scope     string    `json:"scope,omitempty"` // Область действия ключа, пустое значение - без ограничений
+		CreatedAt time.Time `json:"created_at"`      // Момент создания ключа
+		RevokedAt time.Time `json:"revoked_at"`      // Момент отзыва ключа, нулевое значение - ключ действует
+	}
+
+	// APIKeyStorager обеспечивает хранение ключей API.
+	APIKeyStorager interface {
+		AddAPIKey(context.Context, APIKey) error               // Сохранение нового ключа.
+		GetAPIKey(context.Context, string) (APIKey, error)     // Получение ключа по идентификатору.
+		ListAPIKeys(context.Context) ([]APIKey, error)         // Получение всех ключей, включая отозванные.
+		RevokeAPIKey(context.Context, string, time.Time) error // Отзыв ключа в заданный момент.
+	}
+)
+
+// Revoked сообщает, отозван ли ключ.
+func (k APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// AddAPIKey сохраняет ключ API в хранилище в памяти.
+func (s *MemoryStorage) AddAPIKey(_ context.Context, key APIKey) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	s.putAPIKey(key)
+	return nil
+}
+
+// putAPIKey сохраняет ключ API в хранилище в памяти без установки блокировки.
+func (s *MemoryStorage) putAPIKey(key APIKey) {
+	if s.apiKeys == nil {
+		s.apiKeys = map[string]APIKey{}
+	}
+
+	s.apiKeys[key.ID] = key
+}
+
+// GetAPIKey возвращает ключ API с заданным идентификатором из хранилища в памяти.
+func (s *MemoryStorage) GetAPIKey(_ context.Context, id string) (APIKey, error) {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+
+	key, ok := s.apiKeys[id]
+	if !ok {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys возвращает все ключи API из хранилища в памяти в порядке их создания.
+func (s *MemoryStorage) ListAPIKeys(_ context.Context) ([]APIKey, error) {
+	s.locker.RLock()
+	result := make([]APIKey, 0, len(s.apiKeys))
+	for _, key := range s.apiKeys {
+		result = append(result, key)
+	}
+	s.locker.RUnlock()
+
+	sortAPIKeys(result)
+	return result, nil
+}
+
+// RevokeAPIKey отзывает ключ API в хранилище в памяти. Повторный отзыв не меняет момент отзыва.
+func (s *MemoryStorage) RevokeAPIKey(_ context.Context, id string, at time.Time) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	_, err := s.revokeAPIKey(id, at)
+	return err
+}
+
+// revokeAPIKey отзывает ключ API без установки блокировки и возвращает изменённый ключ.
+func (s *MemoryStorage) revokeAPIKey(id string, at time.Time) (APIKey, error) {
+	key, ok := s.apiKeys[id]
+	if !ok {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+
+	if !key.Revoked() {
+		key.RevokedAt = at.UTC()
+		s.apiKeys[id] = key
+	}
+
+	return key, nil
+}
+
+// sortAPIKeys упорядочивает ключи API по моменту создания и идентификатору.
+func sortAPIKeys(keys []APIKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+}
+
+// openAPIKeysFile открывает файл ключей API и загружает из него ранее сохранённые ключи.
+// Каждое изменение ключа дописывается в файл, поэтому действует последняя запись с тем же идентификатором.
+func (s *fileStorage) openAPIKeysFile(f string) error {
+	var err error
+
+	s.apiKeysFile, err = os.OpenFile(f, os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_SYNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	s.apiKeysEncoder = json.NewEncoder(s.apiKeysFile)
+
+	decoder := json.NewDecoder(s.apiKeysFile)
+	for decoder.More() {
+		var key APIKey
+		err = decoder.Decode(&key)
+		if err != nil {
+			return err
+		}
+
+		s.MemoryStorage.putAPIKey(key)
+	}
+
+	return nil
+}
+
+// AddAPIKey сохраняет ключ API в файл и в хранилище в памяти.
+func (s *fileStorage) AddAPIKey(ctx context.Context, key APIKey) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if s.apiKeysEncoder != nil {
+		err := s.apiKeysEncoder.Encode(&key)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.putAPIKey(key)
+	return nil
+}
+
+// RevokeAPIKey отзывает ключ API и записывает изменение в файл ключей.
+func (s *fileStorage) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	key, ok := s.apiKeys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	if key.Revoked() {
+		return nil
+	}
+
+	key.RevokedAt = at.UTC()
+	if s.apiKeysEncoder != nil {
+		err := s.apiKeysEncoder.Encode(&key)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.putAPIKey(key)
+	return nil
+}
@@ -0,0 +1,1072 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Названия разделов встроенной БД ключ-значение.
+var (
+	boltURLsBucket     = []byte("urls")      // Записи о коротких URL в формате JSON по короткому URL
+	boltLongURLsBucket = []byte("long_urls") // Короткие URL по исходным длинным URL
+	boltUserURLsBucket = []byte("user_urls") // Индекс коротких URL пользователя с ключами вида пользователь\x00короткий URL
+	boltUsersBucket    = []byte("users")     // Пользователи, добавлявшие короткие URL
+	boltExpiringBucket = []byte("expiring")  // Индекс сроков действия с ключами вида момент окончания\x00короткий URL
+	boltClicksBucket   = []byte("clicks")    // События перехода с ключами вида короткий URL\x00порядковый номер
+	boltMetaBucket     = []byte("meta")      // Счётчики для статистики сервиса
+	boltAPIKeysBucket  = []byte("api_keys")  // Ключи API в формате JSON по идентификатору ключа
+
+	boltURLsCounter  = []byte("urls")
+	boltUsersCounter = []byte("users")
+)
+
+const (
+	// BoltOpenTimeout задаёт максимальное время ожидания блокировки файла встроенной БД,
+	// если файл уже открыт другим процессом.
+	BoltOpenTimeout = time.Second
+
+	// boltImportBatchSize задаёт количество записей, импортируемых в одной транзакции.
+	boltImportBatchSize = 1000
+
+	boltKeySeparator = 0
+)
+
+// BoltStorage содержит настройки хранилища во встроенной транзакционной БД ключ-значение в одном файле
+// и ссылку на хранилище в памяти.
+// В отличие от хранилища в файле, данные не загружаются в память при запуске, а читаются из индексов БД.
+type BoltStorage struct {
+	*MemoryStorage
+	db *bolt.DB
+}
+
+// ImportResult содержит итоги импорта записей в хранилище.
+type ImportResult struct {
+	Records int // Количество импортированных записей о коротких URL
+	Clicks  int // Количество импортированных событий перехода
+}
+
+// NewBoltStorage открывает или создаёт файл встроенной БД и создаёт реализацию хранилища в нём.
+// Если файл не удалось открыть, например он заблокирован другим процессом дольше BoltOpenTimeout,
+// возвращается ошибка: запуск с хранилищем в памяти привёл бы к потере данных при перезапуске сервиса.
+func NewBoltStorage(m *MemoryStorage, filePath string) (*BoltStorage, error) {
+	db, err := bolt.Open(filePath, 0600, &bolt.Options{Timeout: BoltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла встроенной БД %s: %w", filePath, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			boltURLsBucket, boltLongURLsBucket, boltUserURLsBucket, boltUsersBucket,
+			boltExpiringBucket, boltClicksBucket, boltMetaBucket, boltAPIKeysBucket,
+		} {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err1 := db.Close(); err1 != nil {
+			log.Println(err1)
+		}
+		return nil, fmt.Errorf("ошибка при создании разделов встроенной БД: %w", err)
+	}
+
+	return &BoltStorage{MemoryStorage: m, db: db}, nil
+}
+
+// DeletionQueueProcess обрабатывает очередь запросов на удаление в отдельном потоке.
+func (s *BoltStorage) DeletionQueueProcess(ctx context.Context) {
+	deletionQueueProcess(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// DrainDeletionQueue останавливает обработку очереди на удаление и удаляет оставшиеся в ней короткие URL.
+func (s *BoltStorage) DrainDeletionQueue(ctx context.Context) error {
+	return drainDeletionQueue(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// ExpirationProcess периодически помечает удалёнными короткие URL с истёкшим сроком действия.
+func (s *BoltStorage) ExpirationProcess(ctx context.Context) {
+	go expirationProcess(ctx, s, s.expired, ExpirationCheckInterval)
+}
+
+// view выполняет транзакцию чтения, если контекст запроса ещё не завершён.
+func (s *BoltStorage) view(ctx context.Context, f func(*bolt.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.View(f)
+}
+
+// update выполняет транзакцию изменения, если контекст запроса ещё не завершён.
+func (s *BoltStorage) update(ctx context.Context, f func(*bolt.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(f)
+}
+
+// AddURL добавляет исходный длинный URL в хранилище, связывая его с созданным коротким URL.
+// Если в параметрах задан псевдоним, он используется в качестве короткого URL.
+// Если исходный длинный URL уже сокращён, возвращается ранее созданный короткий URL и ошибка дублирования.
+func (s *BoltStorage) AddURL(ctx context.Context, l, user string, opts URLOptions) (string, error) {
+	if s.db == nil {
+		return s.MemoryStorage.AddURL(ctx, l, user, opts)
+	}
+
+	if opts.Alias != "" {
+		err := ValidateAlias(opts.Alias)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var sh string
+	var duplicate bool
+	err := s.update(ctx, func(tx *bolt.Tx) error {
+		var err error
+		if opts.deduplicated() {
+			sh, duplicate, err = s.findDuplicateRecord(tx, l, user, time.Now())
+			if err != nil || duplicate {
+				return err
+			}
+		}
+
+		sh, err = s.newShortURL(tx, l, opts.Alias)
+		if err != nil {
+			return err
+		}
+
+		return s.putRecord(tx, Record{
+			ShortURL:     sh,
+			LongURL:      l,
+			UserID:       user,
+			ExpiresAt:    timeOrNil(opts.ExpiresAt),
+			PasswordHash: opts.PasswordHash,
+			CreatedAt:    timeOrNil(time.Now()),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if duplicate {
+		log.Println("Найдена ранее сохранённая запись")
+		return sh, NewStorageDBError(l, true, nil)
+	}
+
+	return sh, nil
+}
+
+// findDuplicateRecord ищет действующий короткий URL, ранее созданный для исходного длинного URL.
+func (s *BoltStorage) findDuplicateRecord(tx *bolt.Tx, l, user string, now time.Time) (string, bool, error) {
+	existing := tx.Bucket(boltLongURLsBucket).Get([]byte(s.dedupKey(l, user)))
+	if existing == nil {
+		return "", false, nil
+	}
+
+	r, err := getBoltRecord(tx, string(existing))
+	if err != nil || r == nil {
+		return "", false, err
+	}
+
+	if r.Deleted || r.memoryRecord().Expired(now) {
+		return "", false, nil
+	}
+
+	return r.ShortURL, true, nil
+}
+
+// newShortURL возвращает псевдоним, если он не занят, или создаёт новый короткий URL, отсутствующий в БД.
+func (s *BoltStorage) newShortURL(tx *bolt.Tx, l, alias string) (string, error) {
+	urls := tx.Bucket(boltURLsBucket)
+
+	if alias != "" {
+		if urls.Get([]byte(alias)) != nil {
+			log.Println("Короткий URL", alias, "уже существует в хранилище")
+			return "", ErrAliasTaken
+		}
+		return alias, nil
+	}
+
+	generator := s.shortURLGenerator()
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		sh, err := generator.Generate(l, attempt)
+		if err != nil {
+			return "", err
+		}
+
+		if urls.Get([]byte(sh)) == nil {
+			return sh, nil
+		}
+	}
+
+	return "", errGenerationExhausted
+}
+
+// AddURLs добавляет несколько исходных длинных URL в хранилище в одной транзакции,
+// связывая их с соответствующими созданными короткими URL.
+// Для ранее сокращённых исходных длинных URL возвращаются существующие короткие URL с признаком дублирования.
+func (s *BoltStorage) AddURLs(ctx context.Context, longURLs BatchURLs, user string) (BatchURLs, error) {
+	if s.db == nil {
+		return s.MemoryStorage.AddURLs(ctx, longURLs, user)
+	}
+
+	result := make(BatchURLs, 0, len(longURLs))
+	err := s.update(ctx, func(tx *bolt.Tx) error {
+		now := time.Now()
+		for _, longURL := range longURLs {
+			var sh string
+			var duplicate bool
+			var err error
+			if longURL.deduplicated() {
+				sh, duplicate, err = s.findDuplicateRecord(tx, longURL.URL, user, now)
+				if err != nil {
+					return err
+				}
+			}
+
+			if duplicate {
+				result = append(result, RecordURL{ID: longURL.ID, URL: sh, ExpiresAt: longURL.ExpiresAt, Duplicate: true})
+				continue
+			}
+
+			sh, err = s.newShortURL(tx, longURL.URL, "")
+			if err != nil {
+				return err
+			}
+
+			err = s.putRecord(tx, Record{
+				ShortURL:  sh,
+				LongURL:   longURL.URL,
+				UserID:    user,
+				ExpiresAt: timeOrNil(longURL.ExpiresAt),
+				CreatedAt: timeOrNil(time.Now()),
+			})
+			if err != nil {
+				return err
+			}
+
+			result = append(result, RecordURL{ID: longURL.ID, URL: sh, ExpiresAt: longURL.ExpiresAt})
+		}
+		return nil
+	})
+	if err != nil {
+		return result[:0], err
+	}
+
+	return result, nil
+}
+
+// FindURL ищет в хранилище исходный длинный URL по заданному короткому URL.
+func (s *BoltStorage) FindURL(ctx context.Context, sh string) (MemoryRecord, error) {
+	if s.db == nil {
+		return s.MemoryStorage.FindURL(ctx, sh)
+	}
+
+	var r *Record
+	err := s.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		r, err = getBoltRecord(tx, sh)
+		return err
+	})
+	if err != nil {
+		return MemoryRecord{}, err
+	}
+
+	if r == nil {
+		return MemoryRecord{}, errors.New("короткий URL с ID \"" + sh + "\" не существует")
+	}
+
+	return r.memoryRecord(), nil
+}
+
+// FlagURL устанавливает или снимает пометку короткого URL в хранилище.
+func (s *BoltStorage) FlagURL(ctx context.Context, sh string, flagged bool) error {
+	if s.db == nil {
+		return s.MemoryStorage.FlagURL(ctx, sh, flagged)
+	}
+
+	return s.update(ctx, func(tx *bolt.Tx) error {
+		r, err := getBoltRecord(tx, sh)
+		if err != nil {
+			return err
+		}
+
+		if r == nil {
+			return ErrURLNotFound
+		}
+
+		r.Flagged = flagged
+		return s.putRecord(tx, *r)
+	})
+}
+
+// UpdateURL заменяет исходный длинный URL короткого URL, принадлежащего пользователю.
+// Если новый исходный длинный URL уже сокращён, возвращается ошибка дублирования.
+func (s *BoltStorage) UpdateURL(ctx context.Context, sh, l, user string) error {
+	if s.db == nil {
+		return s.MemoryStorage.UpdateURL(ctx, sh, l, user)
+	}
+
+	return s.update(ctx, func(tx *bolt.Tx) error {
+		r, err := getBoltRecord(tx, sh)
+		if err != nil {
+			return err
+		}
+
+		if r == nil {
+			return ErrURLNotFound
+		}
+
+		now := time.Now()
+		err = updatable(r.memoryRecord(), user, now)
+		if err != nil || r.LongURL == l {
+			return err
+		}
+
+		existing, duplicate, err := s.findDuplicateRecord(tx, l, user, now)
+		if err != nil {
+			return err
+		}
+
+		if duplicate && existing != sh && r.memoryRecord().deduplicated() {
+			return NewStorageDBError(l, true, nil)
+		}
+
+		r.LongURL = l
+		return s.putRecord(tx, *r)
+	})
+}
+
+// RestoreURL отменяет удаление короткого URL, принадлежащего пользователю.
+// Если исходный длинный URL за это время сокращён повторно, возвращается ошибка дублирования.
+func (s *BoltStorage) RestoreURL(ctx context.Context, sh, user string) error {
+	if s.db == nil {
+		return s.MemoryStorage.RestoreURL(ctx, sh, user)
+	}
+
+	return s.update(ctx, func(tx *bolt.Tx) error {
+		r, err := getBoltRecord(tx, sh)
+		if err != nil {
+			return err
+		}
+
+		if r == nil {
+			return ErrURLNotFound
+		}
+
+		now := time.Now()
+		err = restorable(r.memoryRecord(), user, now, s.restoreGracePeriod())
+		if err != nil {
+			return err
+		}
+
+		_, duplicate, err := s.findDuplicateRecord(tx, r.LongURL, r.UserID, now)
+		if err != nil {
+			return err
+		}
+
+		if duplicate && r.memoryRecord().deduplicated() {
+			return NewStorageDBError(r.LongURL, true, nil)
+		}
+
+		r.Deleted = false
+		r.DeletedAt = nil
+		return s.putRecord(tx, *r)
+	})
+}
+
+// GetURLsByUser ищет в хранилище короткие URL, добавленные заданным пользователем.
+func (s *BoltStorage) GetURLsByUser(ctx context.Context, u string) ([]string, error) {
+	if s.db == nil {
+		return s.MemoryStorage.GetURLsByUser(ctx, u)
+	}
+
+	result := make([]string, 0)
+	err := s.view(ctx, func(tx *bolt.Tx) error {
+		prefix := boltKey(u)
+		c := tx.Bucket(boltUserURLsBucket).Cursor()
+		for k, _ := c.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			result = append(result, string(k[len(prefix):]))
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// ListURLs возвращает страницу списка коротких URL пользователя из встроенной БД.
+func (s *BoltStorage) ListURLs(ctx context.Context, user string, q ListQuery) (ListPage, error) {
+	if s.db == nil {
+		return s.MemoryStorage.ListURLs(ctx, user, q)
+	}
+
+	entries := make([]URLEntry, 0)
+	err := s.view(ctx, func(tx *bolt.Tx) error {
+		prefix := boltKey(user)
+		c := tx.Bucket(boltUserURLsBucket).Cursor()
+		for k, _ := c.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			r, err := getBoltRecord(tx, string(k[len(prefix):]))
+			if err != nil {
+				return err
+			}
+
+			if r != nil {
+				entries = append(entries, URLEntry{ShortURL: r.ShortURL, MemoryRecord: r.memoryRecord()})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ListPage{}, err
+	}
+
+	return paginate(entries, q, time.Now())
+}
+
+// DeleteURLs добавляет в очередь на удаление те из заданных коротких URL, которые принадлежат пользователю.
+// Принадлежность коротких URL проверяется в рамках запроса, а само удаление выполняется в отдельном потоке.
+// Возвращает задание на удаление с состоянием каждого короткого URL из запроса.
+func (s *BoltStorage) DeleteURLs(ctx context.Context, shortURLs []string, user string) (DeletionJob, error) {
+	if s.db == nil {
+		return s.MemoryStorage.DeleteURLs(ctx, shortURLs, user)
+	}
+
+	var results []DeletionResult
+	err := s.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		results = newDeletionResults(shortURLs, func(sh string) DeletionStatus {
+			r, getErr := getBoltRecord(tx, sh)
+			if getErr != nil {
+				err = getErr
+			}
+			if r == nil {
+				return DeletionNotFound
+			}
+			return deletionStatus(r.memoryRecord(), true, user)
+		})
+		return err
+	})
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	return s.enqueueDeletion(ctx, user, results)
+}
+
+func (s *BoltStorage) delete(ctx context.Context, deletionBatch []string) error {
+	if s.db == nil {
+		return s.MemoryStorage.delete(ctx, deletionBatch)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, sh := range deletionBatch {
+			if sh == "" {
+				continue
+			}
+
+			r, err := getBoltRecord(tx, sh)
+			if err != nil {
+				return err
+			}
+
+			if r == nil || r.Deleted {
+				continue
+			}
+
+			r.Deleted = true
+			r.DeletedAt = timeOrNil(time.Now())
+			err = s.putRecord(tx, *r)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// expired возвращает короткие URL, срок действия которых истёк к заданному моменту.
+func (s *BoltStorage) expired(now time.Time) []string {
+	if s.db == nil {
+		return s.MemoryStorage.expired(now)
+	}
+
+	result := make([]string, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bound := boltTimeKey(now)
+		c := tx.Bucket(boltExpiringBucket).Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k[:len(bound)], bound) <= 0; k, _ = c.Next() {
+			result = append(result, string(k[len(bound)+1:]))
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Ошибка при поиске коротких URL с истёкшим сроком действия:", err)
+	}
+
+	return result
+}
+
+// GetStatistics возвращает статистику сервиса: количество сокращённых URL и количество пользователей.
+func (s *BoltStorage) GetStatistics(ctx context.Context) (urls int, users int, err error) {
+	if s.db == nil {
+		return s.MemoryStorage.GetStatistics(ctx)
+	}
+
+	err = s.view(ctx, func(tx *bolt.Tx) error {
+		meta := tx.Bucket(boltMetaBucket)
+		urls = int(boltCounter(meta, boltURLsCounter))
+		users = int(boltCounter(meta, boltUsersCounter))
+		return nil
+	})
+
+	return urls, users, err
+}
+
+// walkShortURLs передаёт функции все короткие URL из встроенной БД.
+func (s *BoltStorage) walkShortURLs(ctx context.Context, fn func(sh string)) error {
+	if s.db == nil {
+		return s.MemoryStorage.walkShortURLs(ctx, fn)
+	}
+
+	return s.view(ctx, func(tx *bolt.Tx) error {
+		return tx.Bucket(boltURLsBucket).ForEach(func(k, _ []byte) error {
+			fn(string(k))
+			return nil
+		})
+	})
+}
+
+// AddClicks сохраняет пакет событий перехода по коротким URL.
+func (s *BoltStorage) AddClicks(ctx context.Context, clicks []Click) error {
+	if s.db == nil {
+		return s.MemoryStorage.AddClicks(ctx, clicks)
+	}
+
+	return s.update(ctx, func(tx *bolt.Tx) error {
+		return putBoltClicks(tx, clicks)
+	})
+}
+
+// GetClicks возвращает все события перехода по заданному короткому URL в порядке их сохранения.
+func (s *BoltStorage) GetClicks(ctx context.Context, sh string) ([]Click, error) {
+	if s.db == nil {
+		return s.MemoryStorage.GetClicks(ctx, sh)
+	}
+
+	result := make([]Click, 0)
+	err := s.view(ctx, func(tx *bolt.Tx) error {
+		prefix := boltKey(sh)
+		c := tx.Bucket(boltClicksBucket).Cursor()
+		for k, v := c.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var click Click
+			err := json.Unmarshal(v, &click)
+			if err != nil {
+				return err
+			}
+			result = append(result, click)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// MergeUser переназначает все короткие URL пользователя другому пользователю.
+func (s *BoltStorage) MergeUser(ctx context.Context, from, to string) (int, error) {
+	if s.db == nil {
+		return s.MemoryStorage.MergeUser(ctx, from, to)
+	}
+
+	if from == "" || to == "" || from == to {
+		return 0, nil
+	}
+
+	merged := 0
+	err := s.update(ctx, func(tx *bolt.Tx) error {
+		merged = 0
+
+		shortURLs := make([]string, 0)
+		prefix := boltKey(from)
+		c := tx.Bucket(boltUserURLsBucket).Cursor()
+		for k, _ := c.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			shortURLs = append(shortURLs, string(k[len(prefix):]))
+		}
+
+		for _, sh := range shortURLs {
+			r, err := getBoltRecord(tx, sh)
+			if err != nil {
+				return err
+			}
+			if r == nil || r.UserID != from {
+				continue
+			}
+
+			r.UserID = to
+			err = s.putRecord(tx, *r)
+			if err != nil {
+				return err
+			}
+			merged++
+		}
+
+		return nil
+	})
+
+	return merged, err
+}
+
+// AddAPIKey сохраняет ключ API.
+func (s *BoltStorage) AddAPIKey(ctx context.Context, key APIKey) error {
+	if s.db == nil {
+		return s.MemoryStorage.AddAPIKey(ctx, key)
+	}
+
+	return s.update(ctx, func(tx *bolt.Tx) error {
+		return putBoltAPIKey(tx, key)
+	})
+}
+
+// GetAPIKey возвращает ключ API с заданным идентификатором.
+func (s *BoltStorage) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	if s.db == nil {
+		return s.MemoryStorage.GetAPIKey(ctx, id)
+	}
+
+	var key APIKey
+	err := s.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		key, err = getBoltAPIKey(tx, id)
+		return err
+	})
+
+	return key, err
+}
+
+// ListAPIKeys возвращает все ключи API в порядке их создания.
+func (s *BoltStorage) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	if s.db == nil {
+		return s.MemoryStorage.ListAPIKeys(ctx)
+	}
+
+	result := make([]APIKey, 0)
+	err := s.view(ctx, func(tx *bolt.Tx) error {
+		return tx.Bucket(boltAPIKeysBucket).ForEach(func(_, v []byte) error {
+			var key APIKey
+			err := json.Unmarshal(v, &key)
+			if err != nil {
+				return err
+			}
+			result = append(result, key)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortAPIKeys(result)
+	return result, nil
+}
+
+// RevokeAPIKey отзывает ключ API. Повторный отзыв не меняет момент отзыва.
+func (s *BoltStorage) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	if s.db == nil {
+		return s.MemoryStorage.RevokeAPIKey(ctx, id, at)
+	}
+
+	return s.update(ctx, func(tx *bolt.Tx) error {
+		key, err := getBoltAPIKey(tx, id)
+		if err != nil {
+			return err
+		}
+		if key.Revoked() {
+			return nil
+		}
+
+		key.RevokedAt = at.UTC()
+		return putBoltAPIKey(tx, key)
+	})
+}
+
+// getBoltAPIKey читает ключ API из раздела ключей.
+func getBoltAPIKey(tx *bolt.Tx, id string) (APIKey, error) {
+	v := tx.Bucket(boltAPIKeysBucket).Get([]byte(id))
+	if v == nil {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+
+	var key APIKey
+	err := json.Unmarshal(v, &key)
+	return key, err
+}
+
+// putBoltAPIKey записывает ключ API в раздел ключей.
+func putBoltAPIKey(tx *bolt.Tx, key APIKey) error {
+	value, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(boltAPIKeysBucket).Put([]byte(key.ID), value)
+}
+
+// CloseFunc возвращает функцию для закрытия файла встроенной БД.
+func (s *BoltStorage) CloseFunc() func() {
+	return func() {
+		s.DeletionCancel()
+
+		if s.db == nil {
+			return
+		}
+
+		err := s.db.Close()
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		log.Println("файл", s.db.Path(), "был успешно закрыт")
+	}
+}
+
+// Ping проверяет, что файл встроенной БД открыт, и выдаёт ошибку, если используется хранилище в памяти.
+func (s *BoltStorage) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return s.MemoryStorage.Ping(ctx)
+	}
+
+	return nil
+}
+
+// Import загружает в хранилище записи из файла хранилища в формате JSON Lines,
+// а также события перехода из сопутствующего файла с суффиксом .clicks, если он существует.
+// Если файл хранилища сжимался с записью снимка, сначала загружаются записи из файла снимка с суффиксом .snapshot,
+// а затем записи журнала изменений из основного файла.
+// Записи с уже существующими короткими URL перезаписываются, поэтому импорт можно безопасно повторить.
+// Импорт выполняется до начала работы сервиса с файлом встроенной БД.
+func (s *BoltStorage) Import(ctx context.Context, filePath string) (ImportResult, error) {
+	var result ImportResult
+	if s.db == nil {
+		return result, errors.New("файл встроенной БД не открыт")
+	}
+
+	snapshot, err := os.Open(filePath + snapshotFileSuffix)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, err
+	}
+	if err == nil {
+		defer snapshot.Close()
+
+		result.Records, err = s.importRecords(ctx, snapshot)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+
+	records, err := s.importRecords(ctx, file)
+	result.Records += records
+	if err != nil {
+		return result, err
+	}
+
+	clicksFile, err := os.Open(filePath + clicksFileSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	defer clicksFile.Close()
+
+	result.Clicks, err = s.importClicks(ctx, clicksFile)
+	return result, err
+}
+
+// importRecords загружает записи о коротких URL пакетами, каждый пакет - в отдельной транзакции.
+func (s *BoltStorage) importRecords(ctx context.Context, r io.Reader) (int, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	count := 0
+
+	for decoder.More() {
+		batch := make([]Record, 0, boltImportBatchSize)
+		for len(batch) < boltImportBatchSize && decoder.More() {
+			var record Record
+			err := decoder.Decode(&record)
+			if err != nil {
+				return count, err
+			}
+
+			if record.ShortURL == "" || (record.LongURL == "" && !record.Deleted) {
+				continue
+			}
+			record.Checksum = 0
+			batch = append(batch, record)
+		}
+
+		err := s.update(ctx, func(tx *bolt.Tx) error {
+			for _, record := range batch {
+				err := s.putRecord(tx, record)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+
+		count += len(batch)
+	}
+
+	return count, nil
+}
+
+// importClicks загружает события перехода пакетами, каждый пакет - в отдельной транзакции.
+// Порядковым номером события служит номер строки в файле, поэтому при повторном импорте события не дублируются.
+func (s *BoltStorage) importClicks(ctx context.Context, r io.Reader) (int, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	count := 0
+
+	for decoder.More() {
+		batch := make([]Click, 0, boltImportBatchSize)
+		for len(batch) < boltImportBatchSize && decoder.More() {
+			var click Click
+			err := decoder.Decode(&click)
+			if err != nil {
+				return count, err
+			}
+			batch = append(batch, click)
+		}
+
+		err := s.update(ctx, func(tx *bolt.Tx) error {
+			b := tx.Bucket(boltClicksBucket)
+			for i, c := range batch {
+				err := putBoltClick(b, c, uint64(count+i+1))
+				if err != nil {
+					return err
+				}
+			}
+
+			if b.Sequence() < uint64(count+len(batch)) {
+				return b.SetSequence(uint64(count + len(batch)))
+			}
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+
+		count += len(batch)
+	}
+
+	return count, nil
+}
+
+// getBoltRecord читает запись о коротком URL. Если запись не найдена, возвращается nil.
+func getBoltRecord(tx *bolt.Tx, sh string) (*Record, error) {
+	value := tx.Bucket(boltURLsBucket).Get([]byte(sh))
+	if value == nil {
+		return nil, nil
+	}
+
+	r := new(Record)
+	err := json.Unmarshal(value, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// putRecord сохраняет запись о коротком URL и обновляет индексы.
+// Если запись с таким коротким URL уже существует, её прежние значения удаляются из индексов.
+func (s *BoltStorage) putRecord(tx *bolt.Tx, r Record) error {
+	previous, err := getBoltRecord(tx, r.ShortURL)
+	if err != nil {
+		return err
+	}
+
+	if previous != nil {
+		err = s.unindexRecord(tx, *previous)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = incrementBoltCounter(tx.Bucket(boltMetaBucket), boltURLsCounter)
+		if err != nil {
+			return err
+		}
+	}
+
+	value, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Bucket(boltURLsBucket).Put([]byte(r.ShortURL), value)
+	if err != nil {
+		return err
+	}
+
+	return s.indexRecord(tx, r)
+}
+
+// indexRecord добавляет запись о коротком URL в индексы.
+// Удалённые записи не занимают исходный длинный URL и не участвуют в проверке сроков действия.
+func (s *BoltStorage) indexRecord(tx *bolt.Tx, r Record) error {
+	if r.UserID != "" {
+		err := tx.Bucket(boltUserURLsBucket).Put(boltKey(r.UserID, r.ShortURL), []byte{})
+		if err != nil {
+			return err
+		}
+
+		users := tx.Bucket(boltUsersBucket)
+		if users.Get([]byte(r.UserID)) == nil {
+			err = users.Put([]byte(r.UserID), []byte{})
+			if err != nil {
+				return err
+			}
+
+			err = incrementBoltCounter(tx.Bucket(boltMetaBucket), boltUsersCounter)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.Deleted {
+		return nil
+	}
+
+	if r.memoryRecord().deduplicated() {
+		err := tx.Bucket(boltLongURLsBucket).Put([]byte(s.dedupKey(r.LongURL, r.UserID)), []byte(r.ShortURL))
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.ExpiresAt != nil {
+		return tx.Bucket(boltExpiringBucket).Put(boltExpiringKey(*r.ExpiresAt, r.ShortURL), []byte{})
+	}
+
+	return nil
+}
+
+// unindexRecord удаляет прежние значения записи о коротком URL из индексов.
+func (s *BoltStorage) unindexRecord(tx *bolt.Tx, r Record) error {
+	err := tx.Bucket(boltUserURLsBucket).Delete(boltKey(r.UserID, r.ShortURL))
+	if err != nil {
+		return err
+	}
+
+	longURLs := tx.Bucket(boltLongURLsBucket)
+	key := []byte(s.dedupKey(r.LongURL, r.UserID))
+	if string(longURLs.Get(key)) == r.ShortURL {
+		err = longURLs.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.ExpiresAt != nil {
+		return tx.Bucket(boltExpiringBucket).Delete(boltExpiringKey(*r.ExpiresAt, r.ShortURL))
+	}
+
+	return nil
+}
+
+// putBoltClicks сохраняет события перехода, нумеруя их в порядке добавления.
+func putBoltClicks(tx *bolt.Tx, clicks []Click) error {
+	b := tx.Bucket(boltClicksBucket)
+	for _, c := range clicks {
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		err = putBoltClick(b, c, seq)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// putBoltClick сохраняет событие перехода с заданным порядковым номером.
+func putBoltClick(b *bolt.Bucket, c Click, seq uint64) error {
+	value, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	key := binary.BigEndian.AppendUint64(boltKey(c.ShortURL), seq)
+	return b.Put(key, value)
+}
+
+// boltCounter возвращает значение счётчика статистики.
+func boltCounter(meta *bolt.Bucket, name []byte) uint64 {
+	value := meta.Get(name)
+	if len(value) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(value)
+}
+
+// incrementBoltCounter увеличивает значение счётчика статистики на единицу.
+func incrementBoltCounter(meta *bolt.Bucket, name []byte) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, boltCounter(meta, name)+1)
+	return meta.Put(name, value)
+}
+
+// boltKey составляет ключ индекса из частей, разделённых нулевым байтом.
+// Ключ из одной части заканчивается разделителем и используется как префикс для поиска.
+func boltKey(parts ...string) []byte {
+	key := make([]byte, 0)
+	for i, part := range parts {
+		key = append(key, part...)
+		if i < len(parts)-1 || len(parts) == 1 {
+			key = append(key, boltKeySeparator)
+		}
+	}
+
+	return key
+}
+
+// boltTimeKey представляет момент времени в виде ключа, сортируемого по возрастанию.
+func boltTimeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// boltExpiringKey составляет ключ индекса сроков действия.
+func boltExpiringKey(t time.Time, sh string) []byte {
+	key := append(boltTimeKey(t), boltKeySeparator)
+	return append(key, sh...)
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DatabaseCacheTTL задаёт время, в течение которого запись в кэше чтения из БД считается актуальной.
+// Ограничение нужно, чтобы изменения, сделанные другими экземплярами сервиса, становились видны без перезапуска.
+const DatabaseCacheTTL = 30 * time.Second
+
+// Типы данных для кэша чтения из БД.
+type (
+	// recordCache хранит ограниченное количество последних прочитанных из БД записей,
+	// вытесняя давно не использовавшиеся записи при переполнении.
+	recordCache struct {
+		locker  sync.Mutex
+		size    int
+		ttl     time.Duration
+		order   *list.List
+		entries map[string]*list.Element
+	}
+
+	cacheEntry struct {
+		shortURL string
+		record   MemoryRecord
+		storedAt time.Time
+	}
+)
+
+// newRecordCache создаёт кэш заданного размера. При размере 0 кэш не создаётся.
+func newRecordCache(size int, ttl time.Duration) *recordCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &recordCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// get возвращает запись из кэша, если она есть и ещё не устарела.
+func (c *recordCache) get(sh string, now time.Time) (MemoryRecord, bool) {
+	if c == nil {
+		return MemoryRecord{}, false
+	}
+
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	e, ok := c.entries[sh]
+	if !ok {
+		return MemoryRecord{}, false
+	}
+
+	entry := e.Value.(*cacheEntry)
+	if now.Sub(entry.storedAt) >= c.ttl {
+		c.order.Remove(e)
+		delete(c.entries, sh)
+		return MemoryRecord{}, false
+	}
+
+	c.order.MoveToFront(e)
+	return entry.record, true
+}
+
+// add помещает запись в кэш, вытесняя самую давно использовавшуюся запись при переполнении.
+func (c *recordCache) add(sh string, mr MemoryRecord, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	if e, ok := c.entries[sh]; ok {
+		e.Value = &cacheEntry{shortURL: sh, record: mr, storedAt: now}
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[sh] = c.order.PushFront(&cacheEntry{shortURL: sh, record: mr, storedAt: now})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).shortURL)
+	}
+}
+
+// remove удаляет записи из кэша.
+func (c *recordCache) remove(shortURLs ...string) {
+	if c == nil {
+		return
+	}
+
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	for _, sh := range shortURLs {
+		if e, ok := c.entries[sh]; ok {
+			c.order.Remove(e)
+			delete(c.entries, sh)
+		}
+	}
+}
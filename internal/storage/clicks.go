@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Типы данных для хранения событий перехода по коротким URL.
+type (
+	// Click содержит данные об одном переходе по короткому URL.
+	Click struct {
+		ShortURL    string    `json:"short_url"`              // Короткий URL, по которому выполнен переход
+		Time        time.Time `json:"time"`                   // Момент перехода
+		Referrer    string    `json:"referrer,omitempty"`     // Адрес страницы, с которой выполнен переход
+		UserAgent   string    `json:"user_agent,omitempty"`   // Клиентское приложение, выполнившее переход
+		VisitorHash string    `json:"visitor_hash,omitempty"` // Хеш IP-адреса клиента
+		Country     string    `json:"country,omitempty"`      // Код страны клиента
+	}
+
+	// ClickStorager обеспечивает хранилище функциями сохранения и получения событий перехода по коротким URL.
+	ClickStorager interface {
+		AddClicks(context.Context, []Click) error           // Сохранение пакета событий перехода.
+		GetClicks(context.Context, string) ([]Click, error) // Получение всех событий перехода по короткому URL.
+	}
+)
+
+// AddClicks сохраняет пакет событий перехода в хранилище в памяти.
+func (s *MemoryStorage) AddClicks(_ context.Context, clicks []Click) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	s.addClicks(clicks)
+	return nil
+}
+
+// addClicks сохраняет пакет событий перехода в хранилище в памяти без установки блокировки.
+func (s *MemoryStorage) addClicks(clicks []Click) {
+	if s.clicks == nil {
+		s.clicks = map[string][]Click{}
+	}
+
+	for _, click := range clicks {
+		s.clicks[click.ShortURL] = append(s.clicks[click.ShortURL], click)
+	}
+}
+
+// GetClicks возвращает все события перехода по заданному короткому URL из хранилища в памяти.
+func (s *MemoryStorage) GetClicks(_ context.Context, sh string) ([]Click, error) {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+
+	result := make([]Click, len(s.clicks[sh]))
+	copy(result, s.clicks[sh])
+	return result, nil
+}
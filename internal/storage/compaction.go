@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// CompactionCheckInterval задаёт периодичность проверки необходимости сжатия файла хранилища.
+	CompactionCheckInterval = time.Minute
+
+	// snapshotFileSuffix задаёт суффикс имени файла снимка хранилища.
+	snapshotFileSuffix = ".snapshot"
+)
+
+// Типы данных для сжатия файла хранилища.
+type (
+	// CompactionOptions содержит настройки сжатия файла хранилища.
+	CompactionOptions struct {
+		MinSize  int64   // Минимальный размер файла в байтах, начиная с которого он сжимается автоматически
+		Ratio    float64 // Отношение количества строк в файлах к количеству коротких URL, при котором файл сжимается автоматически, 0 - автоматическое сжатие отключено
+		Snapshot bool    // Признак записи коротких URL в файл снимка, после которого в основном файле ведётся журнал изменений
+	}
+
+	// CompactionResult содержит размеры файлов хранилища до и после сжатия.
+	CompactionResult struct {
+		LinesBefore int   `json:"lines_before"` // Количество строк до сжатия
+		LinesAfter  int   `json:"lines_after"`  // Количество строк после сжатия
+		SizeBefore  int64 `json:"size_before"`  // Размер файлов в байтах до сжатия
+		SizeAfter   int64 `json:"size_after"`   // Размер файлов в байтах после сжатия
+	}
+
+	// Compactor обеспечивает сжатие хранилища по запросу.
+	Compactor interface {
+		Compact(context.Context) (CompactionResult, error)
+	}
+)
+
+// CompactionProcess периодически проверяет размер файла хранилища и сжимает его при превышении заданных порогов.
+func (s *fileStorage) CompactionProcess(ctx context.Context) {
+	if s.compaction.Ratio <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(CompactionCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if !s.needsCompaction() {
+				continue
+			}
+
+			result, err := s.Compact(ctx)
+			if err != nil {
+				log.Println("Ошибка при сжатии файла хранилища:", err)
+				continue
+			}
+
+			log.Println("Файл хранилища сжат, строк было", result.LinesBefore, "стало", result.LinesAfter)
+		}
+	}()
+}
+
+// needsCompaction проверяет, превышены ли пороги размера файла хранилища и отношения количества строк к количеству коротких URL.
+func (s *fileStorage) needsCompaction() bool {
+	s.fileLocker.Lock()
+	lines := s.lines
+	size, err := s.filesSize()
+	s.fileLocker.Unlock()
+
+	if err != nil {
+		log.Println("Ошибка при получении размера файла хранилища:", err)
+		return false
+	}
+
+	s.locker.RLock()
+	records := len(s.container)
+	s.locker.RUnlock()
+
+	return size >= s.compaction.MinSize && float64(lines) >= s.compaction.Ratio*float64(records)
+}
+
+// Compact перезаписывает файл хранилища так, чтобы в нём осталось по одной строке на каждый действующий короткий URL.
+// Удалённые короткие URL сохраняются полностью только до окончания срока, в течение которого пользователь может
+// их восстановить, после чего от них остаётся только короткий URL с признаком удаления, чтобы после перезапуска
+// сервиса генератор и проверка совпадений не выдали этот короткий URL повторно для другого исходного URL.
+// Новый файл сначала записывается во временный файл, который затем переименовывается,
+// поэтому при сбое во время сжатия сохраняется прежний файл.
+// Если включена запись снимка, короткие URL записываются в файл снимка, а основной файл очищается.
+func (s *fileStorage) Compact(ctx context.Context) (CompactionResult, error) {
+	var result CompactionResult
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	s.fileLocker.Lock()
+	defer s.fileLocker.Unlock()
+
+	if s.file == nil {
+		return result, errors.New("файл хранилища не открыт")
+	}
+
+	var err error
+	result.LinesBefore = s.lines
+	result.SizeBefore, err = s.filesSize()
+	if err != nil {
+		return result, err
+	}
+
+	records := s.records(time.Now())
+
+	if s.compaction.Snapshot {
+		err = writeRecordsFile(s.filePath+snapshotFileSuffix, records)
+		if err != nil {
+			return result, err
+		}
+
+		err = s.file.Truncate(0)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		err = writeRecordsFile(s.filePath, records)
+		if err != nil {
+			return result, err
+		}
+
+		err = s.reopenFile()
+		if err != nil {
+			return result, err
+		}
+
+		err = os.Remove(s.filePath + snapshotFileSuffix)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return result, err
+		}
+	}
+
+	s.lines = len(records)
+	result.LinesAfter = s.lines
+	result.SizeAfter, err = s.filesSize()
+
+	return result, err
+}
+
+// records возвращает записи хранилища в памяти для записи в сжатый файл, упорядоченные по короткому URL.
+// Удалённые короткие URL, срок восстановления которых истёк, заменяются в хранилище в памяти записями,
+// содержащими только признак удаления.
+func (s *fileStorage) records(now time.Time) []Record {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	cutoff := now.Add(-s.restoreGracePeriod())
+	records := make([]Record, 0, len(s.container))
+	for sh, mr := range s.container {
+		if mr.Deleted && !mr.DeletedAt.After(cutoff) {
+			s.moveUserURL(sh, mr.User, "")
+			mr = MemoryRecord{Deleted: true}
+			s.container[sh] = mr
+		}
+
+		records = append(records, newRecord(sh, mr))
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ShortURL < records[j].ShortURL
+	})
+
+	return records
+}
+
+// reopenFile закрывает прежний основной файл хранилища и открывает файл, записанный на его место.
+func (s *fileStorage) reopenFile() error {
+	previous := s.file
+
+	err := s.openFile(s.filePath)
+	if err != nil {
+		s.file = previous
+		return err
+	}
+
+	err = previous.Close()
+	if err != nil {
+		log.Println(err)
+	}
+
+	return nil
+}
+
+// filesSize возвращает суммарный размер основного файла хранилища и файла снимка.
+func (s *fileStorage) filesSize() (int64, error) {
+	info, err := s.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	info, err = os.Stat(s.filePath + snapshotFileSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return size, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return size + info.Size(), nil
+}
+
+// writeRecordsFile записывает записи во временный файл в том же каталоге и заменяет им файл с заданным именем.
+func writeRecordsFile(f string, records []Record) error {
+	dir := filepath.Dir(f)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	encoder := json.NewEncoder(tmp)
+	for i := range records {
+		err = encodeRecord(encoder, &records[i])
+		if err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), f)
+	if err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir сбрасывает на диск изменения каталога, чтобы переименование файла сохранилось при сбое.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	err = d.Sync()
+	if err != nil {
+		log.Println("Не удалось сбросить на диск изменения каталога", dir, ":", err)
+	}
+
+	return nil
+}
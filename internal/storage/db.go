@@ -2,26 +2,71 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"log"
+	"sort"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
 	txPreparedInsert = "shurl-insert"
 	txPreparedDelete = "shurl-delete"
+
+	constraintShortURLPrimaryKey = "short_urls_pkey"
+	constraintDedupKey           = "unique_dedup_key"
+
+	// longURLLockClass задаёт первую часть ключа рекомендательной блокировки исходного длинного URL в БД,
+	// отделяя эти блокировки от других блокировок сервиса.
+	longURLLockClass = 0x7368
 )
 
 // Типы данных, относящиеся к реализации хранилища в БД.
 type (
-	// DatabaseStorage содержит настройки хранилища в БД, включающие соединение с БД и ссылку на хранилище в памяти.
+	// DatabaseStorage содержит настройки хранилища в БД, включающие пул соединений с БД, кэш чтения
+	// и ссылку на хранилище в памяти, которое используется, если соединение с БД не установлено.
+	// Все данные о коротких URL читаются непосредственно из БД, поэтому несколько экземпляров сервиса
+	// могут работать с одной БД.
 	DatabaseStorage struct {
 		*MemoryStorage
-		conn *pgx.Conn
+		pool           *pgxpool.Pool
+		acquireTimeout time.Duration
+		cache          *recordCache
+	}
+
+	// DatabaseOptions содержит настройки пула соединений с БД и кэша чтения.
+	// Нулевые значения означают использование настроек pgxpool по умолчанию и отключённый кэш.
+	DatabaseOptions struct {
+		CacheSize         int           // Количество записей в кэше чтения
+		MinConns          int           // Минимальное количество соединений в пуле
+		MaxConns          int           // Максимальное количество соединений в пуле
+		HealthCheckPeriod time.Duration // Период проверки простаивающих соединений
+		AcquireTimeout    time.Duration // Максимальное время ожидания свободного соединения
+	}
+
+	// PoolStats содержит статистику пула соединений с БД.
+	PoolStats struct {
+		TotalConns           int32         `json:"total_conns"`            // Общее количество соединений
+		IdleConns            int32         `json:"idle_conns"`             // Количество простаивающих соединений
+		AcquiredConns        int32         `json:"acquired_conns"`         // Количество занятых соединений
+		MaxConns             int32         `json:"max_conns"`              // Максимальное количество соединений
+		AcquireCount         int64         `json:"acquire_count"`          // Количество успешных получений соединения
+		EmptyAcquireCount    int64         `json:"empty_acquire_count"`    // Количество получений соединения с ожиданием
+		CanceledAcquireCount int64         `json:"canceled_acquire_count"` // Количество отменённых получений соединения
+		AcquireDuration      time.Duration `json:"acquire_duration_ns"`    // Общее время ожидания соединений в наносекундах
+	}
+
+	// PoolStatser позволяет получить статистику пула соединений хранилища, если оно его использует.
+	PoolStatser interface {
+		PoolStats() (PoolStats, bool)
 	}
 
 	// DBError описывает структуру данных об ошибке при взаимодействии с хранилищем в БД.
@@ -38,16 +83,47 @@ var DBErrorDublicate = NewStorageDBError("", true, nil)
 // DBErrorUnknown содержит типовую ошибку при взаимодействии с БД.
 var DBErrorUnknown = NewStorageDBError("", false, nil)
 
-// DeletionQueueProcess обрабатывает очередь запросов на удаление, вызывая обработчик каждой записи в отдельном потоке.
+// DeletionQueueProcess обрабатывает очередь запросов на удаление в отдельном потоке.
 func (s *DatabaseStorage) DeletionQueueProcess(ctx context.Context) {
-	go deletionQueueProcess(ctx, s, s.MemoryStorage.deletionQueue)
+	deletionQueueProcess(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// DrainDeletionQueue останавливает обработку очереди на удаление и удаляет оставшиеся в ней короткие URL.
+func (s *DatabaseStorage) DrainDeletionQueue(ctx context.Context) error {
+	return drainDeletionQueue(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// ExpirationProcess периодически помечает удалёнными в БД короткие URL с истёкшим сроком действия.
+func (s *DatabaseStorage) ExpirationProcess(ctx context.Context) {
+	go expirationProcess(ctx, s, s.expired, ExpirationCheckInterval)
+}
+
+// expired возвращает из БД короткие URL, срок действия которых истёк к заданному моменту.
+func (s *DatabaseStorage) expired(now time.Time) []string {
+	if s.pool == nil {
+		return s.MemoryStorage.expired(now)
+	}
+
+	result, err := s.queryShortURLs(context.Background(), querySelectExpired, now)
+	if err != nil {
+		log.Println("Ошибка при поиске в БД коротких URL с истёкшим сроком действия:", err)
+	}
+
+	return result
 }
 
 func (s *DatabaseStorage) delete(ctx context.Context, deletionBatch []string) error {
-	s.locker.Lock()
-	defer s.locker.Unlock()
+	if s.pool == nil {
+		return s.MemoryStorage.delete(ctx, deletionBatch)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
 
-	tx, err := s.conn.Begin(ctx)
+	tx, err := conn.Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -75,55 +151,69 @@ func (s *DatabaseStorage) delete(ctx context.Context, deletionBatch []string) er
 		return err
 	}
 
-	return s.MemoryStorage.delete(ctx, deletionBatch)
+	s.cache.remove(deletionBatch...)
+	return nil
 }
 
-// NewDBStorage создаёт реализацию хранилища в БД.
-func NewDBStorage(ctx context.Context, m *MemoryStorage, database string) *DatabaseStorage {
-	storage := &DatabaseStorage{MemoryStorage: m, conn: nil}
+// NewDBStorage создаёт реализацию хранилища в БД с пулом соединений.
+// Если размер кэша больше 0, последние прочитанные записи хранятся в памяти в течение DatabaseCacheTTL.
+func NewDBStorage(ctx context.Context, m *MemoryStorage, database string, opts DatabaseOptions) *DatabaseStorage {
+	storage := &DatabaseStorage{
+		MemoryStorage:  m,
+		pool:           nil,
+		acquireTimeout: opts.AcquireTimeout,
+		cache:          newRecordCache(opts.CacheSize, DatabaseCacheTTL),
+	}
 
-	var err error
-	storage.conn, err = pgx.Connect(ctx, database)
+	poolConfig, err := pgxpool.ParseConfig(database)
 	if err != nil {
 		log.Println(err)
 		return storage
 	}
 
-	err = storage.init(ctx)
-	if err != nil {
-		log.Fatal(err)
+	if opts.MinConns > 0 {
+		poolConfig.MinConns = int32(opts.MinConns)
+	}
+	if opts.MaxConns > 0 {
+		poolConfig.MaxConns = int32(opts.MaxConns)
+	}
+	if opts.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = opts.HealthCheckPeriod
 	}
 
-	return storage
-}
-
-func (s *DatabaseStorage) init(ctx context.Context) error {
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		log.Println(err)
+		return storage
+	}
 
-	_, err := s.conn.Exec(ctx, queryCreateTable)
+	err = pool.Ping(ctx)
 	if err != nil {
-		return err
+		log.Println(err)
+		pool.Close()
+		return storage
 	}
 
-	rows, err := s.conn.Query(ctx, querySelectAll)
+	storage.pool = pool
+
+	err = storage.init(ctx)
 	if err != nil {
-		return err
+		log.Fatal(err)
 	}
 
-	defer rows.Close()
+	storage.deletionQueue.outbox = storage
 
-	for rows.Next() {
-		var sh, l, u string
-		var d bool
-		err = rows.Scan(&sh, &l, &u, &d)
-		if err != nil {
-			log.Println("Ошибка чтения из БД:", err)
-		}
+	return storage
+}
 
-		s.MemoryStorage.container[sh] = MemoryRecord{LongURL: l, Deleted: d, User: u}
-		s.MemoryStorage.usersURLs[u] = append(s.MemoryStorage.usersURLs[u], sh)
+// init применяет к БД миграции схемы, которые ещё не были применены.
+func (s *DatabaseStorage) init(ctx context.Context) error {
+	migrator, err := NewMigrator(s.pool)
+	if err != nil {
+		return err
 	}
 
-	err = rows.Err()
+	_, err = migrator.Up(ctx)
 	if err != nil {
 		return err
 	}
@@ -165,40 +255,176 @@ func NewStorageDBError(longURL string, duplicate bool, err error) error {
 }
 
 // AddURL добавляет исходный длинный URL в хранилище в БД, связывая его с созданным коротким URL.
-func (s *DatabaseStorage) AddURL(l, user string) (string, error) {
+// Если в параметрах задан псевдоним, он используется в качестве короткого URL.
+// Если исходный длинный URL уже сокращён, возвращается ранее созданный короткий URL и ошибка дублирования.
+func (s *DatabaseStorage) AddURL(ctx context.Context, l, user string, opts URLOptions) (string, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.AddURL(ctx, l, user, opts)
+	}
+
+	if opts.Alias != "" {
+		err := ValidateAlias(opts.Alias)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Release()
 
-	sh, err := s.MemoryStorage.AddURL(l, user)
+	tx, err := conn.Begin(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	s.locker.Lock()
-	defer s.locker.Unlock()
+	defer func() {
+		if err1 := tx.Rollback(context.Background()); err1 != nil && !errors.Is(err1, pgx.ErrTxClosed) {
+			log.Println(err1)
+		}
+	}()
 
-	ctx := context.Background()
-	var pgErr *pgconn.PgError
-	ct, err := s.conn.Exec(ctx, queryInsert, sh, l, user)
-	if err != nil && !errors.As(err, &pgErr) {
+	err = s.lockLongURLs(ctx, tx, []string{l}, user)
+	if err != nil {
 		return "", err
 	}
 
-	if err != nil && pgErr.Code != pgerrcode.UniqueViolation {
-		log.Println("Ошибка операции с БД, код:", pgErr.Code, ", сообщение:", pgErr.Error())
+	if opts.deduplicated() {
+		existing, err := s.findDuplicate(ctx, tx, l, user)
+		if err != nil {
+			return "", NewStorageDBError(l, false, err)
+		}
+
+		if existing != "" {
+			log.Println("Найдена ранее сохранённая запись")
+			return existing, NewStorageDBError(l, true, nil)
+		}
+	}
+
+	var sh string
+	if opts.Alias != "" {
+		sh, err = s.insertURL(ctx, tx, queryInsert, opts.Alias, l, user, opts)
+	} else {
+		sh, err = s.insertGenerated(ctx, tx, queryInsertGenerated, l, user, opts)
+	}
+	if err != nil {
 		return "", err
 	}
 
+	err = tx.Commit(ctx)
 	if err != nil {
-		log.Println("Ошибка операции с БД, код:", pgErr.Code, ", сообщение:", pgErr.Error())
-		duplicateErr := NewStorageDBError(l, true, err)
+		return "", err
+	}
 
-		r := s.conn.QueryRow(ctx, querySelectByLongURL, l)
-		err = r.Scan(&sh)
+	return sh, nil
+}
+
+// lockLongURLs получает до конца транзакции рекомендательные блокировки исходных длинных URL,
+// чтобы одновременные запросы не создали несколько коротких URL для одного исходного длинного URL.
+// Блокировки получаются в порядке возрастания ключей, что исключает взаимную блокировку транзакций.
+func (s *DatabaseStorage) lockLongURLs(ctx context.Context, tx pgx.Tx, longURLs []string, user string) error {
+	keys := make([]int32, 0, len(longURLs))
+	seen := make(map[int32]bool, len(longURLs))
+	for _, l := range longURLs {
+		key := int32(crc32.ChecksumIEEE([]byte(s.dedupKey(l, user))))
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		_, err := tx.Exec(ctx, queryLockLongURL, longURLLockClass, key)
 		if err != nil {
-			return "", NewStorageDBError(l, false, err)
+			return err
 		}
+	}
+
+	return nil
+}
+
+// dbDedupKey возвращает ключ поиска дублирующихся URL для уникального индекса в БД.
+// Уникальный индекс дополняет рекомендательные блокировки: если блокировка не сработает,
+// второй действующий короткий URL для того же исходного длинного URL не будет сохранён.
+// Записям, не участвующим в поиске дублирующихся URL, ключ не назначается, а при удалении записи он снимается.
+func (s *DatabaseStorage) dbDedupKey(l, user string, deduplicated bool) *string {
+	if !deduplicated {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(s.dedupKey(l, user)))
+	key := hex.EncodeToString(sum[:])
+	return &key
+}
 
-		log.Println("Найдена ранее сохранённая запись")
-		return sh, duplicateErr
+// findDuplicate ищет действующий короткий URL, ранее созданный для исходного длинного URL,
+// с учётом области поиска дублирующихся URL. Если такого нет, возвращается пустая строка.
+// Записи с паролем или сроком действия не рассматриваются, см. URLOptions.deduplicated.
+func (s *DatabaseStorage) findDuplicate(ctx context.Context, tx pgx.Tx, l, user string) (string, error) {
+	var row pgx.Row
+	if s.dedup == DedupUser {
+		row = tx.QueryRow(ctx, querySelectByLongURLAndUser, l, user)
+	} else {
+		row = tx.QueryRow(ctx, querySelectByLongURL, l)
+	}
+
+	var sh string
+	err := row.Scan(&sh)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+
+	return sh, err
+}
+
+// insertGenerated добавляет запись о коротком URL в БД, повторяя генерацию при совпадении с существующим коротким URL.
+func (s *DatabaseStorage) insertGenerated(ctx context.Context, tx pgx.Tx, query, l, user string, opts URLOptions) (string, error) {
+	generator := s.shortURLGenerator()
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		sh, err := generator.Generate(l, attempt)
+		if err != nil {
+			return "", err
+		}
+
+		sh, err = s.insertURL(ctx, tx, query, sh, l, user, opts)
+		if errors.Is(err, ErrAliasTaken) {
+			continue
+		}
+
+		return sh, err
+	}
+
+	return "", errGenerationExhausted
+}
+
+// insertURL добавляет запись о коротком URL в БД.
+// Если короткий URL уже существует, возвращается ErrAliasTaken.
+func (s *DatabaseStorage) insertURL(ctx context.Context, tx pgx.Tx, query, sh, l, user string, opts URLOptions) (string, error) {
+	var pgErr *pgconn.PgError
+	ct, err := tx.Exec(ctx, query, sh, l, user, timeOrNil(opts.ExpiresAt), passwordHashOrNil(opts.PasswordHash), s.dbDedupKey(l, user, opts.deduplicated()))
+	if err != nil && errors.As(err, &pgErr) {
+		log.Println("Ошибка операции с БД, код:", pgErr.Code, ", сообщение:", pgErr.Error())
+	}
+
+	if err != nil && errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == constraintDedupKey {
+		log.Println("Исходный длинный URL", l, "одновременно сокращён другим запросом")
+		return "", NewStorageDBError(l, false, err)
+	}
+
+	if (err != nil && errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == constraintShortURLPrimaryKey) ||
+		(err == nil && ct.RowsAffected() == 0) {
+		log.Println("Короткий URL", sh, "уже существует в БД")
+		return "", ErrAliasTaken
+	}
+
+	if err != nil {
+		return "", err
 	}
 
 	log.Println("Добавлено строк:", ct.RowsAffected())
@@ -206,42 +432,68 @@ func (s *DatabaseStorage) AddURL(l, user string) (string, error) {
 }
 
 // AddURLs добавляет несколько исходных длинных URL в хранилище в БД, связывая их с соответствующими созданными короткими URL.
-func (s *DatabaseStorage) AddURLs(longURLs BatchURLs, user string) (BatchURLs, error) {
+// Для ранее сокращённых исходных длинных URL, в том числе повторяющихся в пакете,
+// возвращаются существующие короткие URL с признаком дублирования.
+func (s *DatabaseStorage) AddURLs(ctx context.Context, longURLs BatchURLs, user string) (BatchURLs, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.AddURLs(ctx, longURLs, user)
+	}
+
 	result := make(BatchURLs, 0, len(longURLs))
 
-	ctx := context.Background()
-	tx, err := s.conn.Begin(ctx)
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return result[:0], err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
 	if err != nil {
 		return result[:0], err
 	}
 
 	defer func() {
-		if err1 := tx.Rollback(ctx); err1 != nil {
+		if err1 := tx.Rollback(context.Background()); err1 != nil && !errors.Is(err1, pgx.ErrTxClosed) {
 			log.Println(err1)
 		}
 	}()
 
-	_, err = tx.Prepare(ctx, txPreparedInsert, queryInsert)
+	_, err = tx.Prepare(ctx, txPreparedInsert, queryInsertGenerated)
+	if err != nil {
+		return result[:0], err
+	}
+
+	urls := make([]string, len(longURLs))
+	for i, longURL := range longURLs {
+		urls[i] = longURL.URL
+	}
+
+	err = s.lockLongURLs(ctx, tx, urls, user)
 	if err != nil {
 		return result[:0], err
 	}
 
 	for _, longURL := range longURLs {
-		sh, err2 := s.MemoryStorage.AddURL(longURL.URL, user)
-		if err2 != nil {
-			return result[:0], err2
+		var existing string
+		if longURL.deduplicated() {
+			existing, err = s.findDuplicate(ctx, tx, longURL.URL, user)
+			if err != nil {
+				return result[:0], err
+			}
 		}
 
-		_, err2 = tx.Exec(ctx, txPreparedInsert, sh, longURL.URL, user)
-		if err2 != nil {
-			return result[:0], err2
+		if existing != "" {
+			result = append(result, RecordURL{ID: longURL.ID, URL: existing, ExpiresAt: longURL.ExpiresAt, Duplicate: true})
+			continue
 		}
 
-		result = append(result, RecordURL{ID: longURL.ID, URL: sh})
-	}
+		sh, err := s.insertGenerated(ctx, tx, txPreparedInsert, longURL.URL, user, URLOptions{ExpiresAt: longURL.ExpiresAt})
+		if err != nil {
+			return result[:0], err
+		}
 
-	s.locker.Lock()
-	defer s.locker.Unlock()
+		result = append(result, RecordURL{ID: longURL.ID, URL: sh, ExpiresAt: longURL.ExpiresAt})
+	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
@@ -251,32 +503,737 @@ func (s *DatabaseStorage) AddURLs(longURLs BatchURLs, user string) (BatchURLs, e
 	return result, nil
 }
 
-// CloseFunc возвращает функцию для закрытия соединения с БД, используемой для хранения информации о коротких и длинных URL.
+// FindURL ищет в БД исходный длинный URL по заданному короткому URL, используя кэш чтения, если он включён.
+func (s *DatabaseStorage) FindURL(ctx context.Context, sh string) (MemoryRecord, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.FindURL(ctx, sh)
+	}
+
+	if mr, ok := s.cache.get(sh, time.Now()); ok {
+		return mr, nil
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return MemoryRecord{}, err
+	}
+	defer conn.Release()
+
+	mr, err := scanRecord(conn.QueryRow(ctx, querySelectByShortURL, sh))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return MemoryRecord{}, errors.New("короткий URL с ID \"" + sh + "\" не существует")
+	}
+	if err != nil {
+		return MemoryRecord{}, err
+	}
+
+	s.cache.add(sh, mr, time.Now())
+	return mr, nil
+}
+
+// FlagURL устанавливает или снимает пометку короткого URL в БД.
+func (s *DatabaseStorage) FlagURL(ctx context.Context, sh string, flagged bool) error {
+	if s.pool == nil {
+		return s.MemoryStorage.FlagURL(ctx, sh, flagged)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ct, err := conn.Exec(ctx, queryFlag, sh, flagged)
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return ErrURLNotFound
+	}
+
+	s.cache.remove(sh)
+	return nil
+}
+
+// UpdateURL заменяет в БД исходный длинный URL короткого URL, принадлежащего пользователю.
+// Если новый исходный длинный URL уже сокращён, возвращается ошибка дублирования.
+func (s *DatabaseStorage) UpdateURL(ctx context.Context, sh, l, user string) error {
+	if s.pool == nil {
+		return s.MemoryStorage.UpdateURL(ctx, sh, l, user)
+	}
+
+	return s.modifyURL(ctx, sh, func(tx pgx.Tx, mr MemoryRecord) error {
+		err := updatable(mr, user, time.Now())
+		if err != nil || mr.LongURL == l {
+			return err
+		}
+
+		err = s.lockLongURLs(ctx, tx, []string{l}, user)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.findDuplicate(ctx, tx, l, user)
+		if err != nil {
+			return NewStorageDBError(l, false, err)
+		}
+
+		if existing != "" && existing != sh && mr.deduplicated() {
+			return NewStorageDBError(l, true, nil)
+		}
+
+		_, err = tx.Exec(ctx, queryUpdateLongURL, sh, l, s.dbDedupKey(l, user, mr.deduplicated()))
+		return err
+	})
+}
+
+// RestoreURL отменяет в БД удаление короткого URL, принадлежащего пользователю.
+// Если исходный длинный URL за это время сокращён повторно, возвращается ошибка дублирования.
+func (s *DatabaseStorage) RestoreURL(ctx context.Context, sh, user string) error {
+	if s.pool == nil {
+		return s.MemoryStorage.RestoreURL(ctx, sh, user)
+	}
+
+	return s.modifyURL(ctx, sh, func(tx pgx.Tx, mr MemoryRecord) error {
+		err := restorable(mr, user, time.Now(), s.restoreGracePeriod())
+		if err != nil {
+			return err
+		}
+
+		err = s.lockLongURLs(ctx, tx, []string{mr.LongURL}, mr.User)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.findDuplicate(ctx, tx, mr.LongURL, mr.User)
+		if err != nil {
+			return NewStorageDBError(mr.LongURL, false, err)
+		}
+
+		if existing != "" && mr.deduplicated() {
+			return NewStorageDBError(mr.LongURL, true, nil)
+		}
+
+		_, err = tx.Exec(ctx, queryRestore, sh, s.dbDedupKey(mr.LongURL, mr.User, mr.deduplicated()))
+		return err
+	})
+}
+
+// modifyURL блокирует запись о коротком URL до конца транзакции и передаёт её функции изменения.
+// Если функция изменения завершилась без ошибки, транзакция фиксируется, а запись удаляется из кэша чтения.
+func (s *DatabaseStorage) modifyURL(ctx context.Context, sh string, modify func(pgx.Tx, MemoryRecord) error) error {
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err1 := tx.Rollback(context.Background()); err1 != nil && !errors.Is(err1, pgx.ErrTxClosed) {
+			log.Println(err1)
+		}
+	}()
+
+	var mr MemoryRecord
+	var d, e *time.Time
+	var p *string
+	err = tx.QueryRow(ctx, querySelectForUpdate, sh).Scan(&mr.LongURL, &mr.User, &mr.Deleted, &d, &e, &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrURLNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if d != nil {
+		mr.DeletedAt = *d
+	}
+	if e != nil {
+		mr.ExpiresAt = *e
+	}
+	if p != nil {
+		mr.PasswordHash = *p
+	}
+
+	err = modify(tx, mr)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.cache.remove(sh)
+	return nil
+}
+
+// GetURLsByUser ищет в БД короткие URL, добавленные заданным пользователем.
+func (s *DatabaseStorage) GetURLsByUser(ctx context.Context, u string) ([]string, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.GetURLsByUser(ctx, u)
+	}
+
+	return s.queryShortURLs(ctx, querySelectByUser, u)
+}
+
+// ListURLs возвращает страницу списка коротких URL пользователя из БД.
+// Отбор, сортировка и ограничение размера страницы выполняются в БД, следующая страница ищется по ключу сортировки.
+func (s *DatabaseStorage) ListURLs(ctx context.Context, user string, q ListQuery) (ListPage, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.ListURLs(ctx, user, q)
+	}
+
+	after, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return ListPage{}, err
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	query := querySelectPageByUser
+	args := []any{user}
+
+	switch q.Status {
+	case StatusAll:
+		query += filterNotDeleted
+	case StatusActive:
+		query += filterActive
+	case StatusDeleted:
+		query += filterDeleted
+	case StatusExpired:
+		query += filterExpired
+	}
+
+	if q.Search != "" {
+		args = append(args, q.Search)
+		query += fmt.Sprintf(filterSearch, len(args))
+	}
+
+	filterAfter, order := filterAfterDesc, orderDesc
+	if q.Order == OrderAsc {
+		filterAfter, order = filterAfterAsc, orderAsc
+	}
+
+	if after != nil {
+		args = append(args, after.createdAt, after.shortURL)
+		query += fmt.Sprintf(filterAfter, len(args)-1, len(args))
+	}
+
+	args = append(args, limit+1)
+	query += fmt.Sprintf(order, len(args))
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return ListPage{}, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return ListPage{}, err
+	}
+	defer rows.Close()
+
+	entries := make([]URLEntry, 0, limit+1)
+	for rows.Next() {
+		var sh string
+		mr, err := scanRecord(rows, &sh)
+		if err != nil {
+			return ListPage{}, err
+		}
+
+		entries = append(entries, URLEntry{ShortURL: sh, MemoryRecord: mr})
+	}
+
+	if err = rows.Err(); err != nil {
+		return ListPage{}, err
+	}
+
+	return newListPage(entries, limit), nil
+}
+
+// scanRecord читает запись о коротком URL из строки результата запроса.
+// Перед полями записи могут быть прочитаны дополнительные столбцы, переданные в prefix.
+func scanRecord(row pgx.Row, prefix ...any) (MemoryRecord, error) {
+	var mr MemoryRecord
+	var d, e *time.Time
+	var p *string
+
+	dest := append(prefix, &mr.LongURL, &mr.User, &mr.Deleted, &d, &e, &p, &mr.Flagged, &mr.CreatedAt)
+	err := row.Scan(dest...)
+	if err != nil {
+		return MemoryRecord{}, err
+	}
+
+	if d != nil {
+		mr.DeletedAt = *d
+	}
+	if e != nil {
+		mr.ExpiresAt = *e
+	}
+	if p != nil {
+		mr.PasswordHash = *p
+	}
+
+	return mr, nil
+}
+
+// DeleteURLs добавляет в очередь на удаление те из заданных коротких URL, которые принадлежат пользователю.
+// Принадлежность коротких URL проверяется в рамках запроса, а само удаление выполняется в отдельном потоке.
+// Возвращает задание на удаление с состоянием каждого короткого URL из запроса.
+func (s *DatabaseStorage) DeleteURLs(ctx context.Context, shortURLs []string, user string) (DeletionJob, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.DeleteURLs(ctx, shortURLs, user)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return DeletionJob{}, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, querySelectDeletionCandidates, shortURLs)
+	if err != nil {
+		return DeletionJob{}, err
+	}
+	defer rows.Close()
+
+	records := make(map[string]MemoryRecord, len(shortURLs))
+	for rows.Next() {
+		var sh string
+		var mr MemoryRecord
+		err = rows.Scan(&sh, &mr.User, &mr.Deleted)
+		if err != nil {
+			return DeletionJob{}, err
+		}
+		records[sh] = mr
+	}
+
+	if err = rows.Err(); err != nil {
+		return DeletionJob{}, err
+	}
+
+	results := newDeletionResults(shortURLs, func(sh string) DeletionStatus {
+		mr, ok := records[sh]
+		return deletionStatus(mr, ok, user)
+	})
+
+	return s.enqueueDeletion(ctx, user, results)
+}
+
+// GetStatistics возвращает статистику сервиса по данным БД: количество сокращённых URL и количество пользователей.
+func (s *DatabaseStorage) GetStatistics(ctx context.Context) (urls int, users int, err error) {
+	if s.pool == nil {
+		return s.MemoryStorage.GetStatistics(ctx)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, querySelectStatistics).Scan(&urls, &users)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return urls, users, nil
+}
+
+// walkShortURLs передаёт функции все короткие URL из БД.
+// Общий для экземпляров сервиса счётчик в БД не ведётся: генерация выполняется внутри транзакции,
+// а получение номера через отдельное соединение могло бы исчерпать пул. Если номер уже занят
+// другим экземпляром сервиса, вставка не выполняется и короткий URL генерируется повторно.
+func (s *DatabaseStorage) walkShortURLs(ctx context.Context, fn func(sh string)) error {
+	if s.pool == nil {
+		return s.MemoryStorage.walkShortURLs(ctx, fn)
+	}
+
+	shortURLs, err := s.queryShortURLs(ctx, querySelectShortURLs)
+	if err != nil {
+		return err
+	}
+
+	for _, sh := range shortURLs {
+		fn(sh)
+	}
+
+	return nil
+}
+
+// queryShortURLs выполняет запрос к БД, возвращающий список коротких URL.
+func (s *DatabaseStorage) queryShortURLs(ctx context.Context, query string, args ...any) ([]string, error) {
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]string, 0)
+	for rows.Next() {
+		var sh string
+		err = rows.Scan(&sh)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sh)
+	}
+
+	return result, rows.Err()
+}
+
+// savePending сохраняет запросы на удаление в таблице очереди на удаление в БД.
+func (s *DatabaseStorage) savePending(ctx context.Context, requests []deletionRequest) error {
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	jobIDs, shortURLs := splitDeletionRequests(requests)
+	_, err = conn.Exec(ctx, queryInsertPendingDeletions, jobIDs, shortURLs)
+	return err
+}
+
+// loadPending возвращает запросы на удаление из таблицы очереди на удаление в БД в порядке их добавления.
+func (s *DatabaseStorage) loadPending(ctx context.Context) ([]deletionRequest, error) {
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, querySelectPendingDeletions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]deletionRequest, 0)
+	for rows.Next() {
+		var r deletionRequest
+		err = rows.Scan(&r.jobID, &r.shortURL)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+
+	return result, rows.Err()
+}
+
+// removePending удаляет обработанные запросы на удаление из таблицы очереди на удаление в БД.
+func (s *DatabaseStorage) removePending(ctx context.Context, requests []deletionRequest) error {
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	jobIDs, shortURLs := splitDeletionRequests(requests)
+	_, err = conn.Exec(ctx, queryDeletePendingDeletions, jobIDs, shortURLs)
+	return err
+}
+
+// splitDeletionRequests разделяет запросы на удаление на массивы идентификаторов заданий и коротких URL для передачи в БД.
+func splitDeletionRequests(requests []deletionRequest) (jobIDs, shortURLs []string) {
+	jobIDs = make([]string, len(requests))
+	shortURLs = make([]string, len(requests))
+	for i, r := range requests {
+		jobIDs[i] = r.jobID
+		shortURLs[i] = r.shortURL
+	}
+
+	return jobIDs, shortURLs
+}
+
+// AddClicks сохраняет пакет событий перехода по коротким URL в БД.
+func (s *DatabaseStorage) AddClicks(ctx context.Context, clicks []Click) error {
+	if s.pool == nil {
+		return s.MemoryStorage.AddClicks(ctx, clicks)
+	}
+
+	batch := &pgx.Batch{}
+	for _, c := range clicks {
+		batch.Queue(queryInsertClick, c.ShortURL, c.Time, c.Referrer, c.UserAgent, c.VisitorHash, c.Country)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	return conn.SendBatch(ctx, batch).Close()
+}
+
+// GetClicks возвращает из БД все события перехода по заданному короткому URL.
+func (s *DatabaseStorage) GetClicks(ctx context.Context, sh string) ([]Click, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.GetClicks(ctx, sh)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, querySelectClicks, sh)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]Click, 0)
+	for rows.Next() {
+		var c Click
+		err = rows.Scan(&c.ShortURL, &c.Time, &c.Referrer, &c.UserAgent, &c.VisitorHash, &c.Country)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+
+	return result, rows.Err()
+}
+
+// MergeUser переназначает в БД все короткие URL пользователя другому пользователю.
+func (s *DatabaseStorage) MergeUser(ctx context.Context, from, to string) (int, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.MergeUser(ctx, from, to)
+	}
+
+	if from == "" || to == "" || from == to {
+		return 0, nil
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	// При поиске дублирующихся URL среди URL пользователя ключ зависит от пользователя, поэтому он снимается:
+	// у нового владельца уже может быть короткий URL для того же исходного длинного URL.
+	query := queryMergeUser
+	if s.dedup == DedupUser {
+		query = queryMergeUserScoped
+	}
+
+	rows, err := conn.Query(ctx, query, from, to)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	merged := 0
+	for rows.Next() {
+		var sh string
+		err = rows.Scan(&sh)
+		if err != nil {
+			return merged, err
+		}
+		s.cache.remove(sh)
+		merged++
+	}
+
+	return merged, rows.Err()
+}
+
+// AddAPIKey сохраняет ключ API в БД.
+func (s *DatabaseStorage) AddAPIKey(ctx context.Context, key APIKey) error {
+	if s.pool == nil {
+		return s.MemoryStorage.AddAPIKey(ctx, key)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, queryInsertAPIKey, key.ID, key.User, key.Name, key.Hash, key.Scope, key.CreatedAt)
+	return err
+}
+
+// GetAPIKey возвращает из БД ключ API с заданным идентификатором.
+func (s *DatabaseStorage) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.GetAPIKey(ctx, id)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return APIKey{}, err
+	}
+	defer conn.Release()
+
+	key, err := scanAPIKey(conn.QueryRow(ctx, querySelectAPIKey, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+
+	return key, err
+}
+
+// ListAPIKeys возвращает из БД все ключи API в порядке их создания.
+func (s *DatabaseStorage) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	if s.pool == nil {
+		return s.MemoryStorage.ListAPIKeys(ctx)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, querySelectAPIKeys+` ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, key)
+	}
+
+	return result, rows.Err()
+}
+
+// RevokeAPIKey отзывает ключ API в БД. Повторный отзыв не меняет момент отзыва.
+func (s *DatabaseStorage) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	if s.pool == nil {
+		return s.MemoryStorage.RevokeAPIKey(ctx, id, at)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ct, err := conn.Exec(ctx, queryRevokeAPIKey, id, at)
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// scanAPIKey считывает ключ API из строки результата запроса.
+func scanAPIKey(row pgx.Row) (APIKey, error) {
+	var key APIKey
+	var revokedAt *time.Time
+
+	err := row.Scan(&key.ID, &key.User, &key.Name, &key.Hash, &key.Scope, &key.CreatedAt, &revokedAt)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	if revokedAt != nil {
+		key.RevokedAt = *revokedAt
+	}
+
+	return key, nil
+}
+
+// passwordHashOrNil возвращает ссылку на хеш пароля или nil для незащищённого короткого URL.
+func passwordHashOrNil(hash string) *string {
+	if hash == "" {
+		return nil
+	}
+
+	return &hash
+}
+
+// acquire получает соединение из пула, ожидая свободное соединение не дольше заданного времени.
+func (s *DatabaseStorage) acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	if s.acquireTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.acquireTimeout)
+		defer cancel()
+	}
+
+	return s.pool.Acquire(ctx)
+}
+
+// CloseFunc возвращает функцию для закрытия пула соединений с БД, используемой для хранения информации о коротких и длинных URL.
 func (s *DatabaseStorage) CloseFunc() func() {
 	return func() {
 		s.DeletionCancel()
-		close(s.deletionQueue)
-		//close(s.errors)
 
-		if s.conn == nil {
+		if s.pool == nil {
 			return
 		}
 
-		ctx := context.Background()
-		err := s.conn.Close(ctx)
-		if err != nil {
-			log.Println(err)
-			return
-		}
+		s.pool.Close()
 	}
 }
 
 // Ping проверяет соединение с БД и выдаёт ошибку, если оно не установлено.
-func (s *DatabaseStorage) Ping() error {
-	if s.conn == nil {
-		return s.MemoryStorage.Ping()
+// Статистика пула соединений выводится в журнал.
+func (s *DatabaseStorage) Ping(ctx context.Context) error {
+	if s.pool == nil {
+		return s.MemoryStorage.Ping(ctx)
+	}
+
+	conn, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	stats, _ := s.PoolStats()
+	log.Printf("Статистика пула соединений с БД: %+v\n", stats)
+
+	return conn.Ping(ctx)
+}
+
+// PoolStats возвращает статистику пула соединений с БД.
+// Если соединение с БД не установлено, возвращается признак отсутствия пула.
+func (s *DatabaseStorage) PoolStats() (PoolStats, bool) {
+	if s.pool == nil {
+		return PoolStats{}, false
 	}
 
-	ctx := context.Background()
-	return s.conn.Ping(ctx)
+	stat := s.pool.Stat()
+	return PoolStats{
+		TotalConns:           stat.TotalConns(),
+		IdleConns:            stat.IdleConns(),
+		AcquiredConns:        stat.AcquiredConns(),
+		MaxConns:             stat.MaxConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		AcquireDuration:      stat.AcquireDuration(),
+	}, true
 }
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// DedupScope задаёт область поиска ранее сокращённых исходных длинных URL.
+type DedupScope string
+
+// Поддерживаемые области поиска ранее сокращённых исходных длинных URL.
+const (
+	DedupGlobal DedupScope = "global" // Один короткий URL на исходный длинный URL для всех пользователей
+	DedupUser   DedupScope = "user"   // Отдельный короткий URL на исходный длинный URL для каждого пользователя
+)
+
+// ErrDedupScopeUnknown возвращается при запросе неизвестной области поиска дублирующихся URL.
+var ErrDedupScopeUnknown = errors.New("неизвестная область поиска дублирующихся URL")
+
+// NewDedupScope проверяет название области поиска дублирующихся URL. По умолчанию поиск выполняется среди всех пользователей.
+func NewDedupScope(scope string) (DedupScope, error) {
+	switch DedupScope(scope) {
+	case "", DedupGlobal:
+		return DedupGlobal, nil
+	case DedupUser:
+		return DedupUser, nil
+	}
+
+	return "", ErrDedupScopeUnknown
+}
+
+// deduplicated проверяет, участвует ли короткий URL с заданными параметрами в поиске дублирующихся URL.
+// Короткие URL, защищённые паролем или с ограниченным сроком действия, создаются по каждому запросу отдельно,
+// чтобы пароль и срок действия не терялись и не передавались другим пользователям того же исходного длинного URL.
+func (o URLOptions) deduplicated() bool {
+	return o.PasswordHash == "" && o.ExpiresAt.IsZero()
+}
+
+// deduplicated проверяет, участвует ли запись в поиске дублирующихся URL, см. URLOptions.deduplicated.
+func (mr MemoryRecord) deduplicated() bool {
+	return URLOptions{ExpiresAt: mr.ExpiresAt, PasswordHash: mr.PasswordHash}.deduplicated()
+}
+
+// deduplicated проверяет, участвует ли сокращаемый в пакете URL в поиске дублирующихся URL, см. URLOptions.deduplicated.
+func (r RecordURL) deduplicated() bool {
+	return URLOptions{ExpiresAt: r.ExpiresAt}.deduplicated()
+}
+
+// dedupKey возвращает ключ обратного индекса исходных длинных URL с учётом области поиска дублирующихся URL.
+func (s *MemoryStorage) dedupKey(l, user string) string {
+	if s.dedup == DedupUser {
+		return user + "\x00" + l
+	}
+
+	return l
+}
+
+// indexLongURL добавляет запись в обратный индекс исходных длинных URL без установки блокировки.
+// Удалённые записи не добавляются, чтобы исходный длинный URL можно было сократить повторно,
+// а записи с паролем или сроком действия - потому что они не участвуют в поиске дублирующихся URL.
+func (s *MemoryStorage) indexLongURL(sh string, mr MemoryRecord) {
+	if mr.Deleted || !mr.deduplicated() {
+		return
+	}
+
+	if s.longURLs == nil {
+		s.longURLs = map[string]string{}
+	}
+	s.longURLs[s.dedupKey(mr.LongURL, mr.User)] = sh
+}
+
+// unindexLongURL удаляет запись из обратного индекса исходных длинных URL без установки блокировки.
+func (s *MemoryStorage) unindexLongURL(sh string, mr MemoryRecord) {
+	key := s.dedupKey(mr.LongURL, mr.User)
+	if s.longURLs[key] == sh {
+		delete(s.longURLs, key)
+	}
+}
+
+// findDuplicate ищет действующий короткий URL, ранее созданный для исходного длинного URL, без установки блокировки.
+func (s *MemoryStorage) findDuplicate(l, user string, now time.Time) (string, bool) {
+	sh, ok := s.longURLs[s.dedupKey(l, user)]
+	if !ok {
+		return "", false
+	}
+
+	mr := s.container[sh]
+	if mr.Deleted || mr.Expired(now) {
+		return "", false
+	}
+
+	return sh, true
+}
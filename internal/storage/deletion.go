@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DeletionJobRetention задаёт срок, в течение которого хранятся сведения о задании на удаление.
+const DeletionJobRetention = time.Hour
+
+// Состояния удаления отдельного короткого URL в задании на удаление.
+const (
+	DeletionQueued         DeletionStatus = "queued"          // Короткий URL в очереди на удаление
+	DeletionDeleted        DeletionStatus = "deleted"         // Короткий URL удалён
+	DeletionNotFound       DeletionStatus = "not_found"       // Короткий URL не существует
+	DeletionForbidden      DeletionStatus = "forbidden"       // Короткий URL принадлежит другому пользователю
+	DeletionAlreadyDeleted DeletionStatus = "already_deleted" // Короткий URL удалён ранее
+	DeletionFailed         DeletionStatus = "failed"          // Ошибка хранилища при удалении короткого URL
+)
+
+// ErrDeletionJobNotFound возвращается, если задание на удаление не существует, устарело или создано другим пользователем.
+var ErrDeletionJobNotFound = errors.New("задание на удаление не найдено")
+
+// Типы данных для отслеживания заданий на удаление.
+type (
+	// DeletionStatus содержит состояние удаления отдельного короткого URL.
+	DeletionStatus string
+
+	// DeletionResult содержит короткий URL из запроса на удаление и состояние его удаления.
+	DeletionResult struct {
+		ShortURL string
+		Status   DeletionStatus
+	}
+
+	// DeletionJob содержит сведения о задании на удаление: идентификатор, пользователя, создавшего задание,
+	// момент создания и состояние удаления каждого короткого URL из запроса.
+	DeletionJob struct {
+		ID        string
+		User      string
+		CreatedAt time.Time
+		Results   []DeletionResult
+	}
+
+	// deletionRequest содержит короткий URL в очереди на удаление и идентификатор задания, в рамках которого он удаляется.
+	deletionRequest struct {
+		jobID    string
+		shortURL string
+	}
+
+	// deletionJobStore хранит задания на удаление и состояние удаления коротких URL в них.
+	deletionJobStore interface {
+		start(ctx context.Context, user string, results []DeletionResult, now time.Time) (DeletionJob, error)
+		finish(ctx context.Context, batch []deletionRequest, status DeletionStatus) error
+		get(ctx context.Context, id, user string) (DeletionJob, error)
+	}
+
+	// deletionJobs хранит задания на удаление в памяти экземпляра сервиса.
+	deletionJobs struct {
+		locker sync.Mutex
+		jobs   map[string]*DeletionJob
+	}
+)
+
+// Done проверяет, что в задании на удаление не осталось коротких URL в очереди.
+func (j DeletionJob) Done() bool {
+	for _, r := range j.Results {
+		if r.Status == DeletionQueued {
+			return false
+		}
+	}
+
+	return true
+}
+
+// copy возвращает копию задания на удаление, не связанную с хранимым заданием.
+func (j *DeletionJob) copy() DeletionJob {
+	result := *j
+	result.Results = append([]DeletionResult(nil), j.Results...)
+	return result
+}
+
+// deletionStatus определяет, может ли пользователь удалить короткий URL, по записи о нём.
+func deletionStatus(mr MemoryRecord, found bool, user string) DeletionStatus {
+	switch {
+	case !found:
+		return DeletionNotFound
+	case mr.User != user:
+		return DeletionForbidden
+	case mr.Deleted:
+		return DeletionAlreadyDeleted
+	default:
+		return DeletionQueued
+	}
+}
+
+// newDeletionResults определяет состояние удаления каждого из неповторяющихся коротких URL запроса.
+func newDeletionResults(shortURLs []string, status func(sh string) DeletionStatus) []DeletionResult {
+	results := make([]DeletionResult, 0, len(shortURLs))
+	seen := make(map[string]bool, len(shortURLs))
+
+	for _, sh := range shortURLs {
+		if seen[sh] {
+			continue
+		}
+		seen[sh] = true
+
+		results = append(results, DeletionResult{ShortURL: sh, Status: status(sh)})
+	}
+
+	return results
+}
+
+// newDeletionJob создаёт задание на удаление со случайным идентификатором.
+func newDeletionJob(user string, results []DeletionResult, now time.Time) (*DeletionJob, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeletionJob{ID: hex.EncodeToString(b), User: user, CreatedAt: now, Results: results}, nil
+}
+
+// setStatus устанавливает состояние удаления короткого URL, если он ещё находится в очереди.
+func (j *DeletionJob) setStatus(sh string, status DeletionStatus) {
+	for i := range j.Results {
+		if j.Results[i].ShortURL == sh && j.Results[i].Status == DeletionQueued {
+			j.Results[i].Status = status
+		}
+	}
+}
+
+func newDeletionJobs() *deletionJobs {
+	return &deletionJobs{jobs: map[string]*DeletionJob{}}
+}
+
+// start регистрирует задание на удаление и удаляет сведения об устаревших заданиях.
+func (d *deletionJobs) start(ctx context.Context, user string, results []DeletionResult, now time.Time) (DeletionJob, error) {
+	job, err := newDeletionJob(user, results, now)
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	for id, j := range d.jobs {
+		if now.Sub(j.CreatedAt) > DeletionJobRetention {
+			delete(d.jobs, id)
+		}
+	}
+	d.jobs[job.ID] = job
+
+	return job.copy(), nil
+}
+
+// finish устанавливает состояние удаления коротких URL из обработанного пакета очереди.
+func (d *deletionJobs) finish(ctx context.Context, batch []deletionRequest, status DeletionStatus) error {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	for _, request := range batch {
+		job, ok := d.jobs[request.jobID]
+		if ok {
+			job.setStatus(request.shortURL, status)
+		}
+	}
+
+	return nil
+}
+
+// get возвращает задание на удаление, созданное заданным пользователем.
+func (d *deletionJobs) get(ctx context.Context, id, user string) (DeletionJob, error) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+
+	job, ok := d.jobs[id]
+	if !ok || job.User != user {
+		return DeletionJob{}, ErrDeletionJobNotFound
+	}
+
+	return job.copy(), nil
+}
+
+// enqueueDeletion регистрирует задание на удаление и добавляет в очередь короткие URL, которые пользователь может удалить.
+// Запросы сохраняются во внешнем хранилище очереди, если оно задано, а само удаление выполняется в отдельном потоке.
+func (s *MemoryStorage) enqueueDeletion(ctx context.Context, user string, results []DeletionResult) (DeletionJob, error) {
+	job, err := s.deletionJobs.start(ctx, user, results, time.Now())
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	requests := make([]deletionRequest, 0, len(job.Results))
+	for _, r := range job.Results {
+		if r.Status == DeletionQueued {
+			requests = append(requests, deletionRequest{jobID: job.ID, shortURL: r.ShortURL})
+		}
+	}
+
+	err = s.deletionQueue.push(ctx, requests)
+	if err != nil {
+		finishJobs(ctx, s.deletionJobs, requests, DeletionFailed)
+		return DeletionJob{}, err
+	}
+
+	return job, nil
+}
+
+// GetDeletionJob возвращает состояние задания на удаление, созданного пользователем.
+// Сведения о задании хранятся в течение DeletionJobRetention в памяти экземпляра сервиса, принявшего запрос на удаление,
+// а в хранилище на Redis-совместимом сервере - на сервере, чтобы они были доступны всем экземплярам сервиса.
+func (s *MemoryStorage) GetDeletionJob(ctx context.Context, id, user string) (DeletionJob, error) {
+	return s.deletionJobs.get(ctx, id, user)
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ExpirationCheckInterval задаёт периодичность поиска и удаления коротких URL с истёкшим сроком действия.
+const ExpirationCheckInterval = time.Minute
+
+// ErrExpirationInvalid возвращается при некорректно заданном сроке действия короткого URL.
+var ErrExpirationInvalid = errors.New("некорректный срок действия короткого URL")
+
+// NewExpiration вычисляет момент окончания срока действия короткого URL
+// по абсолютному времени или по времени жизни в секундах, отсчитываемому от now.
+// Если не задано ни то, ни другое, возвращается нулевое время, означающее бессрочный URL.
+func NewExpiration(expiresAt time.Time, ttlSeconds int64, now time.Time) (time.Time, error) {
+	if !expiresAt.IsZero() && ttlSeconds != 0 {
+		return time.Time{}, fmt.Errorf("%w: нельзя одновременно задавать expires_at и ttl_seconds", ErrExpirationInvalid)
+	}
+
+	if ttlSeconds < 0 {
+		return time.Time{}, fmt.Errorf("%w: время жизни не может быть отрицательным", ErrExpirationInvalid)
+	}
+
+	if ttlSeconds > 0 {
+		return now.Add(time.Duration(ttlSeconds) * time.Second), nil
+	}
+
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		return time.Time{}, fmt.Errorf("%w: момент окончания срока действия уже наступил", ErrExpirationInvalid)
+	}
+
+	return expiresAt, nil
+}
+
+// Expired проверяет, истёк ли к заданному моменту срок действия короткого URL.
+func (r MemoryRecord) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !r.ExpiresAt.After(now)
+}
+
+// expired возвращает короткие URL из хранилища в памяти, срок действия которых истёк, но которые ещё не удалены.
+func (s *MemoryStorage) expired(now time.Time) []string {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+
+	result := make([]string, 0)
+	for sh, mr := range s.container {
+		if !mr.Deleted && mr.Expired(now) {
+			result = append(result, sh)
+		}
+	}
+
+	return result
+}
+
+// ExpirationProcess периодически помечает удалёнными короткие URL с истёкшим сроком действия в хранилище в памяти.
+func (s *MemoryStorage) ExpirationProcess(ctx context.Context) {
+	go expirationProcess(ctx, s, s.expired, ExpirationCheckInterval)
+}
+
+func expirationProcess(ctx context.Context, d deleter, expired func(time.Time) []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			batch := expired(now)
+			if len(batch) == 0 {
+				continue
+			}
+
+			log.Println("Найдено коротких URL с истёкшим сроком действия:", len(batch))
+			err := d.delete(ctx, batch)
+			if err != nil {
+				log.Println("Ошибка при удалении коротких URL с истёкшим сроком действия:", err)
+			}
+		}
+	}
+}
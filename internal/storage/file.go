@@ -1,45 +1,191 @@
 package storage
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
 	"log"
 	"os"
+	"sync"
+	"time"
 )
 
+// clicksFileSuffix задаёт суффикс имени файла, в котором хранятся события перехода по коротким URL.
+const clicksFileSuffix = ".clicks"
+
 type fileStorage struct {
 	*MemoryStorage
-	file    *os.File
-	decoder *json.Decoder
-	encoder *json.Encoder
+	filePath       string
+	file           *os.File
+	fileLocker     sync.Mutex
+	lines          int
+	encoder        *json.Encoder
+	clicksFile     *os.File
+	clicksEncoder  *json.Encoder
+	apiKeysFile    *os.File
+	apiKeysEncoder *json.Encoder
+	compaction     CompactionOptions
+	journal        *deletionJournal
 }
 
 // Record описывает структуру отдельной записи хранилища в файле.
 type Record struct {
-	ShortURL string `json:"short_url"`         // Короткий URL
-	LongURL  string `json:"long_url"`          // Исходный длинный URL
-	Deleted  bool   `json:"deleted,omitempty"` // Признак удаления записи
-	UserID   string `json:"user_id"`           // Идентификатор пользователя, добавившего исходный длинный URL
+	ShortURL     string     `json:"short_url"`               // Короткий URL
+	LongURL      string     `json:"long_url"`                // Исходный длинный URL
+	Deleted      bool       `json:"deleted,omitempty"`       // Признак удаления записи
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`    // Момент удаления записи
+	UserID       string     `json:"user_id"`                 // Идентификатор пользователя, добавившего исходный длинный URL
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`    // Момент окончания срока действия короткого URL
+	PasswordHash string     `json:"password_hash,omitempty"` // Хеш пароля, которым защищён короткий URL
+	Flagged      bool       `json:"flagged,omitempty"`       // Пометка о подозрительной странице назначения
+	CreatedAt    *time.Time `json:"created_at,omitempty"`    // Момент создания короткого URL
+	Checksum     uint32     `json:"crc,omitempty"`           // Контрольная сумма CRC-32 записи без этого поля
+}
+
+// ErrRecordCorrupted возвращается, если запись в файле хранилища повреждена не в последней строке.
+var ErrRecordCorrupted = errors.New("повреждена запись в файле хранилища")
+
+// newRecord создаёт запись хранилища в файле по записи хранилища в памяти.
+func newRecord(sh string, mr MemoryRecord) Record {
+	return Record{
+		ShortURL:     sh,
+		LongURL:      mr.LongURL,
+		Deleted:      mr.Deleted,
+		DeletedAt:    timeOrNil(mr.DeletedAt),
+		UserID:       mr.User,
+		ExpiresAt:    timeOrNil(mr.ExpiresAt),
+		PasswordHash: mr.PasswordHash,
+		Flagged:      mr.Flagged,
+		CreatedAt:    timeOrNil(mr.CreatedAt),
+	}
+}
+
+// checksum вычисляет контрольную сумму записи без учёта поля Checksum.
+func (r Record) checksum() (uint32, error) {
+	r.Checksum = 0
+	content, err := json.Marshal(r)
+	if err != nil {
+		return 0, err
+	}
+
+	return crc32.ChecksumIEEE(content), nil
+}
+
+// valid проверяет контрольную сумму записи. Записи без контрольной суммы, сохранённые прежними версиями сервиса, считаются верными.
+func (r Record) valid() bool {
+	if r.Checksum == 0 {
+		return true
+	}
+
+	sum, err := r.checksum()
+	return err == nil && sum == r.Checksum
+}
+
+// encodeRecord записывает запись в файл хранилища в виде одной строки JSON с контрольной суммой.
+func encodeRecord(encoder *json.Encoder, r *Record) error {
+	sum, err := r.checksum()
+	if err != nil {
+		return err
+	}
+
+	r.Checksum = sum
+	return encoder.Encode(r)
+}
+
+// memoryRecord преобразует запись хранилища в формат записи хранилища в памяти.
+func (r Record) memoryRecord() MemoryRecord {
+	mr := MemoryRecord{LongURL: r.LongURL, User: r.UserID, Deleted: r.Deleted, PasswordHash: r.PasswordHash, Flagged: r.Flagged}
+	if r.ExpiresAt != nil {
+		mr.ExpiresAt = *r.ExpiresAt
+	}
+	if r.DeletedAt != nil {
+		mr.DeletedAt = *r.DeletedAt
+	}
+	if r.CreatedAt != nil {
+		mr.CreatedAt = *r.CreatedAt
+	}
+
+	return mr
 }
 
-func newFileStorage(m *MemoryStorage, filePath string) *fileStorage {
-	storage := &fileStorage{m, nil, nil, nil}
+// newFileStorage создаёт хранилище в файле и загружает из него ранее сохранённые записи.
+// Оборванная последняя строка файла, оставшаяся после сбоя во время записи, удаляется,
+// а повреждение в середине файла или в снимке считается ошибкой: продолжение записи в такой файл
+// скрыло бы потерю данных, поэтому файл нужно восстановить вручную.
+func newFileStorage(m *MemoryStorage, filePath string) (*fileStorage, error) {
+	storage := &fileStorage{MemoryStorage: m}
 
 	if filePath == "" {
-		return storage
+		return storage, nil
 	}
 
-	err := storage.openFile(filePath)
+	storage.filePath = filePath
+
+	err := storage.loadSnapshot(filePath + snapshotFileSuffix)
 	if err != nil {
-		log.Println(err)
-		return storage
+		return nil, fmt.Errorf("не удалось загрузить снимок хранилища %s: %w", filePath+snapshotFileSuffix, err)
+	}
+
+	err = storage.openFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл хранилища %s: %w", filePath, err)
 	}
 
 	err = storage.loadFromFile()
+	if err != nil {
+		if closeErr := storage.file.Close(); closeErr != nil {
+			log.Println(closeErr)
+		}
+		return nil, fmt.Errorf("не удалось загрузить файл хранилища %s: %w", filePath, err)
+	}
+
+	err = storage.openClicksFile(filePath + clicksFileSuffix)
+	if err != nil {
+		log.Println(err)
+	}
+
+	err = storage.openAPIKeysFile(filePath + apiKeysFileSuffix)
+	if err != nil {
+		log.Println(err)
+	}
+
+	storage.journal, err = openDeletionJournal(filePath + deletionJournalSuffix)
 	if err != nil {
 		log.Println(err)
+	} else {
+		storage.deletionQueue.outbox = storage.journal
 	}
 
-	return storage
+	return storage, nil
+}
+
+// openClicksFile открывает файл событий перехода по коротким URL и загружает из него ранее сохранённые события.
+func (s *fileStorage) openClicksFile(f string) error {
+	var err error
+
+	s.clicksFile, err = os.OpenFile(f, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0777)
+	if err != nil {
+		return err
+	}
+
+	s.clicksEncoder = json.NewEncoder(s.clicksFile)
+
+	decoder := json.NewDecoder(s.clicksFile)
+	for decoder.More() {
+		var click Click
+		err = decoder.Decode(&click)
+		if err != nil {
+			return err
+		}
+
+		s.MemoryStorage.addClicks([]Click{click})
+	}
+
+	return nil
 }
 
 func (s *fileStorage) openFile(f string) error {
@@ -50,58 +196,151 @@ func (s *fileStorage) openFile(f string) error {
 		return err
 	}
 
-	s.decoder = json.NewDecoder(s.file)
 	s.encoder = json.NewEncoder(s.file)
 
 	return nil
 }
 
+// loadFromFile загружает записи из основного файла хранилища.
+// Если последняя строка файла записана не полностью или повреждена, например из-за сбоя во время записи,
+// файл усекается до последней целой строки.
 func (s *fileStorage) loadFromFile() error {
-	if s.decoder == nil {
+	if s.file == nil {
 		return nil
 	}
 
-	r := new(Record)
-	for s.decoder.More() {
-		err := s.decoder.Decode(r)
-		if err != nil {
-			return err
+	valid, err := s.loadRecords(s.file)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	log.Println("Последняя строка файла", s.file.Name(), "повреждена и будет удалена:", err)
+	return s.file.Truncate(valid)
+}
+
+// loadSnapshot загружает записи из файла снимка хранилища, если он существует.
+func (s *fileStorage) loadSnapshot(f string) error {
+	snapshot, err := os.Open(f)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+
+	_, err = s.loadRecords(snapshot)
+	return err
+}
+
+// loadRecords загружает записи в хранилище в памяти. Более поздняя запись о коротком URL заменяет более раннюю.
+// Возвращает размер в байтах успешно прочитанной части. Если повреждена последняя строка,
+// возвращается ошибка io.ErrUnexpectedEOF, а если строка в середине - ErrRecordCorrupted.
+func (s *fileStorage) loadRecords(r io.Reader) (int64, error) {
+	reader := bufio.NewReader(r)
+	var valid int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			return valid, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return valid, err
+		}
+
+		record := new(Record)
+		decodeErr := json.Unmarshal(line, record)
+		if decodeErr == nil && !record.valid() {
+			decodeErr = errors.New("неверная контрольная сумма")
 		}
-		if r.ShortURL == "" || r.LongURL == "" {
-			continue
+
+		if errors.Is(err, io.EOF) {
+			if decodeErr == nil {
+				decodeErr = errors.New("строка не завершена")
+			}
+			return valid, fmt.Errorf("%w: %v", io.ErrUnexpectedEOF, decodeErr)
 		}
-		s.container[r.ShortURL] = MemoryRecord{LongURL: r.LongURL, Deleted: r.Deleted, User: r.UserID}
 
-		if r.UserID == "" {
-			continue
+		if decodeErr != nil {
+			_, peekErr := reader.Peek(1)
+			if errors.Is(peekErr, io.EOF) {
+				return valid, fmt.Errorf("%w: %v", io.ErrUnexpectedEOF, decodeErr)
+			}
+			return valid, fmt.Errorf("%w: смещение %d: %v", ErrRecordCorrupted, valid, decodeErr)
 		}
-		s.usersURLs[r.UserID] = append(s.usersURLs[r.UserID], r.ShortURL)
+
+		valid += int64(len(line))
+		s.lines++
+		s.loadRecord(record)
 	}
+}
 
-	return nil
+// loadRecord добавляет запись в хранилище в памяти.
+// Удалённая запись без исходного длинного URL, оставшаяся после сжатия файла, только занимает короткий URL.
+func (s *fileStorage) loadRecord(r *Record) {
+	if r.ShortURL == "" || (r.LongURL == "" && !r.Deleted) {
+		return
+	}
+
+	previous, exists := s.container[r.ShortURL]
+	if exists {
+		s.unindexLongURL(r.ShortURL, previous)
+	}
+
+	mr := r.memoryRecord()
+	s.container[r.ShortURL] = mr
+	s.indexLongURL(r.ShortURL, mr)
+
+	if exists {
+		if previous.User != r.UserID {
+			s.moveUserURL(r.ShortURL, previous.User, r.UserID)
+		}
+		return
+	}
+
+	if r.UserID == "" {
+		return
+	}
+	s.usersURLs[r.UserID] = append(s.usersURLs[r.UserID], r.ShortURL)
 }
 
-func (s *fileStorage) saveToFile(r *Record) error {
+// saveToFile дописывает записи в основной файл хранилища.
+func (s *fileStorage) saveToFile(records ...*Record) error {
+	s.fileLocker.Lock()
+	defer s.fileLocker.Unlock()
+
 	if s.encoder == nil {
 		return nil
 	}
 
-	err := s.encoder.Encode(r)
-	if err != nil {
-		return err
+	for _, r := range records {
+		err := encodeRecord(s.encoder, r)
+		if err != nil {
+			return err
+		}
+		s.lines++
 	}
 
 	return nil
 }
 
 // AddURL добавляет исходный длинный URL в хранилище в файле, связывая его с созданным коротким URL.
-func (s *fileStorage) AddURL(l, user string) (string, error) {
-	sh, err := s.MemoryStorage.AddURL(l, user)
+// Если исходный длинный URL уже сокращён, возвращается ранее созданный короткий URL и ошибка дублирования.
+func (s *fileStorage) AddURL(ctx context.Context, l, user string, opts URLOptions) (string, error) {
+	sh, err := s.MemoryStorage.AddURL(ctx, l, user, opts)
+	if errors.Is(err, DBErrorDublicate) {
+		return sh, err
+	}
 	if err != nil {
 		return "", err
 	}
 
-	err = s.saveToFile(&Record{ShortURL: sh, LongURL: l, Deleted: false, UserID: user})
+	s.locker.RLock()
+	record := newRecord(sh, s.container[sh])
+	s.locker.RUnlock()
+
+	err = s.saveToFile(&record)
 	if err != nil {
 		return sh, err
 	}
@@ -110,46 +349,100 @@ func (s *fileStorage) AddURL(l, user string) (string, error) {
 }
 
 // AddURLs добавляет несколько исходных длинных URL в хранилище в файле, связывая их с соответствующими созданными короткими URL.
-func (s *fileStorage) AddURLs(longURLs BatchURLs, user string) (BatchURLs, error) {
+// Для ранее сокращённых исходных длинных URL возвращаются существующие короткие URL с признаком дублирования.
+func (s *fileStorage) AddURLs(ctx context.Context, longURLs BatchURLs, user string) (BatchURLs, error) {
 	result := make(BatchURLs, 0, len(longURLs))
 	for _, longURL := range longURLs {
-		sh, err := s.AddURL(longURL.URL, user)
-		if err != nil {
-			return result[:0], err
-		}
-
-		err = s.saveToFile(&Record{ShortURL: sh, LongURL: longURL.URL, Deleted: false, UserID: user})
-		if err != nil {
+		sh, err := s.AddURL(ctx, longURL.URL, user, URLOptions{ExpiresAt: longURL.ExpiresAt})
+		duplicate := errors.Is(err, DBErrorDublicate)
+		if err != nil && !duplicate {
 			return result[:0], err
 		}
 
-		result = append(result, RecordURL{ID: longURL.ID, URL: sh})
+		result = append(result, RecordURL{ID: longURL.ID, URL: sh, ExpiresAt: longURL.ExpiresAt, Duplicate: duplicate})
 	}
 
 	return result, nil
 }
 
-// DeleteURLs добавляет заданные короткие URL в очередь на удаление из хранилища в файле.
-func (s *fileStorage) DeleteURLs(shortURLs []string, user string) (deleted []string) {
-	deleted = s.MemoryStorage.DeleteURLs(shortURLs, user)
+// timeOrNil возвращает ссылку на заданный момент времени или nil для нулевого значения,
+// например для бессрочного короткого URL.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
 
-	for _, sh := range deleted {
-		err := s.saveToFile(&Record{ShortURL: sh, LongURL: s.container[sh].LongURL, Deleted: true, UserID: user})
-		if err != nil {
-			log.Println("Ошибка при записи удалённой ссылки с id", sh, "в файл")
+	return &t
+}
+
+// AddClicks сохраняет пакет событий перехода в файл и в хранилище в памяти.
+func (s *fileStorage) AddClicks(ctx context.Context, clicks []Click) error {
+	if s.clicksEncoder != nil {
+		for i := range clicks {
+			err := s.clicksEncoder.Encode(&clicks[i])
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	return deleted
+	return s.MemoryStorage.AddClicks(ctx, clicks)
+}
+
+// DeletionQueueProcess обрабатывает очередь запросов на удаление в отдельном потоке.
+func (s *fileStorage) DeletionQueueProcess(ctx context.Context) {
+	deletionQueueProcess(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// DrainDeletionQueue останавливает обработку очереди на удаление и удаляет оставшиеся в ней короткие URL.
+func (s *fileStorage) DrainDeletionQueue(ctx context.Context) error {
+	return drainDeletionQueue(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// ExpirationProcess периодически помечает удалёнными короткие URL с истёкшим сроком действия.
+func (s *fileStorage) ExpirationProcess(ctx context.Context) {
+	go expirationProcess(ctx, s, s.expired, ExpirationCheckInterval)
+}
+
+// delete дописывает в файл записи о коротких URL, помеченных удалёнными, и только после этого помечает их удалёнными
+// в хранилище в памяти, чтобы удаление сохранилось после перезапуска сервиса, а при ошибке записи было повторено.
+func (s *fileStorage) delete(ctx context.Context, deletionBatch []string) error {
+	return s.MemoryStorage.markDeleted(deletionBatch, s.saveToFile)
 }
 
 // CloseFunc возвращает функцию для закрытия файла, используемого для хранения информации о коротких и длинных URL.
 func (s *fileStorage) CloseFunc() func() {
 	return func() {
+		if s.DeletionCancel != nil {
+			s.DeletionCancel()
+		}
+
+		if s.clicksFile != nil {
+			if err := s.clicksFile.Close(); err != nil {
+				log.Println(err)
+			}
+		}
+
+		if s.apiKeysFile != nil {
+			if err := s.apiKeysFile.Close(); err != nil {
+				log.Println(err)
+			}
+		}
+
+		if s.journal != nil {
+			if err := s.journal.close(); err != nil {
+				log.Println(err)
+			}
+		}
+
+		s.fileLocker.Lock()
+		defer s.fileLocker.Unlock()
+
 		if s.file == nil {
 			return
 		}
 
+		s.encoder = nil
 		err := s.file.Close()
 		if err != nil {
 			log.Println(err)
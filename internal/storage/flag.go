@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrURLNotFound возвращается, если короткий URL, который требуется изменить, не существует.
+var ErrURLNotFound = errors.New("короткий URL не найден")
+
+// Flagger обеспечивает пометку коротких URL, ведущих на подозрительные страницы.
+// При переходе по помеченному короткому URL вместо перенаправления показывается предупреждение.
+type Flagger interface {
+	FlagURL(ctx context.Context, sh string, flagged bool) error
+}
+
+// FlagURL устанавливает или снимает пометку короткого URL в хранилище в памяти.
+func (s *MemoryStorage) FlagURL(ctx context.Context, sh string, flagged bool) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.flagURL(sh, flagged, nil)
+}
+
+// flagURL устанавливает или снимает пометку короткого URL без установки блокировки.
+// Если задана функция save, изменённая запись сохраняется ею до изменения хранилища в памяти.
+func (s *MemoryStorage) flagURL(sh string, flagged bool, save func(...*Record) error) error {
+	mr, ok := s.container[sh]
+	if !ok {
+		return ErrURLNotFound
+	}
+
+	mr.Flagged = flagged
+	if save != nil {
+		record := newRecord(sh, mr)
+		err := save(&record)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.container[sh] = mr
+	return nil
+}
+
+// FlagURL устанавливает или снимает пометку короткого URL и записывает изменение в файл хранилища.
+// Изменение записывается в файл под блокировкой хранилища и применяется в памяти только после успешной записи.
+func (s *fileStorage) FlagURL(ctx context.Context, sh string, flagged bool) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.flagURL(sh, flagged, s.saveToFile)
+}
@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Названия поддерживаемых способов генерации коротких URL.
+const (
+	GeneratorTime     = "time"     // Время создания в base36 и два случайных байта
+	GeneratorRandom   = "random"   // Криптографически случайная строка в base62
+	GeneratorSequence = "sequence" // Порядковый номер, закодированный перемешанным алфавитом
+	GeneratorHash     = "hash"     // Хеш исходного длинного URL в base62
+)
+
+const (
+	// DefaultGeneratedLength задаёт длину короткого URL по умолчанию для генераторов с настраиваемой длиной.
+	DefaultGeneratedLength = 8
+	// MinGeneratedLength и MaxGeneratedLength ограничивают настраиваемую длину короткого URL.
+	// Более короткие URL быстро исчерпывают попытки генерации, а более длинные не помещаются
+	// в столбец short_url базы данных (varchar(32)).
+	MinGeneratedLength = 4
+	MaxGeneratedLength = 32
+	// hashChunkLength задаёт длину в base62 одного 8-байтового фрагмента хеша.
+	hashChunkLength = 11
+	// maxGenerationAttempts задаёт количество попыток генерации короткого URL при совпадении с существующим.
+	maxGenerationAttempts = 10
+
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	// sequenceMultiplier и sequenceMask задают взаимно однозначное перемешивание порядкового номера,
+	// чтобы соседние номера не давали похожие короткие URL.
+	sequenceMultiplier = 0x5DEECE66D
+	sequenceMask       = 1<<40 - 1
+)
+
+// sequenceInverse содержит обратный к sequenceMultiplier элемент по модулю 2^40,
+// с помощью которого по короткому URL восстанавливается порядковый номер.
+var sequenceInverse = inverseOdd(sequenceMultiplier)
+
+// Типы данных для генерации коротких URL.
+type (
+	// Generator создаёт короткий URL для заданного длинного URL.
+	// Номер попытки позволяет детерминированным генераторам выдавать другой результат при совпадении.
+	Generator interface {
+		Generate(longURL string, attempt int) (string, error)
+	}
+
+	// sequenceSeeder позволяет продолжить нумерацию после загрузки уже сохранённых записей
+	// и получать порядковые номера из общего для экземпляров сервиса источника.
+	sequenceSeeder interface {
+		Seed(n uint64)
+		Position(sh string) (uint64, bool)
+		UseSource(next func() (uint64, error))
+	}
+
+	// sequenceSource выдаёт порядковые номера, общие для всех экземпляров сервиса, работающих с одним хранилищем.
+	// Метод raiseSequence поднимает счётчик до заданного значения, если он меньше.
+	sequenceSource interface {
+		nextSequence(ctx context.Context) (uint64, error)
+		raiseSequence(ctx context.Context, n uint64) error
+	}
+
+	// shortURLWalker перебирает все короткие URL в хранилище, в том числе удалённые и псевдонимы.
+	shortURLWalker interface {
+		walkShortURLs(ctx context.Context, fn func(sh string)) error
+	}
+
+	// TimeGenerator создаёт короткий URL из текущего времени в base36 и двух случайных байт.
+	TimeGenerator struct{}
+
+	// RandomGenerator создаёт криптографически случайный короткий URL в base62 заданной длины.
+	RandomGenerator struct {
+		Length int
+	}
+
+	// SequenceGenerator создаёт короткий URL из порядкового номера, закодированного перемешанным по соли алфавитом.
+	SequenceGenerator struct {
+		counter  uint64
+		alphabet string
+		next     func() (uint64, error)
+	}
+
+	// HashGenerator создаёт короткий URL из SHA-256 хеша исходного длинного URL в base62 заданной длины.
+	HashGenerator struct {
+		Length int
+	}
+)
+
+// ErrGeneratorUnknown возвращается при запросе неизвестного способа генерации коротких URL.
+var ErrGeneratorUnknown = errors.New("неизвестный способ генерации коротких URL")
+
+// ErrGeneratedLengthInvalid возвращается, если длина короткого URL выходит за допустимые пределы.
+var ErrGeneratedLengthInvalid = errors.New("длина короткого URL должна быть от " + strconv.Itoa(MinGeneratedLength) + " до " + strconv.Itoa(MaxGeneratedLength) + " символов")
+
+// errGenerationExhausted возвращается, если все попытки генерации дали уже существующие короткие URL.
+var errGenerationExhausted = errors.New("не удалось сгенерировать уникальный короткий URL за " + strconv.Itoa(maxGenerationAttempts) + " попыток")
+
+// NewGenerator создаёт генератор коротких URL по его названию.
+// Длина используется случайным и хеширующим генераторами, соль - последовательным.
+// Нулевая длина означает длину по умолчанию.
+func NewGenerator(kind string, length int, salt string) (Generator, error) {
+	if length == 0 {
+		length = DefaultGeneratedLength
+	}
+	if length < MinGeneratedLength || length > MaxGeneratedLength {
+		return nil, fmt.Errorf("%w: %d", ErrGeneratedLengthInvalid, length)
+	}
+
+	switch kind {
+	case "", GeneratorTime:
+		return TimeGenerator{}, nil
+	case GeneratorRandom:
+		return RandomGenerator{Length: length}, nil
+	case GeneratorSequence:
+		return NewSequenceGenerator(salt), nil
+	case GeneratorHash:
+		return HashGenerator{Length: length}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrGeneratorUnknown, kind)
+}
+
+// Generate создаёт короткий URL из текущего времени и случайных байт.
+func (g TimeGenerator) Generate(_ string, _ int) (string, error) {
+	result := strconv.FormatInt(time.Now().UnixMicro(), 36)
+
+	b := make([]byte, 2)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return result + hex.EncodeToString(b), nil
+}
+
+// Generate создаёт случайный короткий URL в base62.
+func (g RandomGenerator) Generate(_ string, _ int) (string, error) {
+	max := big.NewInt(int64(len(base62Alphabet)))
+
+	result := make([]byte, g.Length)
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = base62Alphabet[n.Int64()]
+	}
+
+	return string(result), nil
+}
+
+// NewSequenceGenerator создаёт последовательный генератор, алфавит которого перемешан в зависимости от соли.
+func NewSequenceGenerator(salt string) *SequenceGenerator {
+	return &SequenceGenerator{alphabet: shuffleAlphabet(base62Alphabet, salt)}
+}
+
+// Seed устанавливает начальное значение счётчика, например, по наибольшему порядковому номеру среди сохранённых записей.
+func (g *SequenceGenerator) Seed(n uint64) {
+	atomic.StoreUint64(&g.counter, n)
+}
+
+// UseSource задаёт общий для экземпляров сервиса источник порядковых номеров вместо счётчика в памяти.
+// Устанавливается до начала генерации коротких URL.
+func (g *SequenceGenerator) UseSource(next func() (uint64, error)) {
+	g.next = next
+}
+
+// Generate создаёт короткий URL из следующего порядкового номера.
+func (g *SequenceGenerator) Generate(_ string, _ int) (string, error) {
+	var n uint64
+	if g.next != nil {
+		var err error
+		n, err = g.next()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		n = atomic.AddUint64(&g.counter, 1)
+	}
+	n = (n * sequenceMultiplier) & sequenceMask
+
+	return encode(n, g.alphabet), nil
+}
+
+// Position возвращает порядковый номер, из которого получен короткий URL.
+// Если короткий URL не мог быть создан генератором, возвращается false.
+func (g *SequenceGenerator) Position(sh string) (uint64, bool) {
+	if sh == "" {
+		return 0, false
+	}
+
+	base := uint64(len(g.alphabet))
+	var v, weight uint64 = 0, 1
+	for i := 0; i < len(sh); i++ {
+		digit := strings.IndexByte(g.alphabet, sh[i])
+		if digit < 0 || weight > sequenceMask {
+			return 0, false
+		}
+
+		v += uint64(digit) * weight
+		if v > sequenceMask {
+			return 0, false
+		}
+		weight *= base
+	}
+
+	if encode(v, g.alphabet) != sh {
+		return 0, false
+	}
+
+	return (v * sequenceInverse) & sequenceMask, true
+}
+
+// seedSequence продолжает нумерацию последовательного генератора после наибольшего порядкового номера
+// среди сохранённых коротких URL. Количество записей для этого не подходит: после удаления записей
+// номер, равный количеству, может быть уже занят. Псевдонимы, похожие на созданные генератором короткие URL,
+// тоже учитываются, поэтому нумерация может уйти вперёд, но не повторит выданный номер.
+// Если хранилище может выдавать общие для экземпляров сервиса порядковые номера, генератор получает номера из него.
+func seedSequence(ctx context.Context, s Storager, seeder sequenceSeeder) error {
+	walker, ok := s.(shortURLWalker)
+	if !ok {
+		return nil
+	}
+
+	var last uint64
+	err := walker.walkShortURLs(ctx, func(sh string) {
+		if n, ok := seeder.Position(sh); ok && n > last {
+			last = n
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	seeder.Seed(last)
+
+	source, ok := s.(sequenceSource)
+	if !ok {
+		return nil
+	}
+
+	err = source.raiseSequence(ctx, last)
+	if err != nil {
+		return err
+	}
+
+	seeder.UseSource(func() (uint64, error) {
+		return source.nextSequence(ctx)
+	})
+
+	return nil
+}
+
+// Generate создаёт короткий URL из хеша длинного URL. При повторных попытках к URL добавляется номер попытки.
+// Короткий URL составляется из последовательных 8-байтовых фрагментов хеша, каждый из которых
+// дополняется до одинаковой длины, поэтому четырёх фрагментов хватает для MaxGeneratedLength.
+func (g HashGenerator) Generate(longURL string, attempt int) (string, error) {
+	data := longURL
+	if attempt > 0 {
+		data += "#" + strconv.Itoa(attempt)
+	}
+
+	sum := sha256.Sum256([]byte(data))
+	var result strings.Builder
+	for i := 0; i+8 <= len(sum) && result.Len() < g.Length; i += 8 {
+		chunk := encode(binary.BigEndian.Uint64(sum[i:i+8]), base62Alphabet)
+		result.WriteString(chunk)
+		result.WriteString(strings.Repeat(base62Alphabet[:1], hashChunkLength-len(chunk)))
+	}
+
+	return result.String()[:g.Length], nil
+}
+
+// inverseOdd вычисляет обратный элемент нечётного числа по модулю 2^64 методом Ньютона.
+func inverseOdd(a uint64) uint64 {
+	x := a
+	for i := 0; i < 6; i++ {
+		x *= 2 - a*x
+	}
+
+	return x
+}
+
+// encode переводит число в строку в системе счисления, заданной алфавитом.
+func encode(n uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	if n == 0 {
+		return alphabet[:1]
+	}
+
+	result := make([]byte, 0, 11)
+	for n > 0 {
+		result = append(result, alphabet[n%base])
+		n /= base
+	}
+
+	return string(result)
+}
+
+// shuffleAlphabet детерминированно перемешивает алфавит в зависимости от соли, как это делается в hashids.
+func shuffleAlphabet(alphabet, salt string) string {
+	result := []byte(alphabet)
+	if salt == "" {
+		return string(result)
+	}
+
+	for i, v, p := len(result)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		result[i], result[j] = result[j], result[i]
+		v++
+	}
+
+	return string(result)
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+)
+
+// deletionJournalSuffix задаёт суффикс имени файла журнала очереди на удаление.
+const deletionJournalSuffix = ".deletions"
+
+// Типы данных для журнала очереди на удаление хранилища в файле.
+type (
+	// journalEntry описывает строку журнала очереди на удаление: запрос, добавленный в очередь, или отметку о его обработке.
+	journalEntry struct {
+		JobID    string `json:"job_id,omitempty"` // Идентификатор задания на удаление
+		ShortURL string `json:"short_url"`        // Короткий URL, подлежащий удалению
+		Done     bool   `json:"done,omitempty"`   // Признак обработки запроса
+	}
+
+	// deletionJournal сохраняет очередь на удаление в файле в виде журнала добавленных и обработанных запросов.
+	// Когда необработанных запросов не остаётся, журнал очищается.
+	deletionJournal struct {
+		locker  sync.Mutex
+		file    *os.File
+		encoder *json.Encoder
+		pending map[deletionRequest]bool
+		loaded  []deletionRequest
+	}
+)
+
+// openDeletionJournal открывает файл журнала очереди на удаление, загружает из него необработанные запросы
+// и перезаписывает журнал, оставляя в нём только их.
+func openDeletionJournal(f string) (*deletionJournal, error) {
+	file, err := os.OpenFile(f, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0777)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &deletionJournal{file: file, encoder: json.NewEncoder(file), pending: map[deletionRequest]bool{}}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry journalEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			log.Println("Пропущена повреждённая строка журнала очереди на удаление", f, ":", err)
+			continue
+		}
+
+		r := deletionRequest{jobID: entry.JobID, shortURL: entry.ShortURL}
+		if entry.Done {
+			delete(j.pending, r)
+			continue
+		}
+
+		if !j.pending[r] {
+			j.pending[r] = true
+			j.loaded = append(j.loaded, r)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	loaded := make([]deletionRequest, 0, len(j.pending))
+	for _, r := range j.loaded {
+		if j.pending[r] {
+			loaded = append(loaded, r)
+		}
+	}
+	j.loaded = loaded
+
+	err = j.rewrite()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// rewrite перезаписывает журнал, оставляя в нём только необработанные запросы.
+func (j *deletionJournal) rewrite() error {
+	err := j.file.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range j.loaded {
+		err = j.encoder.Encode(journalEntry{JobID: r.jobID, ShortURL: r.shortURL})
+		if err != nil {
+			return err
+		}
+	}
+
+	return j.file.Sync()
+}
+
+// savePending дописывает запросы на удаление в журнал.
+func (j *deletionJournal) savePending(ctx context.Context, requests []deletionRequest) error {
+	j.locker.Lock()
+	defer j.locker.Unlock()
+
+	for _, r := range requests {
+		err := j.encoder.Encode(journalEntry{JobID: r.jobID, ShortURL: r.shortURL})
+		if err != nil {
+			return err
+		}
+		j.pending[r] = true
+	}
+
+	return j.file.Sync()
+}
+
+// loadPending возвращает необработанные запросы на удаление, загруженные из журнала при его открытии.
+func (j *deletionJournal) loadPending(ctx context.Context) ([]deletionRequest, error) {
+	j.locker.Lock()
+	defer j.locker.Unlock()
+
+	loaded := j.loaded
+	j.loaded = nil
+
+	return loaded, nil
+}
+
+// removePending дописывает в журнал отметки об обработке запросов на удаление.
+// Если необработанных запросов не осталось, журнал очищается.
+func (j *deletionJournal) removePending(ctx context.Context, requests []deletionRequest) error {
+	j.locker.Lock()
+	defer j.locker.Unlock()
+
+	for _, r := range requests {
+		delete(j.pending, r)
+	}
+
+	if len(j.pending) == 0 {
+		return j.file.Truncate(0)
+	}
+
+	for _, r := range requests {
+		err := j.encoder.Encode(journalEntry{JobID: r.jobID, ShortURL: r.shortURL, Done: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	return j.file.Sync()
+}
+
+// close закрывает файл журнала очереди на удаление.
+func (j *deletionJournal) close() error {
+	j.locker.Lock()
+	defer j.locker.Unlock()
+
+	return j.file.Close()
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ограничения размера страницы списка коротких URL пользователя.
+const (
+	DefaultListLimit = 100  // Размер страницы, если он не задан в запросе
+	MaxListLimit     = 1000 // Максимальный размер страницы
+)
+
+// Фильтры списка коротких URL пользователя по состоянию.
+// Короткий URL с истёкшим сроком действия считается истёкшим и после того, как обработчик истёкших коротких URL
+// пометил его удалённым; удалённым считается только короткий URL, удалённый до истечения срока действия.
+const (
+	StatusAll     URLStatus = ""        // Все короткие URL пользователя, кроме удалённых
+	StatusActive  URLStatus = "active"  // Не удалённые короткие URL, срок действия которых не истёк
+	StatusDeleted URLStatus = "deleted" // Удалённые короткие URL
+	StatusExpired URLStatus = "expired" // Короткие URL с истёкшим сроком действия
+)
+
+// Порядок сортировки списка коротких URL пользователя по моменту создания.
+const (
+	OrderDesc SortOrder = "desc" // Сначала созданные позже
+	OrderAsc  SortOrder = "asc"  // Сначала созданные раньше
+)
+
+// Ошибки проверки параметров запроса списка коротких URL пользователя.
+var (
+	ErrInvalidCursor = errors.New("неверный курсор страницы списка URL")
+	ErrInvalidLimit  = errors.New("неверный размер страницы списка URL")
+	ErrInvalidStatus = errors.New("неверный фильтр состояния URL, допустимы: active, deleted, expired")
+	ErrInvalidOrder  = errors.New("неверный порядок сортировки URL, допустимы: asc, desc")
+)
+
+// Типы данных для постраничного получения коротких URL пользователя.
+type (
+	// URLStatus задаёт фильтр списка коротких URL по их состоянию.
+	URLStatus string
+
+	// SortOrder задаёт порядок сортировки списка коротких URL по моменту создания.
+	SortOrder string
+
+	// ListQuery содержит параметры запроса страницы списка коротких URL пользователя.
+	// Курсор, возвращённый с предыдущей страницей, продолжает список с того же места
+	// и действителен только при тех же порядке сортировки, фильтре и строке поиска.
+	ListQuery struct {
+		Limit  int       // Размер страницы
+		Cursor string    // Курсор, указывающий на последний короткий URL предыдущей страницы
+		Order  SortOrder // Порядок сортировки по моменту создания
+		Status URLStatus // Фильтр по состоянию
+		Search string    // Подстрока исходного длинного URL без учёта регистра
+	}
+
+	// URLEntry содержит короткий URL и запись о нём в хранилище.
+	URLEntry struct {
+		ShortURL string
+		MemoryRecord
+	}
+
+	// ListPage содержит страницу списка коротких URL пользователя и курсор следующей страницы.
+	// Если следующей страницы нет, курсор пустой.
+	ListPage struct {
+		URLs       []URLEntry
+		NextCursor string
+	}
+
+	// cursor содержит позицию в списке коротких URL, отсортированном по моменту создания и короткому URL.
+	cursor struct {
+		createdAt time.Time
+		shortURL  string
+	}
+)
+
+// NewListQuery проверяет параметры запроса страницы списка коротких URL пользователя
+// и подставляет значения по умолчанию для незаданных параметров.
+func NewListQuery(limit int, cursor, order, status, search string) (ListQuery, error) {
+	q := ListQuery{Limit: limit, Cursor: cursor, Order: SortOrder(order), Status: URLStatus(status), Search: search}
+
+	if q.Limit < 0 || q.Limit > MaxListLimit {
+		return ListQuery{}, ErrInvalidLimit
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultListLimit
+	}
+
+	switch q.Order {
+	case "":
+		q.Order = OrderDesc
+	case OrderAsc, OrderDesc:
+	default:
+		return ListQuery{}, ErrInvalidOrder
+	}
+
+	switch q.Status {
+	case StatusAll, StatusActive, StatusDeleted, StatusExpired:
+	default:
+		return ListQuery{}, ErrInvalidStatus
+	}
+
+	if _, err := decodeCursor(q.Cursor); err != nil {
+		return ListQuery{}, err
+	}
+
+	return q, nil
+}
+
+// status определяет состояние короткого URL для фильтра списка.
+// Запись без момента удаления, например сохранённая до его появления, считается удалённой пользователем.
+func (r MemoryRecord) status(now time.Time) URLStatus {
+	switch {
+	case r.Deleted && (r.ExpiresAt.IsZero() || r.DeletedAt.IsZero() || r.DeletedAt.Before(r.ExpiresAt)):
+		return StatusDeleted
+	case r.Expired(now):
+		return StatusExpired
+	default:
+		return StatusActive
+	}
+}
+
+// match проверяет, что короткий URL соответствует фильтру состояния и строке поиска запроса.
+func (q ListQuery) match(e URLEntry, now time.Time) bool {
+	status := e.status(now)
+	if status != q.Status && (q.Status != StatusAll || status == StatusDeleted) {
+		return false
+	}
+
+	return q.Search == "" || strings.Contains(strings.ToLower(e.LongURL), strings.ToLower(q.Search))
+}
+
+// less сравнивает короткие URL по моменту создания, а при совпадении - по самому короткому URL.
+func (c cursor) less(other cursor) bool {
+	if !c.createdAt.Equal(other.createdAt) {
+		return c.createdAt.Before(other.createdAt)
+	}
+
+	return c.shortURL < other.shortURL
+}
+
+// follows проверяет, что позиция c следует за позицией previous в порядке сортировки запроса.
+func (q ListQuery) follows(c, previous cursor) bool {
+	if q.Order == OrderAsc {
+		return previous.less(c)
+	}
+
+	return c.less(previous)
+}
+
+// encodeCursor формирует курсор, указывающий на короткий URL.
+func encodeCursor(e URLEntry) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(e.CreatedAt.UTC().Format(time.RFC3339Nano) + " " + e.ShortURL))
+}
+
+// decodeCursor разбирает курсор. Для пустого курсора возвращается nil.
+func decodeCursor(value string) (*cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, sh, found := strings.Cut(string(raw), " ")
+	if !found || sh == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor{createdAt: t, shortURL: sh}, nil
+}
+
+// paginate отбирает короткие URL по фильтру запроса, сортирует их и возвращает страницу, следующую за курсором.
+func paginate(entries []URLEntry, q ListQuery, now time.Time) (ListPage, error) {
+	after, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return ListPage{}, err
+	}
+
+	matched := make([]URLEntry, 0, len(entries))
+	for _, e := range entries {
+		if !q.match(e, now) {
+			continue
+		}
+
+		if after != nil && !q.follows(cursor{e.CreatedAt, e.ShortURL}, *after) {
+			continue
+		}
+
+		matched = append(matched, e)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return q.follows(cursor{matched[j].CreatedAt, matched[j].ShortURL}, cursor{matched[i].CreatedAt, matched[i].ShortURL})
+	})
+
+	return newListPage(matched, q.Limit), nil
+}
+
+// newListPage формирует страницу из не более чем limit коротких URL.
+// Если коротких URL больше, возвращается курсор, указывающий на последний из них на странице.
+func newListPage(entries []URLEntry, limit int) ListPage {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	if len(entries) <= limit {
+		return ListPage{URLs: entries}
+	}
+
+	entries = entries[:limit]
+	return ListPage{URLs: entries, NextCursor: encodeCursor(entries[len(entries)-1])}
+}
+
+// ListURLs возвращает страницу списка коротких URL пользователя из хранилища в памяти.
+func (s *MemoryStorage) ListURLs(ctx context.Context, user string, q ListQuery) (ListPage, error) {
+	s.locker.RLock()
+	entries := make([]URLEntry, 0, len(s.usersURLs[user]))
+	for _, sh := range s.usersURLs[user] {
+		if mr, ok := s.container[sh]; ok {
+			entries = append(entries, URLEntry{ShortURL: sh, MemoryRecord: mr})
+		}
+	}
+	s.locker.RUnlock()
+
+	return paginate(entries, q, time.Now())
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultRestoreGracePeriod задаёт срок, в течение которого пользователь может восстановить удалённый короткий URL.
+const DefaultRestoreGracePeriod = 24 * time.Hour
+
+// Ошибки изменения и восстановления коротких URL пользователем.
+var (
+	ErrNotOwner             = errors.New("короткий URL принадлежит другому пользователю")
+	ErrURLDeleted           = errors.New("короткий URL удалён")
+	ErrURLNotDeleted        = errors.New("короткий URL не удалён")
+	ErrURLExpired           = errors.New("срок действия короткого URL истёк")
+	ErrRestorePeriodExpired = errors.New("срок восстановления удалённого короткого URL истёк")
+)
+
+// updatable проверяет, что пользователь может изменить исходный длинный URL записи о коротком URL.
+func updatable(mr MemoryRecord, user string, now time.Time) error {
+	if mr.User != user {
+		return ErrNotOwner
+	}
+
+	if mr.Deleted {
+		return ErrURLDeleted
+	}
+
+	if mr.Expired(now) {
+		return ErrURLExpired
+	}
+
+	return nil
+}
+
+// restorable проверяет, что пользователь может восстановить удалённый короткий URL.
+// Восстановить можно только короткий URL, удалённый не ранее чем за grace до момента now, срок действия которого не истёк.
+func restorable(mr MemoryRecord, user string, now time.Time, grace time.Duration) error {
+	if mr.User != user {
+		return ErrNotOwner
+	}
+
+	if !mr.Deleted {
+		return ErrURLNotDeleted
+	}
+
+	if mr.Expired(now) {
+		return ErrURLExpired
+	}
+
+	if mr.DeletedAt.IsZero() || now.Sub(mr.DeletedAt) > grace {
+		return ErrRestorePeriodExpired
+	}
+
+	return nil
+}
+
+// restoreGracePeriod возвращает срок восстановления удалённых коротких URL, заданный для хранилища, или срок по умолчанию.
+func (s *MemoryStorage) restoreGracePeriod() time.Duration {
+	if s.restoreGrace <= 0 {
+		return DefaultRestoreGracePeriod
+	}
+
+	return s.restoreGrace
+}
+
+// UpdateURL заменяет исходный длинный URL короткого URL, принадлежащего пользователю, в хранилище в памяти.
+// Если новый исходный длинный URL уже сокращён, возвращается ошибка дублирования.
+func (s *MemoryStorage) UpdateURL(ctx context.Context, sh, l, user string) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.updateURL(sh, l, user, time.Now(), nil)
+}
+
+// updateURL заменяет исходный длинный URL без установки блокировки.
+// Если задана функция save, изменённая запись сохраняется ею до изменения хранилища в памяти.
+func (s *MemoryStorage) updateURL(sh, l, user string, now time.Time, save func(...*Record) error) error {
+	mr, ok := s.container[sh]
+	if !ok {
+		return ErrURLNotFound
+	}
+
+	err := updatable(mr, user, now)
+	if err != nil {
+		return err
+	}
+
+	if mr.LongURL == l {
+		return nil
+	}
+
+	if existing, ok := s.findDuplicate(l, user, now); ok && existing != sh && mr.deduplicated() {
+		return NewStorageDBError(l, true, nil)
+	}
+
+	updated := mr
+	updated.LongURL = l
+	if save != nil {
+		record := newRecord(sh, updated)
+		err = save(&record)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.unindexLongURL(sh, mr)
+	s.container[sh] = updated
+	s.indexLongURL(sh, updated)
+
+	return nil
+}
+
+// RestoreURL отменяет удаление короткого URL, принадлежащего пользователю, в хранилище в памяти.
+// Если исходный длинный URL за это время сокращён повторно, возвращается ошибка дублирования.
+func (s *MemoryStorage) RestoreURL(ctx context.Context, sh, user string) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.restoreURL(sh, user, time.Now(), nil)
+}
+
+// restoreURL отменяет удаление короткого URL без установки блокировки.
+// Если задана функция save, изменённая запись сохраняется ею до изменения хранилища в памяти.
+func (s *MemoryStorage) restoreURL(sh, user string, now time.Time, save func(...*Record) error) error {
+	mr, ok := s.container[sh]
+	if !ok {
+		return ErrURLNotFound
+	}
+
+	err := restorable(mr, user, now, s.restoreGracePeriod())
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.findDuplicate(mr.LongURL, mr.User, now); ok && mr.deduplicated() {
+		return NewStorageDBError(mr.LongURL, true, nil)
+	}
+
+	mr.Deleted = false
+	mr.DeletedAt = time.Time{}
+	if save != nil {
+		record := newRecord(sh, mr)
+		err = save(&record)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.container[sh] = mr
+	s.indexLongURL(sh, mr)
+
+	return nil
+}
+
+// UpdateURL заменяет исходный длинный URL короткого URL, принадлежащего пользователю, и записывает изменение в файл хранилища.
+// Изменение записывается в файл под блокировкой хранилища и применяется в памяти только после успешной записи.
+func (s *fileStorage) UpdateURL(ctx context.Context, sh, l, user string) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.updateURL(sh, l, user, time.Now(), s.saveToFile)
+}
+
+// RestoreURL отменяет удаление короткого URL, принадлежащего пользователю, и записывает изменение в файл хранилища.
+// Изменение записывается в файл под блокировкой хранилища и применяется в памяти только после успешной записи.
+func (s *fileStorage) RestoreURL(ctx context.Context, sh, user string) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.restoreURL(sh, user, time.Now(), s.saveToFile)
+}
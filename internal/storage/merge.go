@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+)
+
+// UserMerger обеспечивает перенос коротких URL одного пользователя другому,
+// например анонимного пользователя пользователю, выполнившему вход через внешнего провайдера.
+type UserMerger interface {
+	// MergeUser переназначает все короткие URL пользователя from пользователю to и возвращает их количество.
+	MergeUser(ctx context.Context, from, to string) (int, error)
+}
+
+// MergeUser переназначает короткие URL пользователя другому пользователю в хранилище в памяти.
+func (s *MemoryStorage) MergeUser(ctx context.Context, from, to string) (int, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return len(s.mergeUser(from, to)), nil
+}
+
+// mergeUser переназначает короткие URL пользователя без установки блокировки и возвращает изменённые записи.
+// Если при поиске дублирующихся URL среди URL пользователя у нового владельца уже есть короткий URL
+// для того же исходного длинного URL, обратный индекс продолжает указывать на него.
+func (s *MemoryStorage) mergeUser(from, to string) []Record {
+	if from == "" || to == "" || from == to {
+		return nil
+	}
+
+	merged := make([]Record, 0, len(s.usersURLs[from]))
+	for _, sh := range s.usersURLs[from] {
+		mr, ok := s.container[sh]
+		if !ok {
+			continue
+		}
+
+		s.unindexLongURL(sh, mr)
+		mr.User = to
+		s.container[sh] = mr
+		if _, taken := s.longURLs[s.dedupKey(mr.LongURL, to)]; !taken {
+			s.indexLongURL(sh, mr)
+		}
+
+		s.usersURLs[to] = append(s.usersURLs[to], sh)
+		merged = append(merged, newRecord(sh, mr))
+	}
+	delete(s.usersURLs, from)
+
+	return merged
+}
+
+// moveUserURL переносит короткий URL из списка URL одного пользователя в список другого без установки блокировки.
+func (s *MemoryStorage) moveUserURL(sh, from, to string) {
+	urls := s.usersURLs[from]
+	for i := range urls {
+		if urls[i] == sh {
+			urls = append(urls[:i], urls[i+1:]...)
+			break
+		}
+	}
+
+	if len(urls) == 0 {
+		delete(s.usersURLs, from)
+	} else {
+		s.usersURLs[from] = urls
+	}
+
+	if to != "" {
+		s.usersURLs[to] = append(s.usersURLs[to], sh)
+	}
+}
+
+// MergeUser переназначает короткие URL пользователя другому пользователю и дописывает изменённые записи в файл хранилища.
+func (s *fileStorage) MergeUser(ctx context.Context, from, to string) (int, error) {
+	s.locker.Lock()
+	merged := s.mergeUser(from, to)
+	s.locker.Unlock()
+
+	records := make([]*Record, len(merged))
+	for i := range merged {
+		records[i] = &merged[i]
+	}
+
+	return len(merged), s.saveToFile(records...)
+}
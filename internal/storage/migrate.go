@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationsLockKey задаёт ключ рекомендательной блокировки в БД,
+// которая не позволяет нескольким экземплярам сервиса применять миграции одновременно.
+const migrationsLockKey = 0x73687572
+
+const (
+	queryCreateMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS public.schema_migrations
+		(
+			version integer NOT NULL,
+			name character varying COLLATE pg_catalog."default" NOT NULL,
+			applied_at timestamp with time zone NOT NULL DEFAULT now(),
+
+			CONSTRAINT schema_migrations_pkey PRIMARY KEY (version)
+		)
+	TABLESPACE pg_default;`
+
+	querySelectMigrations = `SELECT version, applied_at FROM schema_migrations`
+	queryInsertMigration  = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+	queryDeleteMigration  = `DELETE FROM schema_migrations WHERE version = $1`
+	queryLockMigrations   = `SELECT pg_advisory_lock($1)`
+	queryUnlockMigrations = `SELECT pg_advisory_unlock($1)`
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrMigrationInvalid возвращается, если набор файлов миграций схемы БД составлен неверно.
+var ErrMigrationInvalid = errors.New("неверный набор миграций схемы БД")
+
+// Типы данных для миграций схемы БД.
+type (
+	// Migration содержит SQL-запросы для применения и отката одной версии схемы БД.
+	Migration struct {
+		Version int    // Номер версии схемы
+		Name    string // Название миграции
+		Up      string // Запрос для применения миграции
+		Down    string // Запрос для отката миграции
+	}
+
+	// MigrationStatus содержит сведения о применении миграции к БД.
+	MigrationStatus struct {
+		Migration
+		Applied   bool      // Признак применения миграции
+		AppliedAt time.Time // Момент применения миграции
+	}
+
+	// Migrator применяет и откатывает миграции схемы БД, встроенные в приложение.
+	Migrator struct {
+		pool       *pgxpool.Pool
+		ownPool    bool
+		migrations []Migration
+	}
+)
+
+// NewMigrator создаёт обработчик миграций, использующий переданный пул соединений с БД.
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// ConnectMigrator подключается к БД и создаёт обработчик миграций.
+// После использования обработчик нужно закрыть методом Close.
+func ConnectMigrator(ctx context.Context, database string) (*Migrator, error) {
+	pool, err := pgxpool.New(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := NewMigrator(pool)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	m.ownPool = true
+	return m, nil
+}
+
+// Close закрывает пул соединений с БД, если он был открыт обработчиком миграций.
+func (m *Migrator) Close() {
+	if m.ownPool {
+		m.pool.Close()
+	}
+}
+
+// loadMigrations читает пары файлов миграций вида 0001_name.up.sql и 0001_name.down.sql
+// и возвращает миграции, упорядоченные по номеру версии.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, ".sql")
+		direction := base[strings.LastIndex(base, ".")+1:]
+		base = strings.TrimSuffix(base, "."+direction)
+
+		number, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("%w: неверное имя файла %s", ErrMigrationInvalid, fileName)
+		}
+
+		content, err := fs.ReadFile(fsys, dir+"/"+fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+
+		if m.Name != name {
+			return nil, fmt.Errorf("%w: разные названия миграции с номером %d", ErrMigrationInvalid, version)
+		}
+
+		switch direction {
+		case "up":
+			m.Up = string(content)
+		case "down":
+			m.Down = string(content)
+		default:
+			return nil, fmt.Errorf("%w: неверное направление в имени файла %s", ErrMigrationInvalid, fileName)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("%w: для миграции %d нет файла применения или отката", ErrMigrationInvalid, m.Version)
+		}
+		result = append(result, *m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	for i, m := range result {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("%w: пропущена миграция с номером %d", ErrMigrationInvalid, i+1)
+		}
+	}
+
+	return result, nil
+}
+
+// Up применяет к БД все ещё не применённые миграции и возвращает их список.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied := make([]Migration, 0)
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, status map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := status[migration.Version]; ok {
+				continue
+			}
+
+			err := m.apply(ctx, conn, migration, migration.Up, queryInsertMigration, migration.Version, migration.Name)
+			if err != nil {
+				return err
+			}
+
+			log.Println("Применена миграция схемы БД", migration.Version, migration.Name)
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down откатывает последнюю применённую миграцию и возвращает её.
+// Если ни одна миграция не применена, возвращается nil.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, status map[int]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := status[migration.Version]; !ok {
+				continue
+			}
+
+			err := m.apply(ctx, conn, migration, migration.Down, queryDeleteMigration, migration.Version)
+			if err != nil {
+				return err
+			}
+
+			log.Println("Откачена миграция схемы БД", migration.Version, migration.Name)
+			reverted = &migration
+			return nil
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status возвращает сведения о применении каждой миграции к БД.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	result := make([]MigrationStatus, 0, len(m.migrations))
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, status map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			appliedAt, ok := status[migration.Version]
+			result = append(result, MigrationStatus{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// withLock получает рекомендательную блокировку миграций в БД, создаёт при необходимости таблицу
+// применённых миграций и вызывает обработчик, передавая ему соединение и моменты применения миграций по номерам версий.
+func (m *Migrator) withLock(ctx context.Context, f func(*pgxpool.Conn, map[int]time.Time) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, queryLockMigrations, migrationsLockKey)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_, err1 := conn.Exec(context.Background(), queryUnlockMigrations, migrationsLockKey)
+		if err1 != nil {
+			log.Println("Ошибка при снятии блокировки миграций схемы БД:", err1)
+		}
+	}()
+
+	_, err = conn.Exec(ctx, queryCreateMigrationsTable)
+	if err != nil {
+		return err
+	}
+
+	status, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return f(conn, status)
+}
+
+// applied возвращает моменты применения миграций по номерам версий.
+func (m *Migrator) applied(ctx context.Context, conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(ctx, querySelectMigrations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	status := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+		status[version] = appliedAt
+	}
+
+	return status, rows.Err()
+}
+
+// apply выполняет запрос миграции и изменяет запись о ней в таблице применённых миграций в одной транзакции.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, migration Migration, query string, recordQuery string, args ...any) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("ошибка в миграции %d %s: %w", migration.Version, migration.Name, err)
+	}
+
+	_, err = tx.Exec(ctx, recordQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
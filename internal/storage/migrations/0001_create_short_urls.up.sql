@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS public.short_urls
+	(
+		short_url character varying(14) COLLATE pg_catalog."default" NOT NULL,
+		long_url character varying COLLATE pg_catalog."default" NOT NULL,
+		user_id character varying COLLATE pg_catalog."default",
+		deleted boolean NOT NULL DEFAULT false,
+
+		CONSTRAINT short_urls_pkey PRIMARY KEY (short_url)
+	)
+TABLESPACE pg_default;
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_long_url
+	ON public.short_urls USING btree
+(	long_url COLLATE pg_catalog."default" ASC NULLS LAST,
+	deleted  ASC NULLS LAST	)
+TABLESPACE pg_default;
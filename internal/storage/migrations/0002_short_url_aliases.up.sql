@@ -0,0 +1,2 @@
+ALTER TABLE public.short_urls
+	ALTER COLUMN short_url TYPE character varying(32);
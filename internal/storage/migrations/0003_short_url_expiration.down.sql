@@ -0,0 +1,2 @@
+ALTER TABLE public.short_urls
+	DROP COLUMN IF EXISTS expires_at;
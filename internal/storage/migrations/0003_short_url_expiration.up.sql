@@ -0,0 +1,2 @@
+ALTER TABLE public.short_urls
+	ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS public.clicks
+	(
+		id bigserial NOT NULL,
+		short_url character varying(32) COLLATE pg_catalog."default" NOT NULL,
+		clicked_at timestamp with time zone NOT NULL,
+		referrer character varying COLLATE pg_catalog."default",
+		user_agent character varying COLLATE pg_catalog."default",
+		visitor_hash character varying(64) COLLATE pg_catalog."default",
+		country character varying(2) COLLATE pg_catalog."default",
+
+		CONSTRAINT clicks_pkey PRIMARY KEY (id)
+	)
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS clicks_short_url
+	ON public.clicks USING btree
+(	short_url COLLATE pg_catalog."default" ASC NULLS LAST,
+	clicked_at ASC NULLS LAST	)
+TABLESPACE pg_default;
@@ -0,0 +1,2 @@
+ALTER TABLE public.short_urls
+	ADD COLUMN IF NOT EXISTS password_hash character varying COLLATE pg_catalog."default";
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS public.short_urls_long_url;
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_long_url
+	ON public.short_urls USING btree
+(	long_url COLLATE pg_catalog."default" ASC NULLS LAST,
+	deleted  ASC NULLS LAST	)
+TABLESPACE pg_default;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS public.unique_long_url;
+
+CREATE INDEX IF NOT EXISTS short_urls_long_url
+	ON public.short_urls USING btree
+(	long_url COLLATE pg_catalog."default" ASC NULLS LAST,
+	user_id  COLLATE pg_catalog."default" ASC NULLS LAST	)
+	WHERE deleted = false
+TABLESPACE pg_default;
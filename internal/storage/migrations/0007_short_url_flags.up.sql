@@ -0,0 +1,2 @@
+ALTER TABLE public.short_urls
+	ADD COLUMN IF NOT EXISTS flagged boolean NOT NULL DEFAULT false;
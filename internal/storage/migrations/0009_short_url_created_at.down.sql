@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.short_urls_user_created_at;
+
+ALTER TABLE public.short_urls
+	DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,9 @@
+ALTER TABLE public.short_urls
+	ADD COLUMN IF NOT EXISTS created_at timestamp with time zone NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS short_urls_user_created_at
+	ON public.short_urls USING btree
+(	user_id    COLLATE pg_catalog."default" ASC NULLS LAST,
+	created_at ASC NULLS LAST,
+	short_url  COLLATE pg_catalog."default" ASC NULLS LAST	)
+TABLESPACE pg_default;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS public.pending_deletions
+	(
+		id bigserial NOT NULL,
+		job_id character varying(32) COLLATE pg_catalog."default" NOT NULL,
+		short_url character varying(32) COLLATE pg_catalog."default" NOT NULL,
+		queued_at timestamp with time zone NOT NULL DEFAULT now(),
+
+		CONSTRAINT pending_deletions_pkey PRIMARY KEY (id)
+	)
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS pending_deletions_job_short_url
+	ON public.pending_deletions USING btree
+(	job_id    COLLATE pg_catalog."default" ASC NULLS LAST,
+	short_url COLLATE pg_catalog."default" ASC NULLS LAST	)
+TABLESPACE pg_default;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS public.api_keys
+	(
+		id character varying(32) COLLATE pg_catalog."default" NOT NULL,
+		user_id character varying(32) COLLATE pg_catalog."default" NOT NULL,
+		name character varying COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+		hash character varying(64) COLLATE pg_catalog."default" NOT NULL,
+		scope character varying(16) COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+		created_at timestamp with time zone NOT NULL DEFAULT now(),
+		revoked_at timestamp with time zone,
+
+		CONSTRAINT api_keys_pkey PRIMARY KEY (id)
+	)
+TABLESPACE pg_default;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.unique_dedup_key;
+
+ALTER TABLE public.short_urls
+	DROP COLUMN IF EXISTS dedup_key;
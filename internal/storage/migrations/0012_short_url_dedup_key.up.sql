@@ -0,0 +1,8 @@
+ALTER TABLE public.short_urls
+	ADD COLUMN IF NOT EXISTS dedup_key character varying(64) COLLATE pg_catalog."default";
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_dedup_key
+	ON public.short_urls USING btree
+(	dedup_key COLLATE pg_catalog."default" ASC NULLS LAST	)
+	WHERE dedup_key IS NOT NULL
+TABLESPACE pg_default;
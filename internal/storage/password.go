@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordInvalid возвращается при попытке защитить короткий URL недопустимым паролем.
+var ErrPasswordInvalid = errors.New("недопустимый пароль для короткого URL")
+
+// HashPassword создаёт bcrypt-хеш пароля для защиты короткого URL.
+func HashPassword(password string) (string, error) {
+	if len(password) > 72 {
+		return "", ErrPasswordInvalid
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// Protected проверяет, защищён ли короткий URL паролем.
+func (r MemoryRecord) Protected() bool {
+	return r.PasswordHash != ""
+}
+
+// CheckPassword проверяет, совпадает ли переданный пароль с паролем, которым защищён короткий URL.
+func (r MemoryRecord) CheckPassword(password string) bool {
+	if !r.Protected() {
+		return true
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(r.PasswordHash), []byte(password)) == nil
+}
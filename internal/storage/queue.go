@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Настройки очереди на удаление по умолчанию.
+const (
+	DefaultDeletionBatchSize     = 20                     // Максимальный размер пакета для массового удаления данных
+	DefaultDeletionFlushInterval = 100 * time.Millisecond // Периодичность удаления неполного пакета
+	DeletionDrainTimeout         = 10 * time.Second       // Максимальное время удаления оставшихся в очереди URL при остановке сервиса
+	DeletionMaxAttempts          = 5                      // Количество попыток удалить пакет, после которого удаление считается неуспешным
+	DeletionMaxBackoff           = 30 * time.Second       // Максимальная пауза перед повторной попыткой удалить пакет
+)
+
+// Типы данных для очереди на удаление.
+type (
+	// DeletionOptions содержит настройки обработки очереди на удаление.
+	// Нулевые значения означают использование настроек по умолчанию.
+	DeletionOptions struct {
+		BatchSize     int           // Максимальный размер пакета для массового удаления
+		FlushInterval time.Duration // Периодичность удаления неполного пакета
+	}
+
+	// DeletionDrainer обеспечивает удаление коротких URL, оставшихся в очереди, при остановке сервиса.
+	DeletionDrainer interface {
+		DrainDeletionQueue(context.Context) error
+	}
+
+	// deletionOutbox сохраняет очередь на удаление вне памяти сервиса,
+	// чтобы не обработанные к остановке сервиса запросы были обработаны после его перезапуска.
+	deletionOutbox interface {
+		savePending(context.Context, []deletionRequest) error
+		loadPending(context.Context) ([]deletionRequest, error)
+		removePending(context.Context, []deletionRequest) error
+	}
+
+	// deletionQueue содержит запросы на удаление, ожидающие обработки.
+	// Если задано внешнее хранилище очереди, запрос сохраняется в нём до добавления в очередь
+	// и удаляется из него только после удаления короткого URL.
+	// Пакет, который не удалось удалить, возвращается в начало очереди и обрабатывается повторно после паузы,
+	// которая удваивается с каждой неудачной попыткой.
+	deletionQueue struct {
+		locker   sync.Mutex
+		requests []deletionRequest
+		failures int
+		retryAt  time.Time
+		full     chan struct{}
+		cancel   context.CancelFunc
+		workers  sync.WaitGroup
+		outbox   deletionOutbox
+		options  DeletionOptions
+	}
+)
+
+func newDeletionQueue(opts DeletionOptions) *deletionQueue {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultDeletionBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultDeletionFlushInterval
+	}
+
+	return &deletionQueue{full: make(chan struct{}, 1), options: opts}
+}
+
+// push добавляет запросы в очередь, не дожидаясь их обработки.
+// Если задано внешнее хранилище очереди, ошибка сохранения в нём возвращается, а запросы в очередь не добавляются.
+func (q *deletionQueue) push(ctx context.Context, requests []deletionRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	if q.outbox != nil {
+		err := q.outbox.savePending(ctx, requests)
+		if err != nil {
+			return err
+		}
+	}
+
+	q.append(requests)
+	return nil
+}
+
+// append добавляет запросы в очередь в памяти и сообщает обработчику о заполнении пакета.
+func (q *deletionQueue) append(requests []deletionRequest) {
+	q.locker.Lock()
+	q.requests = append(q.requests, requests...)
+	full := len(q.requests) >= q.options.BatchSize
+	q.locker.Unlock()
+
+	if full {
+		select {
+		case q.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take извлекает из очереди пакет запросов.
+func (q *deletionQueue) take() []deletionRequest {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	n := len(q.requests)
+	if n > q.options.BatchSize {
+		n = q.options.BatchSize
+	}
+
+	batch := append([]deletionRequest(nil), q.requests[:n]...)
+	q.requests = q.requests[n:]
+	if len(q.requests) == 0 {
+		q.requests = nil
+	}
+
+	return batch
+}
+
+// retry возвращает пакет в начало очереди и откладывает его повторную обработку.
+// Если исчерпаны все DeletionMaxAttempts попыток, пакет в очередь не возвращается и возвращается false.
+func (q *deletionQueue) retry(batch []deletionRequest, now time.Time) bool {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	q.failures++
+	if q.failures >= DeletionMaxAttempts {
+		q.failures = 0
+		q.retryAt = time.Time{}
+		return false
+	}
+
+	backoff := q.options.FlushInterval << (q.failures - 1)
+	if backoff > DeletionMaxBackoff {
+		backoff = DeletionMaxBackoff
+	}
+	q.retryAt = now.Add(backoff)
+	q.requests = append(append([]deletionRequest(nil), batch...), q.requests...)
+
+	return true
+}
+
+// succeeded сбрасывает счётчик неудачных попыток после успешного удаления пакета.
+func (q *deletionQueue) succeeded() {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	q.failures = 0
+	q.retryAt = time.Time{}
+}
+
+// waiting проверяет, не истекла ли пауза перед повторной попыткой удалить пакет.
+func (q *deletionQueue) waiting(now time.Time) bool {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	return now.Before(q.retryAt)
+}
+
+// len возвращает количество запросов в очереди.
+func (q *deletionQueue) len() int {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	return len(q.requests)
+}
+
+// restore добавляет в очередь запросы, сохранённые во внешнем хранилище очереди до перезапуска сервиса.
+func (q *deletionQueue) restore(ctx context.Context) error {
+	if q.outbox == nil {
+		return nil
+	}
+
+	requests, err := q.outbox.loadPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(requests) > 0 {
+		log.Println("Восстановлено запросов на удаление из сохранённой очереди:", len(requests))
+		q.append(requests)
+	}
+
+	return nil
+}
+
+// flush удаляет пакетами все короткие URL из очереди. При ошибке удаления пакета обработка очереди прерывается.
+func (q *deletionQueue) flush(ctx context.Context, d deleter, jobs deletionJobStore) error {
+	for {
+		batch := q.take()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := q.deleteBatch(ctx, d, jobs, batch)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// deleteBatch удаляет пакет коротких URL из очереди и после завершения удаления отмечает результат в заданиях на удаление.
+// При ошибке удаления пакет возвращается в очередь, а состояние в заданиях не меняется до окончательного результата.
+// Если исчерпаны все попытки, короткие URL отмечаются в заданиях как неудалённые, а запросы остаются
+// во внешнем хранилище очереди и обрабатываются повторно после перезапуска сервиса.
+func (q *deletionQueue) deleteBatch(ctx context.Context, d deleter, jobs deletionJobStore, batch []deletionRequest) error {
+	shortURLs := make([]string, len(batch))
+	for i, request := range batch {
+		shortURLs[i] = request.shortURL
+	}
+
+	err := d.delete(ctx, shortURLs)
+	if err != nil {
+		log.Println("Ошибка при удалении пакета коротких URL:", err)
+		if !q.retry(batch, time.Now()) {
+			log.Println("Исчерпаны попытки удалить пакет коротких URL:", len(batch))
+			finishJobs(ctx, jobs, batch, DeletionFailed)
+		}
+		return err
+	}
+	q.succeeded()
+
+	if q.outbox != nil {
+		err = q.outbox.removePending(ctx, batch)
+		if err != nil {
+			log.Println("Ошибка при удалении обработанных запросов из сохранённой очереди:", err)
+		}
+	}
+
+	finishJobs(ctx, jobs, batch, DeletionDeleted)
+	return nil
+}
+
+// finishJobs отмечает результат удаления пакета в заданиях на удаление.
+// Ошибка сохранения состояния заданий не влияет на обработку очереди и только записывается в журнал.
+func finishJobs(ctx context.Context, jobs deletionJobStore, batch []deletionRequest, status DeletionStatus) {
+	err := jobs.finish(ctx, batch, status)
+	if err != nil {
+		log.Println("Ошибка при сохранении состояния задания на удаление:", err)
+	}
+}
+
+// deletionQueueProcess восстанавливает сохранённую очередь на удаление и обрабатывает её:
+// пакет удаляется при заполнении, а неполный пакет - с заданной периодичностью.
+func deletionQueueProcess(ctx context.Context, d deleter, q *deletionQueue, jobs deletionJobStore) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	q.locker.Lock()
+	q.cancel = cancel
+	q.locker.Unlock()
+
+	q.workers.Add(1)
+
+	go func() {
+		defer q.workers.Done()
+
+		err := q.restore(ctx)
+		if err != nil {
+			log.Println("Ошибка при восстановлении сохранённой очереди на удаление:", err)
+		}
+
+		ticker := time.NewTicker(q.options.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.full:
+			case <-ticker.C:
+			}
+
+			if q.waiting(time.Now()) {
+				continue
+			}
+
+			err = q.flush(ctx, d, jobs)
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+}
+
+// drainDeletionQueue останавливает обработчик очереди на удаление и удаляет оставшиеся в ней короткие URL.
+// Запросы, которые не удалось обработать до отмены контекста, остаются во внешнем хранилище очереди.
+func drainDeletionQueue(ctx context.Context, d deleter, q *deletionQueue, jobs deletionJobStore) error {
+	q.locker.Lock()
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.locker.Unlock()
+
+	q.workers.Wait()
+
+	n := q.len()
+	if n == 0 {
+		return nil
+	}
+
+	log.Println("Удаление оставшихся в очереди коротких URL:", n)
+	return q.flush(ctx, d, jobs)
+}
@@ -0,0 +1,1336 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Ключи, под которыми данные сервиса хранятся в Redis-совместимом хранилище.
+const (
+	redisKeyPrefix   = "shurl:"
+	redisURLKey      = redisKeyPrefix + "url:"     // Запись о коротком URL в формате JSON
+	redisLongURLKey  = redisKeyPrefix + "long:"    // Короткий URL, созданный для исходного длинного URL
+	redisUserKey     = redisKeyPrefix + "user:"    // Множество коротких URL пользователя
+	redisUsersKey    = redisKeyPrefix + "users"    // Множество пользователей
+	redisCountKey    = redisKeyPrefix + "count"    // Количество созданных коротких URL
+	redisExpiringKey = redisKeyPrefix + "expiring" // Короткие URL с ограниченным сроком действия, упорядоченные по его окончанию
+	redisClicksKey   = redisKeyPrefix + "clicks:"  // Список событий перехода по короткому URL
+	redisAPIKeysKey  = redisKeyPrefix + "apikeys"  // Ключи API в формате JSON по идентификатору ключа
+	redisJobKey      = redisKeyPrefix + "job:"     // Задание на удаление в формате JSON
+	redisSequenceKey = redisKeyPrefix + "sequence" // Общий счётчик последовательного генератора коротких URL
+
+	// redisTxAttempts задаёт количество попыток изменить запись, если её одновременно изменил другой экземпляр сервиса.
+	redisTxAttempts = 3
+
+	// redisScanCount задаёт количество ключей, запрашиваемых у сервера за один шаг перебора.
+	redisScanCount = 1000
+)
+
+// RedisStorage содержит настройки хранилища на Redis-совместимом сервере и ссылку на хранилище в памяти.
+// Все данные, в том числе задания на удаление, хранятся на сервере,
+// поэтому несколько экземпляров сервиса могут работать с одним сервером.
+type RedisStorage struct {
+	*MemoryStorage
+	client *redis.Client
+}
+
+// NewRedisStorage создаёт реализацию хранилища на Redis-совместимом сервере.
+// Адрес задаётся в виде host:port или в виде URL redis://...
+// Если сервер недоступен, возвращается ошибка: запуск с хранилищем в памяти привёл бы к потере данных
+// и к расхождению с другими экземплярами сервиса.
+func NewRedisStorage(ctx context.Context, m *MemoryStorage, address string) (*RedisStorage, error) {
+	options, err := redisOptions(address)
+	if err != nil {
+		return nil, fmt.Errorf("неверный адрес Redis-совместимого сервера: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	err = client.Ping(ctx).Err()
+	if err != nil {
+		if err1 := client.Close(); err1 != nil {
+			log.Println(err1)
+		}
+		return nil, fmt.Errorf("не удалось подключиться к Redis-совместимому серверу: %w", err)
+	}
+
+	m.deletionJobs = &redisDeletionJobs{client: client}
+	return &RedisStorage{MemoryStorage: m, client: client}, nil
+}
+
+// redisOptions разбирает адрес Redis-совместимого сервера.
+func redisOptions(address string) (*redis.Options, error) {
+	if strings.Contains(address, "://") {
+		return redis.ParseURL(address)
+	}
+
+	return &redis.Options{Addr: address}, nil
+}
+
+// DeletionQueueProcess обрабатывает очередь запросов на удаление в отдельном потоке.
+func (s *RedisStorage) DeletionQueueProcess(ctx context.Context) {
+	deletionQueueProcess(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// DrainDeletionQueue останавливает обработку очереди на удаление и удаляет оставшиеся в ней короткие URL.
+func (s *RedisStorage) DrainDeletionQueue(ctx context.Context) error {
+	return drainDeletionQueue(ctx, s, s.deletionQueue, s.deletionJobs)
+}
+
+// ExpirationProcess периодически помечает удалёнными короткие URL с истёкшим сроком действия.
+func (s *RedisStorage) ExpirationProcess(ctx context.Context) {
+	go expirationProcess(ctx, s, s.expired, ExpirationCheckInterval)
+}
+
+// AddURL добавляет исходный длинный URL в хранилище, связывая его с созданным коротким URL.
+// Если в параметрах задан псевдоним, он используется в качестве короткого URL.
+func (s *RedisStorage) AddURL(ctx context.Context, l, user string, opts URLOptions) (string, error) {
+	if s.client == nil {
+		return s.MemoryStorage.AddURL(ctx, l, user, opts)
+	}
+
+	if opts.Alias != "" {
+		err := ValidateAlias(opts.Alias)
+		if err != nil {
+			return "", err
+		}
+
+		return s.addURL(ctx, opts.Alias, l, user, opts)
+	}
+
+	generator := s.shortURLGenerator()
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		sh, err := generator.Generate(l, attempt)
+		if err != nil {
+			return "", err
+		}
+
+		sh, err = s.addURL(ctx, sh, l, user, opts)
+		if errors.Is(err, ErrAliasTaken) {
+			continue
+		}
+
+		return sh, err
+	}
+
+	return "", errGenerationExhausted
+}
+
+// addURL сохраняет запись о коротком URL, если такой короткий URL ещё не занят.
+// Если исходный длинный URL уже сокращён, возвращается ранее созданный для него короткий URL и ошибка дублирования.
+func (s *RedisStorage) addURL(ctx context.Context, sh, l, user string, opts URLOptions) (string, error) {
+	if opts.deduplicated() {
+		existing, duplicate, err := s.findDuplicateRecord(ctx, l, user, time.Now())
+		if err != nil {
+			return "", err
+		}
+
+		if duplicate {
+			log.Println("Найдена ранее сохранённая запись")
+			return existing, NewStorageDBError(l, true, nil)
+		}
+	}
+
+	value, err := encodeRedisRecord(sh, l, user, opts)
+	if err != nil {
+		return "", err
+	}
+
+	added, err := s.client.SetNX(ctx, redisURLKey+sh, value, 0).Result()
+	if err != nil {
+		return "", err
+	}
+
+	if !added {
+		log.Println("Короткий URL", sh, "уже существует в хранилище")
+		return "", ErrAliasTaken
+	}
+
+	var existing string
+	var duplicate bool
+	if opts.deduplicated() {
+		existing, duplicate, err = s.claimLongURL(ctx, sh, l, user)
+	}
+	if err != nil || duplicate {
+		if err1 := s.client.Del(ctx, redisURLKey+sh).Err(); err1 != nil {
+			log.Println("Ошибка при удалении записи о коротком URL", sh, ":", err1)
+		}
+	}
+
+	if err != nil {
+		return "", NewStorageDBError(l, false, err)
+	}
+
+	if duplicate {
+		log.Println("Найдена ранее сохранённая запись")
+		return existing, NewStorageDBError(l, true, nil)
+	}
+
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		s.index(ctx, p, sh, user, opts.ExpiresAt)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return sh, nil
+}
+
+// claimLongURL связывает исходный длинный URL с коротким URL в обратном индексе.
+// Если исходный длинный URL одновременно сокращён другим запросом, возвращается созданный им короткий URL и признак дублирования.
+// Устаревшая связь с удалённым или истёкшим коротким URL заменяется в транзакции, чтобы не затереть
+// связь, одновременно установленную другим экземпляром сервиса.
+func (s *RedisStorage) claimLongURL(ctx context.Context, sh, l, user string) (string, bool, error) {
+	key := redisLongURLKey + s.dedupKey(l, user)
+
+	added, err := s.client.SetNX(ctx, key, sh, 0).Result()
+	if err != nil || added {
+		return "", false, err
+	}
+
+	var existing string
+	var duplicate bool
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+			var err error
+			existing, duplicate, err = s.claimedByOther(ctx, tx, key, sh, time.Now())
+			if err != nil || duplicate {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, key, sh, 0)
+				return nil
+			})
+			return err
+		}, key)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if err != nil || !duplicate {
+		return "", false, err
+	}
+
+	return existing, true, nil
+}
+
+// claimLongURLs связывает исходные длинные URL пакета с сохранёнными для них короткими URL в обратном индексе.
+// Если исходный длинный URL одновременно сокращён другим запросом, сохранённая для него запись удаляется,
+// а вместо неё возвращается созданный другим запросом короткий URL с признаком дублирования.
+// Исходные длинные URL со сроком действия в обратный индекс не добавляются, см. URLOptions.deduplicated.
+// Возвращает номера исходных длинных URL, для которых сохранённые записи остались в хранилище.
+// При ошибке все сохранённые записи пакета удаляются.
+func (s *RedisStorage) claimLongURLs(ctx context.Context, longURLs BatchURLs, user string, added []int, shortURLs []string, duplicates []bool) ([]int, error) {
+	commands := make([]*redis.BoolCmd, len(added))
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, n := range added {
+			if longURLs[n].deduplicated() {
+				commands[i] = p.SetNX(ctx, redisLongURLKey+s.dedupKey(longURLs[n].URL, user), shortURLs[n], 0)
+			}
+		}
+		return nil
+	})
+
+	claimed := make([]int, 0, len(added))
+	rejected := make([]string, 0)
+	for i, n := range added {
+		if err != nil {
+			break
+		}
+
+		if commands[i] == nil || commands[i].Val() {
+			claimed = append(claimed, n)
+			continue
+		}
+
+		var existing string
+		var duplicate bool
+		existing, duplicate, err = s.claimLongURL(ctx, shortURLs[n], longURLs[n].URL, user)
+		if err != nil || !duplicate {
+			claimed = append(claimed, n)
+			continue
+		}
+
+		log.Println("Найдена ранее сохранённая запись")
+		rejected = append(rejected, redisURLKey+shortURLs[n])
+		shortURLs[n] = existing
+		duplicates[n] = true
+	}
+
+	if err != nil {
+		for _, n := range added {
+			if !duplicates[n] {
+				rejected = append(rejected, redisURLKey+shortURLs[n])
+			}
+		}
+		claimed = claimed[:0]
+	}
+
+	if len(rejected) > 0 {
+		if err1 := s.client.Del(ctx, rejected...).Err(); err1 != nil {
+			log.Println("Ошибка при удалении записей о коротких URL:", err1)
+		}
+	}
+
+	return claimed, err
+}
+
+// findDuplicateRecord ищет действующий короткий URL, ранее созданный для исходного длинного URL.
+func (s *RedisStorage) findDuplicateRecord(ctx context.Context, l, user string, now time.Time) (string, bool, error) {
+	existing, err := s.findDuplicateRecords(ctx, []string{l}, user, now)
+	if err != nil {
+		return "", false, err
+	}
+
+	return existing[0], existing[0] != "", nil
+}
+
+// findDuplicateRecords ищет действующие короткие URL, ранее созданные для исходных длинных URL.
+// Для исходных длинных URL, которые ещё не сокращены, возвращаются пустые строки.
+func (s *RedisStorage) findDuplicateRecords(ctx context.Context, longURLs []string, user string, now time.Time) ([]string, error) {
+	existing := make([]string, len(longURLs))
+
+	shortCommands := make([]*redis.StringCmd, len(longURLs))
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, l := range longURLs {
+			shortCommands[i] = p.Get(ctx, redisLongURLKey+s.dedupKey(l, user))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	recordCommands := make([]*redis.StringCmd, len(longURLs))
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, c := range shortCommands {
+			if c.Err() == nil {
+				recordCommands[i] = p.Get(ctx, redisURLKey+c.Val())
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, c := range recordCommands {
+		if c == nil || c.Err() != nil {
+			continue
+		}
+
+		r, err := decodeRedisRecord(c.Val())
+		if err != nil {
+			return nil, err
+		}
+
+		if !r.Deleted && !r.memoryRecord().Expired(now) {
+			existing[i] = r.ShortURL
+		}
+	}
+
+	return existing, nil
+}
+
+// index добавляет короткий URL в списки пользователя, счётчик и, при необходимости, в список URL с ограниченным сроком действия.
+func (s *RedisStorage) index(ctx context.Context, p redis.Pipeliner, sh, user string, expiresAt time.Time) {
+	p.SAdd(ctx, redisUserKey+user, sh)
+	p.SAdd(ctx, redisUsersKey, user)
+	p.Incr(ctx, redisCountKey)
+
+	if !expiresAt.IsZero() {
+		p.ZAdd(ctx, redisExpiringKey, redis.Z{Score: float64(expiresAt.UnixMilli()), Member: sh})
+	}
+}
+
+// AddURLs добавляет несколько исходных длинных URL в хранилище, связывая их с соответствующими созданными короткими URL.
+// Записи сохраняются пакетами команд. Для ранее сокращённых исходных длинных URL, в том числе повторяющихся в пакете,
+// возвращаются существующие короткие URL с признаком дублирования.
+func (s *RedisStorage) AddURLs(ctx context.Context, longURLs BatchURLs, user string) (BatchURLs, error) {
+	if s.client == nil {
+		return s.MemoryStorage.AddURLs(ctx, longURLs, user)
+	}
+
+	result := make(BatchURLs, 0, len(longURLs))
+
+	urls := make([]string, len(longURLs))
+	for i, longURL := range longURLs {
+		urls[i] = longURL.URL
+	}
+
+	shortURLs, err := s.findDuplicateRecords(ctx, urls, user, time.Now())
+	if err != nil {
+		return result[:0], err
+	}
+
+	duplicates := make([]bool, len(longURLs))
+	firstInBatch := make(map[string]int, len(longURLs))
+	pending := make([]int, 0, len(longURLs))
+	for i, longURL := range longURLs {
+		if !longURL.deduplicated() {
+			shortURLs[i] = ""
+			pending = append(pending, i)
+			continue
+		}
+
+		if shortURLs[i] != "" {
+			duplicates[i] = true
+			continue
+		}
+
+		key := s.dedupKey(longURL.URL, user)
+		if _, ok := firstInBatch[key]; ok {
+			duplicates[i] = true
+			continue
+		}
+
+		firstInBatch[key] = i
+		pending = append(pending, i)
+	}
+	added := pending
+
+	generator := s.shortURLGenerator()
+	for attempt := 0; attempt < maxGenerationAttempts && len(pending) > 0; attempt++ {
+		commands := make([]*redis.BoolCmd, len(pending))
+
+		_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+			for i, n := range pending {
+				sh, err := generator.Generate(longURLs[n].URL, attempt)
+				if err != nil {
+					return err
+				}
+
+				value, err := encodeRedisRecord(sh, longURLs[n].URL, user, URLOptions{ExpiresAt: longURLs[n].ExpiresAt})
+				if err != nil {
+					return err
+				}
+
+				shortURLs[n] = sh
+				commands[i] = p.SetNX(ctx, redisURLKey+sh, value, 0)
+			}
+			return nil
+		})
+		if err != nil {
+			return result[:0], err
+		}
+
+		collided := make([]int, 0)
+		for i, n := range pending {
+			if !commands[i].Val() {
+				collided = append(collided, n)
+			}
+		}
+		pending = collided
+	}
+
+	if len(pending) > 0 {
+		return result[:0], errGenerationExhausted
+	}
+
+	added, err = s.claimLongURLs(ctx, longURLs, user, added, shortURLs, duplicates)
+	if err != nil {
+		return result[:0], err
+	}
+
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, n := range added {
+			s.index(ctx, p, shortURLs[n], user, longURLs[n].ExpiresAt)
+		}
+		return nil
+	})
+	if err != nil {
+		return result[:0], err
+	}
+
+	for i, longURL := range longURLs {
+		if duplicates[i] && shortURLs[i] == "" {
+			shortURLs[i] = shortURLs[firstInBatch[s.dedupKey(longURL.URL, user)]]
+		}
+
+		result = append(result, RecordURL{ID: longURL.ID, URL: shortURLs[i], ExpiresAt: longURL.ExpiresAt, Duplicate: duplicates[i]})
+	}
+
+	return result, nil
+}
+
+// FindURL ищет в хранилище исходный длинный URL по заданному короткому URL.
+func (s *RedisStorage) FindURL(ctx context.Context, sh string) (MemoryRecord, error) {
+	if s.client == nil {
+		return s.MemoryStorage.FindURL(ctx, sh)
+	}
+
+	value, err := s.client.Get(ctx, redisURLKey+sh).Result()
+	if errors.Is(err, redis.Nil) {
+		return MemoryRecord{}, errors.New("короткий URL с ID \"" + sh + "\" не существует")
+	}
+	if err != nil {
+		return MemoryRecord{}, err
+	}
+
+	r, err := decodeRedisRecord(value)
+	if err != nil {
+		return MemoryRecord{}, err
+	}
+
+	return r.memoryRecord(), nil
+}
+
+// FlagURL устанавливает или снимает пометку короткого URL в хранилище.
+// Если запись одновременно изменена другим экземпляром сервиса, изменение повторяется.
+func (s *RedisStorage) FlagURL(ctx context.Context, sh string, flagged bool) error {
+	if s.client == nil {
+		return s.MemoryStorage.FlagURL(ctx, sh, flagged)
+	}
+
+	key := redisURLKey + sh
+
+	var err error
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.Get(ctx, key).Result()
+			if errors.Is(err, redis.Nil) {
+				return ErrURLNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			r, err := decodeRedisRecord(value)
+			if err != nil {
+				return err
+			}
+
+			r.Flagged = flagged
+			encoded, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, key, encoded, 0)
+				return nil
+			})
+			return err
+		}, key)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// UpdateURL заменяет исходный длинный URL короткого URL, принадлежащего пользователю, и переносит его в обратном индексе.
+// Если новый исходный длинный URL уже сокращён, возвращается ошибка дублирования.
+// Если запись одновременно изменена другим экземпляром сервиса, изменение повторяется.
+func (s *RedisStorage) UpdateURL(ctx context.Context, sh, l, user string) error {
+	if s.client == nil {
+		return s.MemoryStorage.UpdateURL(ctx, sh, l, user)
+	}
+
+	key := redisURLKey + sh
+	newLongKey := redisLongURLKey + s.dedupKey(l, user)
+
+	var err error
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+			r, err := s.getRecord(ctx, tx, sh)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			mr := r.memoryRecord()
+			err = updatable(mr, user, now)
+			if err != nil || r.LongURL == l {
+				return err
+			}
+
+			if mr.deduplicated() {
+				_, duplicate, err := s.claimedByOther(ctx, tx, newLongKey, sh, now)
+				if err != nil {
+					return err
+				}
+				if duplicate {
+					return NewStorageDBError(l, true, nil)
+				}
+			}
+
+			oldLongKey := redisLongURLKey + s.dedupKey(r.LongURL, r.UserID)
+			current, err := tx.Get(ctx, oldLongKey).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+
+			r.LongURL = l
+			encoded, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, key, encoded, 0)
+				if current == sh {
+					p.Del(ctx, oldLongKey)
+				}
+				if mr.deduplicated() {
+					p.Set(ctx, newLongKey, sh, 0)
+				}
+				return nil
+			})
+			return err
+		}, key, newLongKey)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// RestoreURL отменяет удаление короткого URL, принадлежащего пользователю, и возвращает его в обратный индекс.
+// Если исходный длинный URL за это время сокращён повторно, возвращается ошибка дублирования.
+// Если запись одновременно изменена другим экземпляром сервиса, изменение повторяется.
+func (s *RedisStorage) RestoreURL(ctx context.Context, sh, user string) error {
+	if s.client == nil {
+		return s.MemoryStorage.RestoreURL(ctx, sh, user)
+	}
+
+	key := redisURLKey + sh
+
+	var err error
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+			r, err := s.getRecord(ctx, tx, sh)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			mr := r.memoryRecord()
+			err = restorable(mr, user, now, s.restoreGracePeriod())
+			if err != nil {
+				return err
+			}
+
+			longKey := redisLongURLKey + s.dedupKey(r.LongURL, r.UserID)
+			if err = tx.Watch(ctx, longKey).Err(); err != nil {
+				return err
+			}
+
+			if mr.deduplicated() {
+				_, duplicate, err := s.claimedByOther(ctx, tx, longKey, sh, now)
+				if err != nil {
+					return err
+				}
+				if duplicate {
+					return NewStorageDBError(r.LongURL, true, nil)
+				}
+			}
+
+			r.Deleted = false
+			r.DeletedAt = nil
+			encoded, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, key, encoded, 0)
+				if mr.deduplicated() {
+					p.Set(ctx, longKey, sh, 0)
+				}
+				if !mr.ExpiresAt.IsZero() {
+					p.ZAdd(ctx, redisExpiringKey, redis.Z{Score: float64(mr.ExpiresAt.UnixMilli()), Member: sh})
+				}
+				return nil
+			})
+			return err
+		}, key)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// getRecord читает запись о коротком URL в рамках транзакции.
+func (s *RedisStorage) getRecord(ctx context.Context, tx *redis.Tx, sh string) (Record, error) {
+	value, err := tx.Get(ctx, redisURLKey+sh).Result()
+	if errors.Is(err, redis.Nil) {
+		return Record{}, ErrURLNotFound
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	return decodeRedisRecord(value)
+}
+
+// claimedByOther проверяет, связан ли исходный длинный URL в обратном индексе с другим действующим коротким URL,
+// и возвращает этот короткий URL.
+func (s *RedisStorage) claimedByOther(ctx context.Context, tx *redis.Tx, longKey, sh string, now time.Time) (string, bool, error) {
+	existing, err := tx.Get(ctx, longKey).Result()
+	if errors.Is(err, redis.Nil) || existing == sh {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	r, err := s.getRecord(ctx, tx, existing)
+	if errors.Is(err, ErrURLNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	if r.Deleted || r.memoryRecord().Expired(now) {
+		return "", false, nil
+	}
+
+	return existing, true, nil
+}
+
+// GetURLsByUser ищет в хранилище короткие URL, добавленные заданным пользователем.
+func (s *RedisStorage) GetURLsByUser(ctx context.Context, u string) ([]string, error) {
+	if s.client == nil {
+		return s.MemoryStorage.GetURLsByUser(ctx, u)
+	}
+
+	return s.client.SMembers(ctx, redisUserKey+u).Result()
+}
+
+// ListURLs возвращает страницу списка коротких URL пользователя с Redis-совместимого сервера.
+// Записи пользователя читаются одним пакетом команд, отбор и сортировка выполняются на стороне сервиса.
+func (s *RedisStorage) ListURLs(ctx context.Context, user string, q ListQuery) (ListPage, error) {
+	if s.client == nil {
+		return s.MemoryStorage.ListURLs(ctx, user, q)
+	}
+
+	shortURLs, err := s.client.SMembers(ctx, redisUserKey+user).Result()
+	if err != nil {
+		return ListPage{}, err
+	}
+
+	commands := make([]*redis.StringCmd, len(shortURLs))
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, sh := range shortURLs {
+			commands[i] = p.Get(ctx, redisURLKey+sh)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return ListPage{}, err
+	}
+
+	entries := make([]URLEntry, 0, len(shortURLs))
+	for i, sh := range shortURLs {
+		value, err := commands[i].Result()
+		if err != nil {
+			continue
+		}
+
+		r, err := decodeRedisRecord(value)
+		if err != nil {
+			return ListPage{}, err
+		}
+
+		entries = append(entries, URLEntry{ShortURL: sh, MemoryRecord: r.memoryRecord()})
+	}
+
+	return paginate(entries, q, time.Now())
+}
+
+// DeleteURLs добавляет в очередь на удаление те из заданных коротких URL, которые принадлежат пользователю.
+// Принадлежность коротких URL проверяется в рамках запроса, а само удаление выполняется в отдельном потоке.
+// Возвращает задание на удаление с состоянием каждого короткого URL из запроса.
+func (s *RedisStorage) DeleteURLs(ctx context.Context, shortURLs []string, user string) (DeletionJob, error) {
+	if s.client == nil {
+		return s.MemoryStorage.DeleteURLs(ctx, shortURLs, user)
+	}
+
+	commands := make([]*redis.StringCmd, len(shortURLs))
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, sh := range shortURLs {
+			commands[i] = p.Get(ctx, redisURLKey+sh)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return DeletionJob{}, err
+	}
+
+	records := make(map[string]MemoryRecord, len(shortURLs))
+	for i, sh := range shortURLs {
+		value, err := commands[i].Result()
+		if err != nil {
+			continue
+		}
+
+		r, err := decodeRedisRecord(value)
+		if err != nil {
+			return DeletionJob{}, err
+		}
+
+		records[sh] = r.memoryRecord()
+	}
+
+	results := newDeletionResults(shortURLs, func(sh string) DeletionStatus {
+		mr, ok := records[sh]
+		return deletionStatus(mr, ok, user)
+	})
+
+	return s.enqueueDeletion(ctx, user, results)
+}
+
+// redisDeletionJobs хранит задания на удаление на Redis-совместимом сервере,
+// чтобы состояние задания было доступно любому экземпляру сервиса и сохранялось при его перезапуске.
+// Устаревшие задания удаляются сервером по истечении DeletionJobRetention.
+type redisDeletionJobs struct {
+	client *redis.Client
+}
+
+// start сохраняет задание на удаление на сервере.
+func (d *redisDeletionJobs) start(ctx context.Context, user string, results []DeletionResult, now time.Time) (DeletionJob, error) {
+	job, err := newDeletionJob(user, results, now)
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	ttl := DeletionJobRetention - time.Since(now)
+	if ttl <= 0 {
+		return job.copy(), nil
+	}
+
+	value, err := json.Marshal(job)
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	err = d.client.Set(ctx, redisJobKey+job.ID, value, ttl).Err()
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	return job.copy(), nil
+}
+
+// finish устанавливает состояние удаления коротких URL из обработанного пакета очереди.
+// Если задание одновременно изменено другим экземпляром сервиса, изменение повторяется.
+func (d *redisDeletionJobs) finish(ctx context.Context, batch []deletionRequest, status DeletionStatus) error {
+	shortURLs := make(map[string][]string)
+	for _, request := range batch {
+		shortURLs[request.jobID] = append(shortURLs[request.jobID], request.shortURL)
+	}
+
+	for id, urls := range shortURLs {
+		err := d.update(ctx, redisJobKey+id, urls, status)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// update устанавливает состояние удаления коротких URL в задании, сохраняя срок его хранения.
+func (d *redisDeletionJobs) update(ctx context.Context, key string, shortURLs []string, status DeletionStatus) error {
+	var err error
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = d.client.Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.Get(ctx, key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			var job DeletionJob
+			err = json.Unmarshal(value, &job)
+			if err != nil {
+				return err
+			}
+
+			for _, sh := range shortURLs {
+				job.setStatus(sh, status)
+			}
+
+			encoded, err := json.Marshal(job)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, key, encoded, redis.KeepTTL)
+				return nil
+			})
+			return err
+		}, key)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// get возвращает задание на удаление, созданное заданным пользователем.
+func (d *redisDeletionJobs) get(ctx context.Context, id, user string) (DeletionJob, error) {
+	value, err := d.client.Get(ctx, redisJobKey+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return DeletionJob{}, ErrDeletionJobNotFound
+	}
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	var job DeletionJob
+	err = json.Unmarshal(value, &job)
+	if err != nil {
+		return DeletionJob{}, err
+	}
+
+	if job.User != user {
+		return DeletionJob{}, ErrDeletionJobNotFound
+	}
+
+	return job, nil
+}
+
+func (s *RedisStorage) delete(ctx context.Context, deletionBatch []string) error {
+	if s.client == nil {
+		return s.MemoryStorage.delete(ctx, deletionBatch)
+	}
+
+	for _, sh := range deletionBatch {
+		if sh == "" {
+			continue
+		}
+
+		err := s.markDeleted(ctx, sh)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markDeleted помечает запись о коротком URL удалённой и освобождает исходный длинный URL для повторного сокращения.
+// Если запись одновременно изменена другим экземпляром сервиса, изменение повторяется.
+func (s *RedisStorage) markDeleted(ctx context.Context, sh string) error {
+	key := redisURLKey + sh
+
+	var err error
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.Get(ctx, key).Result()
+			if errors.Is(err, redis.Nil) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			r, err := decodeRedisRecord(value)
+			if err != nil || r.Deleted {
+				return err
+			}
+
+			r.Deleted = true
+			r.DeletedAt = timeOrNil(time.Now())
+			encoded, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+
+			longKey := redisLongURLKey + s.dedupKey(r.LongURL, r.UserID)
+			current, err := tx.Get(ctx, longKey).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, key, encoded, 0)
+				p.ZRem(ctx, redisExpiringKey, sh)
+				if current == sh {
+					p.Del(ctx, longKey)
+				}
+				return nil
+			})
+			return err
+		}, key)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// expired возвращает короткие URL, срок действия которых истёк к заданному моменту.
+func (s *RedisStorage) expired(now time.Time) []string {
+	if s.client == nil {
+		return s.MemoryStorage.expired(now)
+	}
+
+	result, err := s.client.ZRangeByScore(context.Background(), redisExpiringKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		log.Println("Ошибка при поиске коротких URL с истёкшим сроком действия:", err)
+	}
+
+	return result
+}
+
+// GetStatistics возвращает статистику сервиса: количество сокращённых URL и количество пользователей.
+func (s *RedisStorage) GetStatistics(ctx context.Context) (urls int, users int, err error) {
+	if s.client == nil {
+		return s.MemoryStorage.GetStatistics(ctx)
+	}
+
+	var count *redis.StringCmd
+	var members *redis.IntCmd
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		count = p.Get(ctx, redisCountKey)
+		members = p.SCard(ctx, redisUsersKey)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+
+	urls, err = count.Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+
+	return urls, int(members.Val()), nil
+}
+
+// walkShortURLs передаёт функции все короткие URL с Redis-совместимого сервера.
+func (s *RedisStorage) walkShortURLs(ctx context.Context, fn func(sh string)) error {
+	if s.client == nil {
+		return s.MemoryStorage.walkShortURLs(ctx, fn)
+	}
+
+	iter := s.client.Scan(ctx, 0, redisURLKey+"*", redisScanCount).Iterator()
+	for iter.Next(ctx) {
+		fn(strings.TrimPrefix(iter.Val(), redisURLKey))
+	}
+
+	return iter.Err()
+}
+
+// nextSequence возвращает следующий порядковый номер из общего для экземпляров сервиса счётчика на сервере.
+func (s *RedisStorage) nextSequence(ctx context.Context) (uint64, error) {
+	n, err := s.client.Incr(ctx, redisSequenceKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(n), nil
+}
+
+// raiseSequence поднимает общий счётчик порядковых номеров до заданного значения, если он меньше.
+// Если счётчик одновременно изменён другим экземпляром сервиса, изменение повторяется.
+func (s *RedisStorage) raiseSequence(ctx context.Context, n uint64) error {
+	var err error
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+			current, err := tx.Get(ctx, redisSequenceKey).Uint64()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+			if current >= n {
+				return nil
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, redisSequenceKey, n, 0)
+				return nil
+			})
+			return err
+		}, redisSequenceKey)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// AddClicks сохраняет пакет событий перехода по коротким URL.
+func (s *RedisStorage) AddClicks(ctx context.Context, clicks []Click) error {
+	if s.client == nil {
+		return s.MemoryStorage.AddClicks(ctx, clicks)
+	}
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, c := range clicks {
+			value, err := json.Marshal(c)
+			if err != nil {
+				return err
+			}
+			p.RPush(ctx, redisClicksKey+c.ShortURL, value)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// GetClicks возвращает все события перехода по заданному короткому URL.
+func (s *RedisStorage) GetClicks(ctx context.Context, sh string) ([]Click, error) {
+	if s.client == nil {
+		return s.MemoryStorage.GetClicks(ctx, sh)
+	}
+
+	values, err := s.client.LRange(ctx, redisClicksKey+sh, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Click, 0, len(values))
+	for _, value := range values {
+		var c Click
+		err = json.Unmarshal([]byte(value), &c)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+
+	return result, nil
+}
+
+// MergeUser переназначает все короткие URL пользователя другому пользователю.
+func (s *RedisStorage) MergeUser(ctx context.Context, from, to string) (int, error) {
+	if s.client == nil {
+		return s.MemoryStorage.MergeUser(ctx, from, to)
+	}
+
+	if from == "" || to == "" || from == to {
+		return 0, nil
+	}
+
+	shortURLs, err := s.client.SMembers(ctx, redisUserKey+from).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	merged := 0
+	for _, sh := range shortURLs {
+		moved, err := s.mergeURL(ctx, sh, from, to)
+		if err != nil {
+			return merged, err
+		}
+		if moved {
+			merged++
+		}
+	}
+
+	left, err := s.client.SCard(ctx, redisUserKey+from).Result()
+	if err != nil || left > 0 {
+		return merged, err
+	}
+
+	return merged, s.client.SRem(ctx, redisUsersKey, from).Err()
+}
+
+// mergeURL переназначает короткий URL другому пользователю и возвращает признак того, что запись изменена.
+// Если запись одновременно изменена другим экземпляром сервиса, изменение повторяется.
+func (s *RedisStorage) mergeURL(ctx context.Context, sh, from, to string) (bool, error) {
+	key := redisURLKey + sh
+
+	var moved bool
+	var err error
+	for attempt := 0; attempt < redisTxAttempts; attempt++ {
+		err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+			r, err := s.getRecord(ctx, tx, sh)
+			if errors.Is(err, ErrURLNotFound) {
+				return tx.SRem(ctx, redisUserKey+from, sh).Err()
+			}
+			if err != nil || r.UserID != from {
+				return err
+			}
+
+			oldLongKey := redisLongURLKey + s.dedupKey(r.LongURL, from)
+			newLongKey := redisLongURLKey + s.dedupKey(r.LongURL, to)
+			current, err := tx.Get(ctx, oldLongKey).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+
+			r.UserID = to
+			encoded, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+				p.Set(ctx, key, encoded, 0)
+				p.SRem(ctx, redisUserKey+from, sh)
+				p.SAdd(ctx, redisUserKey+to, sh)
+				p.SAdd(ctx, redisUsersKey, to)
+				if current == sh && oldLongKey != newLongKey {
+					p.Del(ctx, oldLongKey)
+					p.SetNX(ctx, newLongKey, sh, 0)
+				}
+				return nil
+			})
+			moved = err == nil
+			return err
+		}, key)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return moved, err
+		}
+	}
+
+	return moved, err
+}
+
+// AddAPIKey сохраняет ключ API.
+func (s *RedisStorage) AddAPIKey(ctx context.Context, key APIKey) error {
+	if s.client == nil {
+		return s.MemoryStorage.AddAPIKey(ctx, key)
+	}
+
+	value, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(ctx, redisAPIKeysKey, key.ID, value).Err()
+}
+
+// GetAPIKey возвращает ключ API с заданным идентификатором.
+func (s *RedisStorage) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	if s.client == nil {
+		return s.MemoryStorage.GetAPIKey(ctx, id)
+	}
+
+	value, err := s.client.HGet(ctx, redisAPIKeysKey, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	var key APIKey
+	err = json.Unmarshal([]byte(value), &key)
+	return key, err
+}
+
+// ListAPIKeys возвращает все ключи API в порядке их создания.
+func (s *RedisStorage) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	if s.client == nil {
+		return s.MemoryStorage.ListAPIKeys(ctx)
+	}
+
+	values, err := s.client.HVals(ctx, redisAPIKeysKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]APIKey, 0, len(values))
+	for _, value := range values {
+		var key APIKey
+		err = json.Unmarshal([]byte(value), &key)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, key)
+	}
+
+	sortAPIKeys(result)
+	return result, nil
+}
+
+// RevokeAPIKey отзывает ключ API. Повторный отзыв не меняет момент отзыва.
+func (s *RedisStorage) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	if s.client == nil {
+		return s.MemoryStorage.RevokeAPIKey(ctx, id, at)
+	}
+
+	key, err := s.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.Revoked() {
+		return nil
+	}
+
+	key.RevokedAt = at.UTC()
+	return s.AddAPIKey(ctx, key)
+}
+
+// CloseFunc возвращает функцию для закрытия соединения с Redis-совместимым сервером.
+func (s *RedisStorage) CloseFunc() func() {
+	return func() {
+		s.DeletionCancel()
+
+		if s.client == nil {
+			return
+		}
+
+		err := s.client.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// Ping проверяет соединение с Redis-совместимым сервером и выдаёт ошибку, если оно не установлено.
+func (s *RedisStorage) Ping(ctx context.Context) error {
+	if s.client == nil {
+		return s.MemoryStorage.Ping(ctx)
+	}
+
+	return s.client.Ping(ctx).Err()
+}
+
+// encodeRedisRecord формирует запись о коротком URL в формате JSON.
+func encodeRedisRecord(sh, l, user string, opts URLOptions) (string, error) {
+	value, err := json.Marshal(Record{
+		ShortURL:     sh,
+		LongURL:      l,
+		UserID:       user,
+		ExpiresAt:    timeOrNil(opts.ExpiresAt),
+		PasswordHash: opts.PasswordHash,
+		CreatedAt:    timeOrNil(time.Now()),
+	})
+
+	return string(value), err
+}
+
+// decodeRedisRecord разбирает запись о коротком URL в формате JSON.
+func decodeRedisRecord(value string) (Record, error) {
+	var r Record
+	err := json.Unmarshal([]byte(value), &r)
+	return r, err
+}
@@ -4,34 +4,125 @@ const (
 	queryInsert = `
 	INSERT INTO public.short_urls
 	    (
-			short_url, long_url, user_id
+			short_url, long_url, user_id, expires_at, password_hash, dedup_key
 		)
-	VALUES ($1, $2, $3);`
+	VALUES ($1, $2, $3, $4, $5, $6);`
 
-	queryCreateTable = `
-	CREATE TABLE IF NOT EXISTS public.short_urls
+	queryInsertGenerated = `
+	INSERT INTO public.short_urls
+	    (
+			short_url, long_url, user_id, expires_at, password_hash, dedup_key
+		)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT ON CONSTRAINT short_urls_pkey DO NOTHING;`
+
+	querySelectByShortURL = `
+	SELECT long_url, user_id, deleted, deleted_at, expires_at, password_hash, flagged, created_at
+	FROM short_urls
+	WHERE short_url = $1`
+
+	querySelectForUpdate = `
+	SELECT long_url, user_id, deleted, deleted_at, expires_at, password_hash
+	FROM short_urls
+	WHERE short_url = $1
+	FOR UPDATE`
+
+	querySelectByUser = `SELECT short_url FROM short_urls WHERE user_id = $1`
+
+	querySelectPageByUser = `
+	SELECT short_url, long_url, user_id, deleted, deleted_at, expires_at, password_hash, flagged, created_at
+	FROM short_urls
+	WHERE user_id = $1`
+
+	filterActive = ` AND deleted = false AND (expires_at IS NULL OR expires_at > now())`
+
+	filterDeleted = ` AND deleted = true AND (expires_at IS NULL OR deleted_at IS NULL OR deleted_at < expires_at)`
+
+	filterNotDeleted = ` AND NOT (deleted = true AND (expires_at IS NULL OR deleted_at IS NULL OR deleted_at < expires_at))`
+
+	filterExpired = ` AND expires_at IS NOT NULL AND expires_at <= now()` +
+		` AND NOT (deleted = true AND (deleted_at IS NULL OR deleted_at < expires_at))`
+
+	filterSearch = ` AND strpos(lower(long_url), lower($%d)) > 0`
+
+	filterAfterAsc = ` AND (created_at, short_url) > ($%d, $%d)`
+
+	filterAfterDesc = ` AND (created_at, short_url) < ($%d, $%d)`
+
+	orderAsc = ` ORDER BY created_at ASC, short_url ASC LIMIT $%d`
+
+	orderDesc = ` ORDER BY created_at DESC, short_url DESC LIMIT $%d`
+
+	querySelectDeletionCandidates = `
+	SELECT short_url, user_id, deleted
+	FROM short_urls
+	WHERE short_url = ANY($1)`
+
+	querySelectExpired = `
+	SELECT short_url
+	FROM short_urls
+	WHERE deleted <> true AND expires_at IS NOT NULL AND expires_at <= $1`
+
+	querySelectStatistics = `SELECT COUNT(*), COUNT(DISTINCT user_id) FROM short_urls`
+
+	querySelectShortURLs = `SELECT short_url FROM short_urls`
+
+	querySelectByLongURL = `
+	SELECT short_url
+	FROM short_urls
+	WHERE long_url = $1 AND deleted = false AND expires_at IS NULL AND password_hash IS NULL
+	LIMIT 1`
+
+	querySelectByLongURLAndUser = `
+	SELECT short_url
+	FROM short_urls
+	WHERE long_url = $1 AND user_id = $2 AND deleted = false AND expires_at IS NULL AND password_hash IS NULL
+	LIMIT 1`
+
+	queryLockLongURL = `SELECT pg_advisory_xact_lock($1, $2)`
+
+	queryDelete = `UPDATE short_urls SET deleted = true, deleted_at = now(), dedup_key = NULL WHERE short_url = $1 AND deleted = false`
+
+	queryUpdateLongURL = `UPDATE short_urls SET long_url = $2, dedup_key = $3 WHERE short_url = $1`
+
+	queryRestore = `UPDATE short_urls SET deleted = false, deleted_at = NULL, dedup_key = $2 WHERE short_url = $1`
+
+	queryFlag = `UPDATE short_urls SET flagged = $2 WHERE short_url = $1`
+
+	queryInsertClick = `
+	INSERT INTO public.clicks
 		(
-			short_url character varying(14) COLLATE pg_catalog."default" NOT NULL,
-			long_url character varying COLLATE pg_catalog."default" NOT NULL,
-			user_id character varying COLLATE pg_catalog."default",
-			deleted boolean NOT NULL DEFAULT false,
-			
-			CONSTRAINT short_urls_pkey PRIMARY KEY (short_url)
-		)	
-	TABLESPACE pg_default;
-
-	CREATE UNIQUE INDEX IF NOT EXISTS unique_long_url
-    	ON public.short_urls USING btree
-    (	long_url COLLATE pg_catalog."default" ASC NULLS LAST, 
-    	deleted  ASC NULLS LAST	) 
-    TABLESPACE pg_default;
-`
-
-	querySelectAll = `
-	SELECT short_url, long_url, user_id, deleted
-	FROM short_urls`
-
-	querySelectByLongURL = `SELECT short_url FROM short_urls WHERE long_url = $1 AND deleted <> true`
-
-	queryDelete = `UPDATE short_urls SET deleted = true WHERE short_url = $1`
+			short_url, clicked_at, referrer, user_agent, visitor_hash, country
+		)
+	VALUES ($1, $2, $3, $4, $5, $6);`
+
+	querySelectClicks = `
+	SELECT short_url, clicked_at, referrer, user_agent, visitor_hash, country
+	FROM clicks
+	WHERE short_url = $1
+	ORDER BY clicked_at`
+
+	queryInsertPendingDeletions = `
+	INSERT INTO public.pending_deletions (job_id, short_url)
+	SELECT * FROM unnest($1::varchar[], $2::varchar[])`
+
+	querySelectPendingDeletions = `SELECT job_id, short_url FROM pending_deletions ORDER BY id`
+
+	queryDeletePendingDeletions = `
+	DELETE FROM pending_deletions
+	WHERE (job_id, short_url) IN (SELECT * FROM unnest($1::varchar[], $2::varchar[]))`
+
+	queryInsertAPIKey = `
+	INSERT INTO public.api_keys (id, user_id, name, hash, scope, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+
+	querySelectAPIKeys = `SELECT id, user_id, name, hash, scope, created_at, revoked_at FROM api_keys`
+
+	querySelectAPIKey = querySelectAPIKeys + ` WHERE id = $1`
+
+	queryRevokeAPIKey = `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`
+
+	queryMergeUser = `UPDATE short_urls SET user_id = $2 WHERE user_id = $1 RETURNING short_url`
+
+	queryMergeUserScoped = `UPDATE short_urls SET user_id = $2, dedup_key = NULL WHERE user_id = $1 RETURNING short_url`
 )
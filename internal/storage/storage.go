@@ -3,99 +3,192 @@ package storage
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"errors"
-	"log"
-	"strconv"
 	"sync"
 	"time"
 )
 
-// Константы для обработки очередей на удаление записей.
-const (
-	// DeletionBatchSize задаёт максимальный размер пакета для массового удаления данных.
-	DeletionBatchSize = 20
-	// DeletionQueueSize задаёт максимальный размер очереди записей, подлежащих удалению.
-	DeletionQueueSize = DeletionBatchSize * 2
-)
-
 // Типы данных для работы хранилища.
 type (
 	// RecordURL содержит запись для списка массового сокращения длинных URL.
 	RecordURL struct {
-		ID  string // Идентификатор записи в исходном запросе
-		URL string // Длинный URL, который подлежит сокращению
+		ID        string    // Идентификатор записи в исходном запросе
+		URL       string    // Длинный URL, который подлежит сокращению
+		ExpiresAt time.Time // Момент окончания срока действия короткого URL, нулевое значение - бессрочно
+		Duplicate bool      // Признак того, что исходный длинный URL был сокращён ранее и возвращён существующий короткий URL
 	}
 
 	// BatchURLs содержит список URL, подлежащих сокращению
 	BatchURLs = []RecordURL
 
+	// URLOptions содержит необязательные параметры сокращения длинного URL.
+	URLOptions struct {
+		Alias        string    // Пользовательский псевдоним, используемый вместо сгенерированного короткого URL
+		ExpiresAt    time.Time // Момент окончания срока действия короткого URL, нулевое значение - бессрочно
+		PasswordHash string    // Хеш пароля, которым защищён короткий URL, см. HashPassword
+	}
+
 	// Storager обеспечивает экземпляр хранилища основными функциями.
+	// Переданный в методы контекст ограничивает время выполнения операций с хранилищем.
 	Storager interface {
-		AddURL(string, string) (string, error)        // Добавление длинного URL в хранилище и его сокращение.
-		AddURLs(BatchURLs, string) (BatchURLs, error) // Добавление списка длинных URL в хранилище и их сокращение.
-		FindURL(string) (MemoryRecord, error)         // Поиск длинного URL в хранилище по его сокращённому варианту.
-		GetURLsByUser(string) []string                // Поиск в хранилище всех URL, добавленных текущим пользователем.
-		DeleteURLs([]string, string) []string         // Удаление из хранилища списка URL.
-		GetStatistics() (urls int, users int)         // Статистика сервиса: количество сокращённых URL и количество пользователей.
-		CloseFunc() func()                            // Закрытие соединения с хранилищем (для файла или БД).
-		Ping() error                                  // Проверка установки соединения с БД.
+		ClickStorager
+
+		AddURL(context.Context, string, string, URLOptions) (string, error)  // Добавление длинного URL в хранилище и его сокращение.
+		AddURLs(context.Context, BatchURLs, string) (BatchURLs, error)       // Добавление списка длинных URL в хранилище и их сокращение.
+		FindURL(context.Context, string) (MemoryRecord, error)               // Поиск длинного URL в хранилище по его сокращённому варианту.
+		GetURLsByUser(context.Context, string) ([]string, error)             // Поиск в хранилище всех URL, добавленных текущим пользователем.
+		ListURLs(context.Context, string, ListQuery) (ListPage, error)       // Постраничный поиск URL пользователя с сортировкой и фильтрами.
+		DeleteURLs(context.Context, []string, string) (DeletionJob, error)   // Постановка в очередь на удаление списка URL пользователя.
+		GetDeletionJob(context.Context, string, string) (DeletionJob, error) // Состояние задания на удаление, созданного пользователем.
+		UpdateURL(context.Context, string, string, string) error             // Замена исходного длинного URL короткого URL, принадлежащего пользователю.
+		RestoreURL(context.Context, string, string) error                    // Отмена удаления короткого URL, принадлежащего пользователю.
+		GetStatistics(context.Context) (urls int, users int, err error)      // Статистика сервиса: количество сокращённых URL и количество пользователей.
+		CloseFunc() func()                                                   // Закрытие соединения с хранилищем (для файла или БД).
+		Ping(context.Context) error                                          // Проверка установки соединения с БД.
 	}
 
 	deleter interface {
 		DeletionQueueProcess(context.Context)
+		DrainDeletionQueue(context.Context) error
 		delete(context.Context, []string) error
 	}
 
+	// Options содержит настройки хранилища. Способ хранения выбирается по первому заданному из параметров
+	// DatabaseDSN, RedisAddress, BoltStoragePath и FileStoragePath, если ни один из них не задан, данные хранятся в памяти.
+	Options struct {
+		DatabaseDSN     string // Строка подключения к БД
+		RedisAddress    string // Адрес Redis-совместимого сервера
+		BoltStoragePath string // Путь к файлу встроенной БД
+		FileStoragePath string // Путь к файлу хранилища
+
+		Generator          string            // Способ генерации коротких URL, см. NewGenerator
+		GeneratedLength    int               // Длина генерируемого короткого URL
+		Salt               string            // Соль последовательного генератора коротких URL
+		DedupScope         string            // Область поиска дублирующихся URL, см. NewDedupScope
+		RestoreGracePeriod time.Duration     // Срок, в течение которого удалённый короткий URL можно восстановить
+		Database           DatabaseOptions   // Настройки хранилища в БД
+		Deletion           DeletionOptions   // Настройки очереди на удаление
+		Compaction         CompactionOptions // Настройки сжатия файла хранилища
+	}
+
 	// MemoryRecord содержит соответствие исходного длинного URL и пользователя, добавившего его.
-	// А также пометку об удаление этого URL из хранилища.
+	// А также пометку об удаление этого URL из хранилища и момент удаления, момент окончания срока его действия,
+	// хеш пароля, если URL защищён паролем, пометку о подозрительной странице назначения и момент создания.
 	MemoryRecord struct {
-		LongURL string
-		User    string
-		Deleted bool
+		LongURL      string
+		User         string
+		Deleted      bool
+		DeletedAt    time.Time
+		ExpiresAt    time.Time
+		PasswordHash string
+		Flagged      bool
+		CreatedAt    time.Time
 	}
 
 	// MemoryStorage обеспечивает хранилище в памяти для соответствий исходных длинных URL и соответствующих им коротких URL.
-	// А также хранит информацию об URL, добавленных определёнными пользователми,
-	// обеспечивает блокировку хранилища при конкурентном доступе,
-	// содержит ссылку на очередь для удаления записей и функцию для отмены контекста операций удаления.
+	// А также хранит информацию об URL, добавленных определёнными пользователми, обратный индекс исходных длинных URL
+	// для поиска дублирующихся URL и события перехода по коротким URL,
+	// обеспечивает блокировку хранилища при конкурентном доступе, хранит ключи API,
+	// содержит ссылку на очередь для удаления записей, задания на удаление, функцию для отмены контекста операций удаления
+	// и срок, в течение которого удалённые записи можно восстановить.
 	MemoryStorage struct {
 		container      map[string]MemoryRecord
 		usersURLs      map[string][]string
+		longURLs       map[string]string
+		dedup          DedupScope
 		locker         sync.RWMutex
-		deletionQueue  chan string
+		deletionQueue  *deletionQueue
+		deletionJobs   deletionJobStore
 		DeletionCancel context.CancelFunc
+		generator      Generator
+		clicks         map[string][]Click
+		restoreGrace   time.Duration
+		apiKeys        map[string]APIKey
 	}
 )
 
-// NewStorage создаёт реализацию хранилища в памяти, в файле или в БД, в зависимости от переданных настроек.
-func NewStorage(ctx context.Context, filePath string, database string) Storager {
+// NewStorage создаёт реализацию хранилища в памяти, в файле, во встроенной БД, в БД или на Redis-совместимом сервере,
+// в зависимости от переданных настроек.
+func NewStorage(ctx context.Context, opts Options) (Storager, error) {
 	var storage Storager
 
+	generator, err := NewGenerator(opts.Generator, opts.GeneratedLength, opts.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	dedup, err := NewDedupScope(opts.DedupScope)
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewMemoryStorage()
+	m.generator = generator
+	m.dedup = dedup
+	m.restoreGrace = opts.RestoreGracePeriod
+	m.deletionQueue = newDeletionQueue(opts.Deletion)
+
 	deletionContext, deletionCancel := context.WithCancel(ctx)
 
 	switch {
-	case database != "":
-		dStorage := NewDBStorage(ctx, NewMemoryStorage(), database)
+	case opts.DatabaseDSN != "":
+		dStorage := NewDBStorage(ctx, m, opts.DatabaseDSN, opts.Database)
 		dStorage.DeletionCancel = deletionCancel
 		dStorage.DeletionQueueProcess(deletionContext)
+		dStorage.ExpirationProcess(deletionContext)
 		storage = dStorage
 
-	case filePath != "":
-		fStorage := newFileStorage(NewMemoryStorage(), filePath)
+	case opts.RedisAddress != "":
+		rStorage, err := NewRedisStorage(ctx, m, opts.RedisAddress)
+		if err != nil {
+			deletionCancel()
+			return nil, err
+		}
+		rStorage.DeletionCancel = deletionCancel
+		rStorage.DeletionQueueProcess(deletionContext)
+		rStorage.ExpirationProcess(deletionContext)
+		storage = rStorage
+
+	case opts.BoltStoragePath != "":
+		bStorage, err := NewBoltStorage(m, opts.BoltStoragePath)
+		if err != nil {
+			deletionCancel()
+			return nil, err
+		}
+		bStorage.DeletionCancel = deletionCancel
+		bStorage.DeletionQueueProcess(deletionContext)
+		bStorage.ExpirationProcess(deletionContext)
+		storage = bStorage
+
+	case opts.FileStoragePath != "":
+		fStorage, err := newFileStorage(m, opts.FileStoragePath)
+		if err != nil {
+			deletionCancel()
+			return nil, err
+		}
 		fStorage.DeletionCancel = deletionCancel
 		fStorage.DeletionQueueProcess(deletionContext)
+		fStorage.ExpirationProcess(deletionContext)
+		fStorage.compaction = opts.Compaction
+		fStorage.CompactionProcess(deletionContext)
 		storage = fStorage
 
 	default:
-		mStorage := NewMemoryStorage()
-		mStorage.DeletionCancel = deletionCancel
-		mStorage.DeletionQueueProcess(deletionContext)
-		storage = mStorage
+		m.DeletionCancel = deletionCancel
+		m.DeletionQueueProcess(deletionContext)
+		m.ExpirationProcess(deletionContext)
+		storage = m
+	}
+
+	if seeder, ok := generator.(sequenceSeeder); ok {
+		err = seedSequence(ctx, storage, seeder)
+		if err != nil {
+			deletionCancel()
+			return nil, err
+		}
 	}
 
-	return storage
+	return storage, nil
 }
 
 // NewMemoryStorage создаёт реализацию хранилища в памяти приложения.
@@ -103,175 +196,233 @@ func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
 		container:      map[string]MemoryRecord{},
 		usersURLs:      map[string][]string{},
-		deletionQueue:  make(chan string, DeletionQueueSize),
+		longURLs:       map[string]string{},
+		dedup:          DedupGlobal,
+		deletionQueue:  newDeletionQueue(DeletionOptions{}),
+		deletionJobs:   newDeletionJobs(),
 		DeletionCancel: nil,
+		generator:      TimeGenerator{},
+		clicks:         map[string][]Click{},
 	}
 }
 
-func generateShortURL() (string, error) {
-	t := time.Now()
-	result := strconv.FormatInt(t.UnixMicro(), 36)
+// AddURL добавляет исходный длинный URL в хранилище в памяти, связывая его с созданным коротким URL.
+// Если в параметрах задан псевдоним, он используется в качестве короткого URL.
+// Если исходный длинный URL уже сокращён, возвращается ранее созданный короткий URL и ошибка дублирования.
+// Короткий URL с паролем или сроком действия создаётся всегда, см. URLOptions.deduplicated.
+func (s *MemoryStorage) AddURL(ctx context.Context, l, user string, opts URLOptions) (string, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.addURL(l, user, opts)
+}
+
+// addURL добавляет исходный длинный URL в хранилище в памяти без установки блокировки.
+func (s *MemoryStorage) addURL(l, user string, opts URLOptions) (string, error) {
+	if opts.Alias != "" {
+		err := ValidateAlias(opts.Alias)
+		if err != nil {
+			return "", err
+		}
+	}
 
-	b := make([]byte, 2)
-	_, err := rand.Read(b)
+	if opts.deduplicated() {
+		if sh, ok := s.findDuplicate(l, user, time.Now()); ok {
+			return sh, NewStorageDBError(l, true, nil)
+		}
+	}
+
+	sh, err := s.newShortURL(l, opts.Alias)
 	if err != nil {
 		return "", err
 	}
 
-	result = result + hex.EncodeToString(b)
-	log.Println("Сгененирован короткий URL:", result)
+	mr := MemoryRecord{LongURL: l, Deleted: false, User: user, ExpiresAt: opts.ExpiresAt, PasswordHash: opts.PasswordHash, CreatedAt: time.Now()}
+	s.container[sh] = mr
+	s.usersURLs[user] = append(s.usersURLs[user], sh)
+	s.indexLongURL(sh, mr)
+	return sh, nil
+}
 
-	return result, nil
+// newShortURL проверяет переданный псевдоним или генерирует новый короткий URL, если псевдоним не задан.
+func (s *MemoryStorage) newShortURL(l, alias string) (string, error) {
+	if alias != "" {
+		err := ValidateAlias(alias)
+		if err != nil {
+			return "", err
+		}
+
+		if _, ok := s.container[alias]; ok {
+			return "", ErrAliasTaken
+		}
+
+		return alias, nil
+	}
+
+	return s.generateShortURL(l)
 }
 
-// AddURL добавляет исходный длинный URL в хранилище в памяти, связывая его с созданным коротким URL.
-func (s *MemoryStorage) AddURL(l, user string) (string, error) {
-	s.locker.Lock()
-	defer s.locker.Unlock()
+// generateShortURL создаёт новый короткий URL, повторяя генерацию при совпадении с уже существующим.
+func (s *MemoryStorage) generateShortURL(l string) (string, error) {
+	generator := s.shortURLGenerator()
 
-	sh, err := generateShortURL()
-	if err != nil {
-		return "", err
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		sh, err := generator.Generate(l, attempt)
+		if err != nil {
+			return "", err
+		}
+
+		if _, ok := s.container[sh]; !ok {
+			return sh, nil
+		}
 	}
 
-	if _, ok := s.container[sh]; ok {
-		return "", errors.New("короткий URL с ID " + string(sh) + " уже существует")
+	return "", errGenerationExhausted
+}
+
+// shortURLGenerator возвращает генератор коротких URL, заданный для хранилища, или генератор по умолчанию.
+func (s *MemoryStorage) shortURLGenerator() Generator {
+	if s.generator == nil {
+		return TimeGenerator{}
 	}
 
-	s.container[sh] = MemoryRecord{LongURL: l, Deleted: false, User: user}
-	s.usersURLs[user] = append(s.usersURLs[user], sh)
-	return sh, nil
+	return s.generator
 }
 
 // AddURLs добавляет несколько исходных длинных URL в хранилище в памяти, связывая их с соответствующими созданными короткими URL.
-func (s *MemoryStorage) AddURLs(longURLs BatchURLs, user string) (BatchURLs, error) {
+// Для ранее сокращённых исходных длинных URL возвращаются существующие короткие URL с признаком дублирования.
+func (s *MemoryStorage) AddURLs(ctx context.Context, longURLs BatchURLs, user string) (BatchURLs, error) {
 	s.locker.Lock()
 	defer s.locker.Unlock()
 
 	result := make(BatchURLs, 0, len(longURLs))
 	for _, longURL := range longURLs {
-		sh, err := s.AddURL(longURL.URL, user)
-		if err != nil {
+		sh, err := s.addURL(longURL.URL, user, URLOptions{ExpiresAt: longURL.ExpiresAt})
+		duplicate := errors.Is(err, DBErrorDublicate)
+		if err != nil && !duplicate {
 			return result[:0], err
 		}
 
-		result = append(result, RecordURL{longURL.ID, sh})
+		result = append(result, RecordURL{ID: longURL.ID, URL: sh, ExpiresAt: longURL.ExpiresAt, Duplicate: duplicate})
 	}
 
 	return result, nil
 }
 
 // FindURL ищет в хранилище в памяти исходный длинный URL по заданному короткому URL.
-func (s *MemoryStorage) FindURL(sh string) (MemoryRecord, error) {
+func (s *MemoryStorage) FindURL(ctx context.Context, sh string) (MemoryRecord, error) {
 	s.locker.RLock()
 	defer s.locker.RUnlock()
 
 	result, ok := s.container[sh]
 	if !ok {
-		return MemoryRecord{"", "", false}, errors.New("короткий URL с ID \" + string(sh) + \" не существует")
+		return MemoryRecord{}, errors.New("короткий URL с ID \" + string(sh) + \" не существует")
 	}
 
 	return result, nil
 }
 
 // GetURLsByUser ищет в хранилище в памяти исходные длинные URL по заданному идентификатору пользователя, добавившего их.
-func (s *MemoryStorage) GetURLsByUser(u string) []string {
+func (s *MemoryStorage) GetURLsByUser(ctx context.Context, u string) ([]string, error) {
 	s.locker.RLock()
 	defer s.locker.RUnlock()
 
-	return s.usersURLs[u]
+	return s.usersURLs[u], nil
 }
 
 // GetStatistics возвращает статистику сервиса: количество сокращённых URL и количество пользователей.
-func (s *MemoryStorage) GetStatistics() (urls int, users int) {
+func (s *MemoryStorage) GetStatistics(ctx context.Context) (urls int, users int, err error) {
 	urls = len(s.container)
 	users = len(s.usersURLs)
 	return
 }
 
+// walkShortURLs передаёт функции все короткие URL из хранилища в памяти.
+func (s *MemoryStorage) walkShortURLs(ctx context.Context, fn func(sh string)) error {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+
+	for sh := range s.container {
+		fn(sh)
+	}
+
+	return nil
+}
+
 // CloseFunc не возвращает никакую функцию, поскольку соединение с БД не устанавливается для хранилища в памяти.
 func (s *MemoryStorage) CloseFunc() func() {
 	return nil
 }
 
 // Ping возвращает сообщение об ошибке, поскольку соединение с БД не устанавливается для хранилища в памяти.
-func (s *MemoryStorage) Ping() error {
+func (s *MemoryStorage) Ping(ctx context.Context) error {
 	return errors.New("БД не была подключена, используется хранилище в памяти")
 }
 
-// DeleteURLs добавляет заданные короткие URL в очередь на удаление из хранилища в памяти.
-func (s *MemoryStorage) DeleteURLs(shortURLs []string, user string) (deleted []string) {
-	go func() {
-		s.locker.RLock()
-		defer s.locker.RUnlock()
-
-		for _, shortURL := range shortURLs {
-			mr, ok := s.container[shortURL]
-			if !ok {
-				continue
-			}
-
-			if mr.User != user || mr.Deleted {
-				continue
-			}
-
-			s.deletionQueue <- shortURL
-		}
-	}()
+// DeleteURLs добавляет в очередь на удаление из хранилища в памяти те из заданных коротких URL, которые принадлежат пользователю.
+// Возвращает задание на удаление с состоянием каждого короткого URL из запроса.
+func (s *MemoryStorage) DeleteURLs(ctx context.Context, shortURLs []string, user string) (DeletionJob, error) {
+	s.locker.RLock()
+	results := newDeletionResults(shortURLs, func(sh string) DeletionStatus {
+		mr, ok := s.container[sh]
+		return deletionStatus(mr, ok, user)
+	})
+	s.locker.RUnlock()
 
-	return deleted
+	return s.enqueueDeletion(ctx, user, results)
 }
 
 func (s *MemoryStorage) delete(ctx context.Context, deletionBatch []string) error {
-	//s.locker.Lock()
-	//defer s.locker.Unlock()
+	return s.markDeleted(deletionBatch, nil)
+}
 
+// markDeleted помечает удалёнными существующие и ещё не удалённые короткие URL.
+// Если задана функция save, изменённые записи передаются ей до изменения хранилища в памяти под той же блокировкой:
+// при ошибке сохранения хранилище в памяти не меняется, и повторная попытка удаления сохранит записи снова.
+func (s *MemoryStorage) markDeleted(deletionBatch []string, save func(...*Record) error) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	now := time.Now()
+	deleted := make([]*Record, 0, len(deletionBatch))
 	for _, shortURL := range deletionBatch {
-		mr := s.container[shortURL]
+		mr, ok := s.container[shortURL]
+		if !ok || mr.Deleted {
+			continue
+		}
+
 		mr.Deleted = true
-		s.container[shortURL] = mr
+		mr.DeletedAt = now
+		r := newRecord(shortURL, mr)
+		deleted = append(deleted, &r)
+	}
+
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	if save != nil {
+		err := save(deleted...)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, r := range deleted {
+		mr := r.memoryRecord()
+		s.container[r.ShortURL] = mr
+		s.unindexLongURL(r.ShortURL, mr)
 	}
 
 	return nil
 }
 
-// DeletionQueueProcess обрабатывает очередь запросов на удаление, вызывая обработчик каждой записи в отдельном потоке.
+// DeletionQueueProcess обрабатывает очередь запросов на удаление в отдельном потоке.
 func (s *MemoryStorage) DeletionQueueProcess(ctx context.Context) {
-	go deletionQueueProcess(ctx, s, s.deletionQueue)
+	deletionQueueProcess(ctx, s, s.deletionQueue, s.deletionJobs)
 }
 
-func deletionQueueProcess(ctx context.Context, d deleter, deletionQueue <-chan string) {
-	deletionBatch := make([]string, DeletionBatchSize)
-
-	for {
-		select {
-		case sh, ok := <-deletionQueue:
-			if !ok {
-				return
-			}
-
-			deletionBatch = append(deletionBatch, sh)
-
-			if len(deletionBatch) >= DeletionBatchSize {
-				err := d.delete(ctx, deletionBatch)
-				if err != nil {
-					log.Println(err)
-				}
-				deletionBatch = deletionBatch[:0]
-			}
-
-		case <-ctx.Done():
-			return
-		default:
-			if len(deletionBatch) == 0 {
-				continue
-			}
-
-			err := d.delete(ctx, deletionBatch)
-			if err != nil {
-				log.Println(err)
-			}
-			deletionBatch = deletionBatch[:0]
-		}
-	}
+// DrainDeletionQueue останавливает обработку очереди на удаление и удаляет оставшиеся в ней короткие URL.
+func (s *MemoryStorage) DrainDeletionQueue(ctx context.Context) error {
+	return drainDeletionQueue(ctx, s, s.deletionQueue, s.deletionJobs)
 }
@@ -1,9 +1,21 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,24 +58,59 @@ func Test_newMemoryStorage(t *testing.T) {
 }
 
 func Test_newFileStorage(t *testing.T) {
-	type args struct {
-		m        *MemoryStorage
-		filePath string
-	}
+	valid := `{"short_url":"yandex","long_url":"http://ya.ru","user_id":"user1"}` + "\n"
+	corrupted := `{"short_url":"goo` + "\n"
+
 	tests := []struct {
-		name string
-		args args
-		want *fileStorage
+		name     string
+		content  string
+		snapshot string
+		wantURLs int
+		wantErr  bool
 	}{
-		// TODO: Add test cases.
+		{"Новый файл", "", "", 0, false},
+		{"Оборванная последняя строка", valid + corrupted, "", 1, false},
+		{"Повреждённая строка в середине файла", corrupted + valid, "", 0, true},
+		{"Повреждённый снимок", valid, corrupted, 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, newFileStorage(tt.args.m, tt.args.filePath), "newFileStorage(%v, %v)", tt.args.m, tt.args.filePath)
+			filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+			if tt.content != "" {
+				assert.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0600))
+			}
+			if tt.snapshot != "" {
+				assert.NoError(t, os.WriteFile(filePath+snapshotFileSuffix, []byte(tt.snapshot), 0600))
+			}
+
+			s, err := newFileStorage(NewMemoryStorage(), filePath)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, s)
+
+				content, err := os.ReadFile(filePath)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.content, string(content), "повреждённый файл не изменяется")
+				return
+			}
+			assert.NoError(t, err)
+			defer s.CloseFunc()()
+
+			assert.Len(t, s.container, tt.wantURLs)
 		})
 	}
 }
 
+// newTestFileStorage создаёт хранилище в файле для тестов.
+func newTestFileStorage(t *testing.T, filePath string) *fileStorage {
+	s, err := newFileStorage(NewMemoryStorage(), filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
 func Test_memoryStorage_AddURL(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -75,7 +122,7 @@ func Test_memoryStorage_AddURL(t *testing.T) {
 	}{
 		{
 			"Успешное добавление 1 элемента",
-			&MemoryStorage{map[string]MemoryRecord{}, map[string][]string{}, sync.RWMutex{}, nil, nil},
+			&MemoryStorage{container: map[string]MemoryRecord{}, usersURLs: map[string][]string{}},
 			"http://ya.ru",
 			"1111122222",
 			1,
@@ -83,7 +130,7 @@ func Test_memoryStorage_AddURL(t *testing.T) {
 		},
 		{
 			"Успешное добавление дублирующих элементов",
-			&MemoryStorage{map[string]MemoryRecord{}, map[string][]string{}, sync.RWMutex{}, nil, nil},
+			&MemoryStorage{container: map[string]MemoryRecord{}, usersURLs: map[string][]string{}},
 			"http://ya.ru",
 			"3333344444",
 			3,
@@ -93,7 +140,7 @@ func Test_memoryStorage_AddURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			for i := 0; i < 1; i++ {
-				sh, err := tt.s.AddURL(tt.URL, tt.user)
+				sh, err := tt.s.AddURL(context.Background(), tt.URL, tt.user, URLOptions{})
 				assert.NoError(t, err)
 				assert.NotEmpty(t, sh)
 			}
@@ -101,6 +148,71 @@ func Test_memoryStorage_AddURL(t *testing.T) {
 	}
 }
 
+func Test_memoryStorage_AddURLWithAlias(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     *MemoryStorage
+		alias string
+		err   error
+	}{
+		{
+			"Успешное добавление с псевдонимом",
+			&MemoryStorage{container: map[string]MemoryRecord{}, usersURLs: map[string][]string{}},
+			"spring-sale",
+			nil,
+		},
+		{
+			"Псевдоним уже занят",
+			&MemoryStorage{container: map[string]MemoryRecord{"spring-sale": {LongURL: "http://ya.ru"}}, usersURLs: map[string][]string{}},
+			"spring-sale",
+			ErrAliasTaken,
+		},
+		{
+			"Зарезервированный псевдоним",
+			&MemoryStorage{container: map[string]MemoryRecord{}, usersURLs: map[string][]string{}},
+			"API",
+			ErrAliasInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, err := tt.s.AddURL(context.Background(), "http://mail.ru", "1111122222", URLOptions{Alias: tt.alias})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.alias, sh)
+			assert.Equal(t, "http://mail.ru", tt.s.container[sh].LongURL)
+		})
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		wantErr bool
+	}{
+		{"Допустимый псевдоним", "spring_sale-2023", false},
+		{"Слишком короткий псевдоним", "ab", true},
+		{"Слишком длинный псевдоним", "abcdefghijklmnopqrstuvwxyz0123456", true},
+		{"Недопустимый символ", "spring/sale", true},
+		{"Кириллица", "весна", true},
+		{"Зарезервированное слово", "ping", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAlias(tt.alias)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrAliasInvalid)
+			}
+		})
+	}
+}
+
 func Test_memoryStorage_FindURL(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -111,32 +223,32 @@ func Test_memoryStorage_FindURL(t *testing.T) {
 	}{
 		{
 			"Неуспешная попытка поиска в пустом хранилище",
-			&MemoryStorage{map[string]MemoryRecord{}, map[string][]string{}, sync.RWMutex{}, nil, nil},
+			&MemoryStorage{container: map[string]MemoryRecord{}, usersURLs: map[string][]string{}},
 			"dummy",
 			"",
 			false,
 		},
 		{
 			"Успешная попытка поиска в списке из 1 элемента",
-			&MemoryStorage{map[string]MemoryRecord{"dummy": {"http://ya.ru", "", false}}, map[string][]string{}, sync.RWMutex{}, nil, nil},
+			&MemoryStorage{container: map[string]MemoryRecord{"dummy": {LongURL: "http://ya.ru"}}, usersURLs: map[string][]string{}},
 			"dummy",
 			"http://ya.ru",
 			true,
 		},
 		{
 			"Успешная попытка поиска в списке из 3 элементов",
-			&MemoryStorage{map[string]MemoryRecord{
-				"dummy":  {"http://ya.ru", "", false},
-				"dummy1": {"http://mail.ru", "", false},
-				"dummy2": {"http://google.ru", "", false},
-			}, map[string][]string{}, sync.RWMutex{}, nil, nil},
+			&MemoryStorage{container: map[string]MemoryRecord{
+				"dummy":  {LongURL: "http://ya.ru"},
+				"dummy1": {LongURL: "http://mail.ru"},
+				"dummy2": {LongURL: "http://google.ru"},
+			}, usersURLs: map[string][]string{}},
 			"dummy1",
 			"http://mail.ru",
 			true,
 		},
 		{
 			"Неуспешная попытка поиска в непустом списке",
-			&MemoryStorage{map[string]MemoryRecord{"dummy": {"http://ya.ru", "", false}}, map[string][]string{}, sync.RWMutex{}, nil, nil},
+			&MemoryStorage{container: map[string]MemoryRecord{"dummy": {LongURL: "http://ya.ru"}}, usersURLs: map[string][]string{}},
 			"dummy1",
 			"",
 			false,
@@ -144,7 +256,7 @@ func Test_memoryStorage_FindURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := tt.s.FindURL(tt.URL)
+			result, err := tt.s.FindURL(context.Background(), tt.URL)
 			assert.Equal(t, tt.OK, err == nil)
 			assert.Equal(t, tt.wantURL, result.LongURL)
 		})
@@ -154,45 +266,41 @@ func Test_memoryStorage_FindURL(t *testing.T) {
 func Test_fileStorage_AddURL(t *testing.T) {
 	tests := []struct {
 		name    string
-		s       fileStorage
+		s       *fileStorage
 		URL     string
 		wantURL string
 		OK      bool
 	}{
 		{
 			"Неуспешная попытка поиска в пустом хранилище",
-			fileStorage{&MemoryStorage{map[string]MemoryRecord{}, map[string][]string{}, sync.RWMutex{}, nil, nil}, nil, nil, nil},
+			&fileStorage{MemoryStorage: &MemoryStorage{container: map[string]MemoryRecord{}, usersURLs: map[string][]string{}}},
 			"dummy",
 			"",
 			false,
 		},
 		{
 			"Успешная попытка поиска в списке из 1 элемента",
-			fileStorage{&MemoryStorage{map[string]MemoryRecord{"dummy": {"http://ya.ru", "", false}}, map[string][]string{}, sync.RWMutex{}, nil, nil}, nil, nil, nil},
+			&fileStorage{MemoryStorage: &MemoryStorage{container: map[string]MemoryRecord{"dummy": {LongURL: "http://ya.ru"}}, usersURLs: map[string][]string{}}},
 			"dummy",
 			"http://ya.ru",
 			true,
 		},
 		{
 			"Успешная попытка поиска в списке из 3 элементов",
-			fileStorage{&MemoryStorage{map[string]MemoryRecord{
-				"dummy":  {"http://ya.ru", "", false},
-				"dummy1": {"http://mail.ru", "", false},
-				"dummy2": {"http://google.ru", "", false},
-			},
-				map[string][]string{},
-				sync.RWMutex{},
-				nil,
-				nil,
+			&fileStorage{MemoryStorage: &MemoryStorage{container: map[string]MemoryRecord{
+				"dummy":  {LongURL: "http://ya.ru"},
+				"dummy1": {LongURL: "http://mail.ru"},
+				"dummy2": {LongURL: "http://google.ru"},
 			},
-				nil, nil, nil},
+				usersURLs: map[string][]string{},
+			}},
 			"dummy1",
 			"http://mail.ru",
 			true,
 		},
 		{
 			"Неуспешная попытка поиска в непустом списке",
-			fileStorage{&MemoryStorage{map[string]MemoryRecord{"dummy": {"http://ya.ru", "", false}}, map[string][]string{}, sync.RWMutex{}, nil, nil}, nil, nil, nil},
+			&fileStorage{MemoryStorage: &MemoryStorage{container: map[string]MemoryRecord{"dummy": {LongURL: "http://ya.ru"}}, usersURLs: map[string][]string{}}},
 			"dummy1",
 			"",
 			false,
@@ -200,9 +308,2053 @@ func Test_fileStorage_AddURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := tt.s.FindURL(tt.URL)
+			result, err := tt.s.FindURL(context.Background(), tt.URL)
 			assert.Equal(t, tt.OK, err == nil)
 			assert.Equal(t, tt.wantURL, result.LongURL)
 		})
 	}
 }
+
+func TestNewGenerator(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		length  int
+		wantLen int
+		wantErr error
+	}{
+		{"Генератор по умолчанию", "", 0, 0, nil},
+		{"Случайный генератор", GeneratorRandom, 10, 10, nil},
+		{"Последовательный генератор", GeneratorSequence, 0, 0, nil},
+		{"Хеширующий генератор", GeneratorHash, 0, DefaultGeneratedLength, nil},
+		{"Хеширующий генератор наибольшей длины", GeneratorHash, MaxGeneratedLength, MaxGeneratedLength, nil},
+		{"Случайный генератор наименьшей длины", GeneratorRandom, MinGeneratedLength, MinGeneratedLength, nil},
+		{"Неизвестный генератор", "dummy", 0, 0, ErrGeneratorUnknown},
+		{"Слишком короткий URL", GeneratorRandom, MinGeneratedLength - 1, 0, ErrGeneratedLengthInvalid},
+		{"Слишком длинный URL", GeneratorHash, MaxGeneratedLength + 1, 0, ErrGeneratedLengthInvalid},
+		{"Отрицательная длина", GeneratorHash, -1, 0, ErrGeneratedLengthInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGenerator(tt.kind, tt.length, "salt")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			sh, err := g.Generate("http://ya.ru", 0)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, sh)
+			if tt.wantLen > 0 {
+				assert.Len(t, sh, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestSequenceGenerator_Generate(t *testing.T) {
+	g := NewSequenceGenerator("salt")
+	generated := map[string]bool{}
+
+	for i := 0; i < 1000; i++ {
+		sh, err := g.Generate("", 0)
+		assert.NoError(t, err)
+		assert.False(t, generated[sh], "повторный короткий URL %s", sh)
+		generated[sh] = true
+	}
+
+	other := NewSequenceGenerator("other salt")
+	sh1, _ := NewSequenceGenerator("salt").Generate("", 0)
+	sh2, _ := other.Generate("", 0)
+	assert.NotEqual(t, sh1, sh2)
+}
+
+func TestSequenceGenerator_Position(t *testing.T) {
+	g := NewSequenceGenerator("salt")
+
+	for n := uint64(1); n <= 1000; n++ {
+		sh, err := g.Generate("", 0)
+		assert.NoError(t, err)
+
+		position, ok := g.Position(sh)
+		assert.True(t, ok)
+		assert.Equal(t, n, position)
+	}
+
+	tests := []struct {
+		name     string
+		shortURL string
+	}{
+		{"Пустая строка", ""},
+		{"Символ не из алфавита", "my-alias"},
+		{"Слишком длинная строка", "abcdefghijk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := g.Position(tt.shortURL)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func Test_seedSequence(t *testing.T) {
+	ctx := context.Background()
+
+	m := NewMemoryStorage()
+	m.generator = NewSequenceGenerator("salt")
+
+	shortURLs := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		sh, err := m.AddURL(ctx, "http://ya.ru/"+strconv.Itoa(i), "user1", URLOptions{})
+		assert.NoError(t, err)
+		shortURLs = append(shortURLs, sh)
+	}
+	delete(m.container, shortURLs[0])
+	delete(m.container, shortURLs[1])
+
+	generator := NewSequenceGenerator("salt")
+	m.generator = generator
+	assert.NoError(t, seedSequence(ctx, m, generator))
+
+	sh, err := generator.Generate("", 0)
+	assert.NoError(t, err)
+	position, _ := generator.Position(sh)
+	assert.Equal(t, uint64(6), position, "нумерация продолжается после наибольшего номера, а не количества записей")
+}
+
+func Test_redisStorage_sequence(t *testing.T) {
+	ctx := context.Background()
+	s, server := newTestRedisStorage(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.AddURL(ctx, "http://ya.ru/"+strconv.Itoa(i), "user1", URLOptions{})
+		assert.NoError(t, err)
+	}
+
+	replicas := make([]*SequenceGenerator, 2)
+	for i := range replicas {
+		m := NewMemoryStorage()
+		m.DeletionCancel = func() {}
+		replica, err := NewRedisStorage(ctx, m, server.Addr())
+		assert.NoError(t, err)
+		t.Cleanup(replica.CloseFunc())
+
+		replicas[i] = NewSequenceGenerator("salt")
+		assert.NoError(t, seedSequence(ctx, replica, replicas[i]))
+	}
+
+	generated := map[string]bool{}
+	for i := 0; i < 10; i++ {
+		sh, err := replicas[i%2].Generate("", 0)
+		assert.NoError(t, err)
+		assert.False(t, generated[sh], "экземпляры сервиса не выдают одинаковые короткие URL")
+		generated[sh] = true
+
+		position, _ := replicas[0].Position(sh)
+		assert.Equal(t, uint64(4+i), position)
+	}
+}
+
+func TestHashGenerator_Generate(t *testing.T) {
+	g := HashGenerator{Length: DefaultGeneratedLength}
+
+	sh1, err := g.Generate("http://ya.ru", 0)
+	assert.NoError(t, err)
+	sh2, _ := g.Generate("http://ya.ru", 0)
+	assert.Equal(t, sh1, sh2)
+
+	sh3, _ := g.Generate("http://ya.ru", 1)
+	assert.NotEqual(t, sh1, sh3)
+
+	long, err := HashGenerator{Length: MaxGeneratedLength}.Generate("http://ya.ru", 0)
+	assert.NoError(t, err)
+	assert.Len(t, long, MaxGeneratedLength)
+	assert.Equal(t, sh1, long[:DefaultGeneratedLength], "длинный URL начинается с короткого")
+	assert.NotContains(t, long[hashChunkLength:], long[:hashChunkLength], "фрагменты хеша не повторяются")
+}
+
+func Test_memoryStorage_generateShortURL(t *testing.T) {
+	s := NewMemoryStorage()
+	s.generator = HashGenerator{Length: DefaultGeneratedLength}
+	s.dedup = DedupUser
+
+	sh1, err := s.AddURL(context.Background(), "http://ya.ru", "1111122222", URLOptions{})
+	assert.NoError(t, err)
+	sh2, err := s.AddURL(context.Background(), "http://ya.ru", "3333344444", URLOptions{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, sh1, sh2)
+}
+
+func TestNewExpiration(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expiresAt  time.Time
+		ttlSeconds int64
+		want       time.Time
+		wantErr    bool
+	}{
+		{"Бессрочный URL", time.Time{}, 0, time.Time{}, false},
+		{"Время жизни в секундах", time.Time{}, 3600, now.Add(time.Hour), false},
+		{"Абсолютный момент окончания", now.Add(24 * time.Hour), 0, now.Add(24 * time.Hour), false},
+		{"Момент окончания в прошлом", now.Add(-time.Hour), 0, time.Time{}, true},
+		{"Отрицательное время жизни", time.Time{}, -1, time.Time{}, true},
+		{"Заданы оба параметра", now.Add(time.Hour), 60, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewExpiration(tt.expiresAt, tt.ttlSeconds, now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrExpirationInvalid)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_memoryStorage_expired(t *testing.T) {
+	now := time.Now()
+	s := &MemoryStorage{container: map[string]MemoryRecord{
+		"dummy":  {LongURL: "http://ya.ru"},
+		"dummy1": {LongURL: "http://mail.ru", ExpiresAt: now.Add(-time.Minute)},
+		"dummy2": {LongURL: "http://google.ru", ExpiresAt: now.Add(time.Minute)},
+		"dummy3": {LongURL: "http://go.dev", ExpiresAt: now.Add(-time.Minute), Deleted: true},
+	}, usersURLs: map[string][]string{}}
+
+	assert.Equal(t, []string{"dummy1"}, s.expired(now))
+
+	err := s.delete(context.Background(), s.expired(now))
+	assert.NoError(t, err)
+	assert.True(t, s.container["dummy1"].Deleted)
+	assert.Empty(t, s.expired(now))
+}
+
+func Test_fileStorage_loadExpiration(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	s := newTestFileStorage(t, filePath)
+	sh, err := s.AddURL(context.Background(), "http://ya.ru", "1111122222", URLOptions{ExpiresAt: expiresAt})
+	assert.NoError(t, err)
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	result, err := loaded.FindURL(context.Background(), sh)
+	assert.NoError(t, err)
+	assert.True(t, expiresAt.Equal(result.ExpiresAt))
+}
+
+func Test_fileStorage_AddClicks(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+	clicks := []Click{
+		{ShortURL: "dummy", Time: time.Now().UTC().Truncate(time.Second), VisitorHash: "a"},
+		{ShortURL: "dummy", Time: time.Now().UTC().Truncate(time.Second), VisitorHash: "b"},
+		{ShortURL: "dummy1", Time: time.Now().UTC().Truncate(time.Second), VisitorHash: "a"},
+	}
+
+	s := newTestFileStorage(t, filePath)
+	assert.NoError(t, s.AddClicks(context.Background(), clicks))
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	result, err := loaded.GetClicks(context.Background(), "dummy")
+	assert.NoError(t, err)
+	assert.Equal(t, clicks[:2], result)
+}
+
+func TestMemoryRecord_CheckPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	assert.NoError(t, err)
+	assert.NotContains(t, hash, "secret")
+
+	tests := []struct {
+		name     string
+		record   MemoryRecord
+		password string
+		want     bool
+	}{
+		{"Незащищённый URL", MemoryRecord{LongURL: "http://ya.ru"}, "", true},
+		{"Верный пароль", MemoryRecord{LongURL: "http://ya.ru", PasswordHash: hash}, "secret", true},
+		{"Неверный пароль", MemoryRecord{LongURL: "http://ya.ru", PasswordHash: hash}, "wrong", false},
+		{"Пустой пароль", MemoryRecord{LongURL: "http://ya.ru", PasswordHash: hash}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.record.CheckPassword(tt.password))
+		})
+	}
+}
+
+func Test_recordCache(t *testing.T) {
+	now := time.Now()
+	c := newRecordCache(2, time.Minute)
+	c.add("a", MemoryRecord{LongURL: "http://a.ru"}, now)
+	c.add("b", MemoryRecord{LongURL: "http://b.ru"}, now)
+
+	_, ok := c.get("a", now)
+	assert.True(t, ok)
+
+	c.add("c", MemoryRecord{LongURL: "http://c.ru"}, now)
+
+	tests := []struct {
+		name string
+		sh   string
+		now  time.Time
+		want bool
+	}{
+		{"Недавно использованная запись", "a", now, true},
+		{"Вытесненная запись", "b", now, false},
+		{"Новая запись", "c", now, true},
+		{"Устаревшая запись", "c", now.Add(time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := c.get(tt.sh, tt.now)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+
+	c.remove("a")
+	_, ok = c.get("a", now)
+	assert.False(t, ok)
+
+	disabled := newRecordCache(0, time.Minute)
+	disabled.add("a", MemoryRecord{}, now)
+	_, ok = disabled.get("a", now)
+	assert.False(t, ok)
+}
+
+func Test_loadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int
+		wantErr  bool
+	}{
+		{
+			"Корректный набор миграций",
+			fstest.MapFS{
+				"m/0002_second.up.sql":   {Data: []byte("SELECT 2")},
+				"m/0002_second.down.sql": {Data: []byte("SELECT -2")},
+				"m/0001_first.up.sql":    {Data: []byte("SELECT 1")},
+				"m/0001_first.down.sql":  {Data: []byte("SELECT -1")},
+			},
+			[]int{1, 2},
+			false,
+		},
+		{
+			"Нет файла отката",
+			fstest.MapFS{
+				"m/0001_first.up.sql": {Data: []byte("SELECT 1")},
+			},
+			nil,
+			true,
+		},
+		{
+			"Пропущен номер миграции",
+			fstest.MapFS{
+				"m/0001_first.up.sql":   {Data: []byte("SELECT 1")},
+				"m/0001_first.down.sql": {Data: []byte("SELECT -1")},
+				"m/0003_third.up.sql":   {Data: []byte("SELECT 3")},
+				"m/0003_third.down.sql": {Data: []byte("SELECT -3")},
+			},
+			nil,
+			true,
+		},
+		{
+			"Неверное имя файла",
+			fstest.MapFS{
+				"m/first.up.sql": {Data: []byte("SELECT 1")},
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files, "m")
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrMigrationInvalid)
+				return
+			}
+
+			assert.NoError(t, err)
+			versions := make([]int, 0, len(migrations))
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+				assert.NotEmpty(t, m.Up)
+				assert.NotEmpty(t, m.Down)
+			}
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+
+	embedded, err := loadMigrations(migrationFiles, "migrations")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, embedded)
+}
+
+// newTestRedisStorage создаёт хранилище, подключённое к Redis-совместимому серверу, работающему внутри теста.
+func newTestRedisStorage(t *testing.T) (*RedisStorage, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+
+	m := NewMemoryStorage()
+	m.generator = NewSequenceGenerator("salt")
+	m.DeletionCancel = func() {}
+
+	s, err := NewRedisStorage(context.Background(), m, server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.CloseFunc())
+
+	return s, server
+}
+
+// watchHook выполняет заданную функцию после первой команды WATCH, чтобы имитировать
+// изменение ключа другим экземпляром сервиса во время транзакции.
+type watchHook struct {
+	once sync.Once
+	fn   func()
+}
+
+func (h *watchHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *watchHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if cmd.Name() == "watch" {
+			h.once.Do(h.fn)
+		}
+		return err
+	}
+}
+
+func (h *watchHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRedisStorage_claimLongURLConcurrent(t *testing.T) {
+	ctx := context.Background()
+	s, server := newTestRedisStorage(t)
+
+	const longURL = "http://ya.ru"
+	_, err := s.AddURL(ctx, longURL, "user1", URLOptions{Alias: "old"})
+	assert.NoError(t, err)
+	assert.NoError(t, s.delete(ctx, []string{"old"}))
+
+	fresh, err := json.Marshal(Record{ShortURL: "fresh", LongURL: longURL, UserID: "user1"})
+	assert.NoError(t, err)
+	server.Set(redisURLKey+"fresh", string(fresh))
+
+	longKey := redisLongURLKey + s.dedupKey(longURL, "user1")
+	// Связь с удалённым коротким URL устарела, но осталась в обратном индексе.
+	server.Set(longKey, "old")
+	s.client.AddHook(&watchHook{fn: func() {
+		server.Set(longKey, "fresh")
+	}})
+
+	existing, duplicate, err := s.claimLongURL(ctx, "new", longURL, "user1")
+	assert.NoError(t, err)
+	assert.True(t, duplicate, "связь, установленная другим экземпляром во время замены устаревшей, не затирается")
+	assert.Equal(t, "fresh", existing)
+
+	indexed, err := server.Get(longKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "fresh", indexed)
+}
+
+func TestNewRedisStorage_unreachable(t *testing.T) {
+	server := miniredis.RunT(t)
+	address := server.Addr()
+	server.Close()
+
+	s, err := NewRedisStorage(context.Background(), NewMemoryStorage(), address)
+	assert.Error(t, err, "при недоступном сервере хранилище в памяти не используется")
+	assert.Nil(t, s)
+}
+
+func Test_redisStorage_AddURLsConcurrent(t *testing.T) {
+	ctx := context.Background()
+	s, server := newTestRedisStorage(t)
+
+	m := NewMemoryStorage()
+	m.generator = RandomGenerator{Length: 8}
+	m.DeletionCancel = func() {}
+	other, err := NewRedisStorage(ctx, m, server.Addr())
+	assert.NoError(t, err)
+	t.Cleanup(other.CloseFunc())
+
+	const attempts = 20
+	for i := 0; i < attempts; i++ {
+		longURL := "http://ya.ru/" + strconv.Itoa(i)
+
+		results := make([]BatchURLs, 2)
+		var wg sync.WaitGroup
+		for j, storage := range []*RedisStorage{s, other} {
+			wg.Add(1)
+			go func(j int, storage *RedisStorage) {
+				defer wg.Done()
+				result, err := storage.AddURLs(ctx, BatchURLs{{ID: "1", URL: longURL}}, "user1")
+				assert.NoError(t, err)
+				results[j] = result
+			}(j, storage)
+		}
+		wg.Wait()
+
+		assert.Equal(t, results[0][0].URL, results[1][0].URL, "исходный длинный URL сокращается одним коротким URL")
+		assert.True(t, results[0][0].Duplicate || results[1][0].Duplicate)
+
+		indexed, err := server.Get(redisLongURLKey + s.dedupKey(longURL, "user1"))
+		assert.NoError(t, err)
+		assert.Equal(t, results[0][0].URL, indexed)
+	}
+
+	urls, _, err := s.GetStatistics(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, attempts, urls, "лишние записи удаляются")
+}
+
+func Test_redisDeletionJobs(t *testing.T) {
+	ctx := context.Background()
+	s, server := newTestRedisStorage(t)
+
+	m := NewMemoryStorage()
+	m.DeletionCancel = func() {}
+	other, err := NewRedisStorage(ctx, m, server.Addr())
+	assert.NoError(t, err)
+	t.Cleanup(other.CloseFunc())
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	job, err := s.DeleteURLs(ctx, []string{sh}, "user1")
+	assert.NoError(t, err)
+
+	got, err := other.GetDeletionJob(ctx, job.ID, "user1")
+	assert.NoError(t, err, "задание доступно другому экземпляру сервиса")
+	assert.False(t, got.Done())
+
+	_, err = other.GetDeletionJob(ctx, job.ID, "user2")
+	assert.ErrorIs(t, err, ErrDeletionJobNotFound)
+
+	assert.NoError(t, s.DrainDeletionQueue(ctx))
+
+	got, err = other.GetDeletionJob(ctx, job.ID, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []DeletionResult{{sh, DeletionDeleted}}, got.Results)
+
+	server.FastForward(DeletionJobRetention + time.Second)
+	_, err = other.GetDeletionJob(ctx, job.ID, "user1")
+	assert.ErrorIs(t, err, ErrDeletionJobNotFound, "устаревшее задание удаляется")
+}
+
+func Test_redisStorage_AddURL(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestRedisStorage(t)
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		longURL   string
+		opts      URLOptions
+		want      string
+		wantErr   error
+		wantDuple bool
+	}{
+		{
+			name:      "Повторное сокращение URL",
+			longURL:   "http://ya.ru",
+			want:      sh,
+			wantDuple: true,
+		},
+		{
+			name:    "Сокращение с псевдонимом",
+			longURL: "http://google.com",
+			opts:    URLOptions{Alias: "google"},
+			want:    "google",
+		},
+		{
+			name:    "Занятый псевдоним",
+			longURL: "http://mail.ru",
+			opts:    URLOptions{Alias: "google"},
+			wantErr: ErrAliasTaken,
+		},
+		{
+			name:    "Неверный псевдоним",
+			longURL: "http://mail.ru",
+			opts:    URLOptions{Alias: "a b"},
+			wantErr: ErrAliasInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.AddURL(ctx, tt.longURL, "user1", tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			if tt.wantDuple {
+				assert.ErrorIs(t, err, DBErrorDublicate)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+
+			record, err := s.FindURL(ctx, got)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.longURL, record.LongURL)
+			assert.Equal(t, "user1", record.User)
+		})
+	}
+
+	_, err = s.FindURL(ctx, "unknown")
+	assert.Error(t, err)
+}
+
+func Test_redisStorage_AddURLs(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestRedisStorage(t)
+
+	batch := BatchURLs{{ID: "1", URL: "http://ya.ru"}, {ID: "2", URL: "http://google.com"}}
+	result, err := s.AddURLs(ctx, batch, "user1")
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	for i, r := range result {
+		assert.Equal(t, batch[i].ID, r.ID)
+
+		record, err := s.FindURL(ctx, r.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, batch[i].URL, record.LongURL)
+	}
+
+	userURLs, err := s.GetURLsByUser(ctx, "user1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{result[0].URL, result[1].URL}, userURLs)
+
+	urls, users, err := s.GetStatistics(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, urls)
+	assert.Equal(t, 1, users)
+}
+
+func Test_redisStorage_delete(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestRedisStorage(t)
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.delete(ctx, []string{"", "unknown", sh}))
+
+	record, err := s.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.True(t, record.Deleted)
+
+	sh1, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err, "удалённый исходный URL можно сократить повторно")
+	assert.NotEqual(t, sh, sh1)
+}
+
+func Test_redisStorage_expired(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestRedisStorage(t)
+
+	now := time.Now()
+	expired, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{ExpiresAt: now.Add(-time.Minute)})
+	assert.NoError(t, err)
+	_, err = s.AddURL(ctx, "http://google.com", "user1", URLOptions{ExpiresAt: now.Add(time.Hour)})
+	assert.NoError(t, err)
+	_, err = s.AddURL(ctx, "http://mail.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{expired}, s.expired(now))
+}
+
+func Test_redisStorage_AddClicks(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestRedisStorage(t)
+
+	clicks := []Click{
+		{ShortURL: "dummy", Time: time.Now().UTC().Truncate(time.Second), VisitorHash: "a"},
+		{ShortURL: "dummy1", Time: time.Now().UTC().Truncate(time.Second), VisitorHash: "b"},
+	}
+	assert.NoError(t, s.AddClicks(ctx, clicks))
+
+	result, err := s.GetClicks(ctx, "dummy")
+	assert.NoError(t, err)
+	assert.Equal(t, clicks[:1], result)
+}
+
+func TestNewBoltStorage_unavailable(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "shurl.db")
+	opened := newTestBoltStorage(t, filePath)
+	defer opened.CloseFunc()()
+
+	tests := []struct {
+		name     string
+		filePath string
+	}{
+		{"Файл заблокирован другим экземпляром", filePath},
+		{"Каталог не существует", filepath.Join(t.TempDir(), "missing", "shurl.db")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewBoltStorage(NewMemoryStorage(), tt.filePath)
+			assert.Error(t, err, "если файл встроенной БД не открыт, хранилище в памяти не используется")
+			assert.Nil(t, s)
+		})
+	}
+}
+
+// newTestBoltStorage создаёт хранилище во встроенной БД в файле во временном каталоге теста.
+func newTestBoltStorage(t *testing.T, filePath string) *BoltStorage {
+	m := NewMemoryStorage()
+	m.generator = NewSequenceGenerator("salt")
+	m.DeletionCancel = func() {}
+
+	s, err := NewBoltStorage(m, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func Test_boltStorage_AddURL(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurl.db")
+	s := newTestBoltStorage(t, filePath)
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{ExpiresAt: time.Now().Add(-time.Minute)})
+	assert.NoError(t, err)
+
+	alias, err := s.AddURL(ctx, "http://google.com", "user2", URLOptions{Alias: "google"})
+	assert.NoError(t, err)
+	assert.Equal(t, "google", alias)
+
+	dup, err := s.AddURL(ctx, "http://google.com", "user1", URLOptions{})
+	assert.ErrorIs(t, err, DBErrorDublicate)
+	assert.Equal(t, "google", dup)
+
+	_, err = s.AddURL(ctx, "http://mail.ru", "user2", URLOptions{Alias: "google"})
+	assert.ErrorIs(t, err, ErrAliasTaken)
+
+	batch, err := s.AddURLs(ctx, BatchURLs{
+		{ID: "1", URL: "http://mail.ru"},
+		{ID: "2", URL: "http://ok.ru"},
+		{ID: "3", URL: "http://google.com"},
+		{ID: "4", URL: "http://mail.ru"},
+	}, "user1")
+	assert.NoError(t, err)
+	assert.Len(t, batch, 4)
+	assert.Equal(t, RecordURL{ID: "3", URL: "google", Duplicate: true}, batch[2])
+	assert.Equal(t, RecordURL{ID: "4", URL: batch[0].URL, Duplicate: true}, batch[3])
+
+	assert.Equal(t, []string{sh}, s.expired(time.Now()))
+	s.CloseFunc()()
+
+	reopened := newTestBoltStorage(t, filePath)
+	defer reopened.CloseFunc()()
+
+	record, err := reopened.FindURL(ctx, "google")
+	assert.NoError(t, err)
+	assert.False(t, record.CreatedAt.IsZero())
+	record.CreatedAt = time.Time{}
+	assert.Equal(t, MemoryRecord{LongURL: "http://google.com", User: "user2"}, record)
+
+	_, err = reopened.FindURL(ctx, "unknown")
+	assert.Error(t, err)
+
+	userURLs, err := reopened.GetURLsByUser(ctx, "user1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{sh, batch[0].URL, batch[1].URL}, userURLs)
+
+	urls, users, err := reopened.GetStatistics(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, urls)
+	assert.Equal(t, 2, users)
+}
+
+func Test_boltStorage_delete(t *testing.T) {
+	ctx := context.Background()
+	s := newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))
+	defer s.CloseFunc()()
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{ExpiresAt: time.Now().Add(-time.Minute)})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.delete(ctx, []string{"", "unknown", sh}))
+
+	record, err := s.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.True(t, record.Deleted)
+	assert.Empty(t, s.expired(time.Now()))
+
+	sh1, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err, "удалённый исходный URL можно сократить повторно")
+	assert.NotEqual(t, sh, sh1)
+
+	urls, _, err := s.GetStatistics(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, urls)
+}
+
+func Test_boltStorage_Import(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "shurldb.txt")
+
+	f := newTestFileStorage(t, filePath)
+	_, err := f.AddURL(ctx, "http://ya.ru", "user1", URLOptions{Alias: "yandex"})
+	assert.NoError(t, err)
+	_, err = f.AddURL(ctx, "http://google.com", "user2", URLOptions{Alias: "google", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	assert.NoError(t, f.AddClicks(ctx, []Click{{ShortURL: "yandex", Time: time.Now().UTC().Truncate(time.Second)}}))
+	f.CloseFunc()()
+
+	s := newTestBoltStorage(t, filepath.Join(dir, "shurl.db"))
+	defer s.CloseFunc()()
+
+	for i := 0; i < 2; i++ {
+		result, err := s.Import(ctx, filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, ImportResult{Records: 2, Clicks: 1}, result)
+	}
+
+	record, err := s.FindURL(ctx, "google")
+	assert.NoError(t, err)
+	assert.False(t, record.CreatedAt.IsZero())
+	record.CreatedAt = time.Time{}
+	assert.Equal(t, MemoryRecord{LongURL: "http://google.com", User: "user2", PasswordHash: "hash"}, record)
+
+	_, err = s.AddURL(ctx, "http://ya.ru", "user3", URLOptions{})
+	assert.ErrorIs(t, err, DBErrorDublicate)
+
+	clicks, err := s.GetClicks(ctx, "yandex")
+	assert.NoError(t, err)
+	assert.Len(t, clicks, 1)
+
+	urls, users, err := s.GetStatistics(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, urls)
+	assert.Equal(t, 2, users)
+}
+
+func Test_boltStorage_ImportSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "shurldb.txt")
+
+	f := newTestFileStorage(t, filePath)
+	f.compaction = CompactionOptions{Snapshot: true}
+	_, err := f.AddURL(ctx, "http://ya.ru", "user1", URLOptions{Alias: "yandex"})
+	assert.NoError(t, err)
+	_, err = f.Compact(ctx)
+	assert.NoError(t, err)
+	_, err = f.AddURL(ctx, "http://google.com", "user2", URLOptions{Alias: "google"})
+	assert.NoError(t, err)
+	assert.NoError(t, f.UpdateURL(ctx, "yandex", "http://ya.ru/updated", "user1"))
+	f.CloseFunc()()
+
+	s := newTestBoltStorage(t, filepath.Join(dir, "shurl.db"))
+	defer s.CloseFunc()()
+
+	result, err := s.Import(ctx, filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Records, "записи снимка и журнала изменений загружаются вместе")
+
+	record, err := s.FindURL(ctx, "yandex")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://ya.ru/updated", record.LongURL, "записи журнала изменений заменяют записи снимка")
+
+	_, err = s.FindURL(ctx, "google")
+	assert.NoError(t, err)
+}
+
+func Test_fileStorage_Compact(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot bool
+	}{
+		{"Перезапись основного файла", false},
+		{"Запись снимка и очистка журнала", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+			s := newTestFileStorage(t, filePath)
+			s.compaction = CompactionOptions{Ratio: 1.5, Snapshot: tt.snapshot}
+
+			_, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{Alias: "yandex"})
+			assert.NoError(t, err)
+			_, err = s.AddURL(ctx, "http://google.com", "user1", URLOptions{Alias: "google"})
+			assert.NoError(t, err)
+			assert.False(t, s.needsCompaction())
+
+			for i := 0; i < 2; i++ {
+				assert.NoError(t, s.saveToFile(&Record{ShortURL: "yandex", LongURL: "http://ya.ru", UserID: "user1"}))
+			}
+			assert.True(t, s.needsCompaction())
+
+			deletedAt := time.Now().Add(-DefaultRestoreGracePeriod - time.Minute)
+			for sh, at := range map[string]time.Time{"restorable": time.Now(), "expired": deletedAt} {
+				record := Record{ShortURL: sh, LongURL: "http://" + sh + ".example", UserID: "user1", Deleted: true, DeletedAt: &at}
+				s.loadRecord(&record)
+				assert.NoError(t, s.saveToFile(&record))
+			}
+
+			result, err := s.Compact(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 6, result.LinesBefore)
+			assert.Equal(t, 4, result.LinesAfter)
+			assert.Less(t, result.SizeAfter, result.SizeBefore)
+			assert.Equal(t, MemoryRecord{Deleted: true}, s.container["expired"],
+				"после срока восстановления от удалённого короткого URL остаётся только признак удаления")
+			assert.NotContains(t, s.usersURLs["user1"], "expired")
+
+			_, err = os.Stat(filePath + snapshotFileSuffix)
+			assert.Equal(t, tt.snapshot, err == nil)
+
+			_, err = s.AddURL(ctx, "http://mail.ru", "user2", URLOptions{Alias: "mail"})
+			assert.NoError(t, err)
+			s.CloseFunc()()
+
+			loaded := newTestFileStorage(t, filePath)
+			defer loaded.CloseFunc()()
+
+			assert.Equal(t, 5, loaded.lines)
+			assert.Len(t, loaded.container, 5)
+			assert.Equal(t, MemoryRecord{Deleted: true}, loaded.container["expired"], "удалённый короткий URL остаётся занятым после перезапуска")
+			assert.ElementsMatch(t, []string{"yandex", "google", "restorable"}, loaded.usersURLs["user1"])
+
+			_, err = loaded.AddURL(ctx, "http://other.example", "user2", URLOptions{Alias: "expired"})
+			assert.ErrorIs(t, err, ErrAliasTaken)
+
+			var seen []string
+			assert.NoError(t, loaded.walkShortURLs(ctx, func(sh string) { seen = append(seen, sh) }))
+			assert.Contains(t, seen, "expired", "короткий URL учитывается при инициализации генератора")
+		})
+	}
+}
+
+func Test_fileStorage_delete(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	_, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{Alias: "yandex"})
+	assert.NoError(t, err)
+	_, err = s.AddURL(ctx, "http://google.com", "user1", URLOptions{Alias: "google"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.delete(ctx, []string{"yandex", "unknown"}))
+	assert.NoError(t, s.delete(ctx, []string{"yandex"}))
+	assert.Equal(t, 3, s.lines, "повторное удаление не записывается в файл")
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	record, err := loaded.FindURL(ctx, "yandex")
+	assert.NoError(t, err)
+	assert.True(t, record.Deleted)
+
+	record, err = loaded.FindURL(ctx, "google")
+	assert.NoError(t, err)
+	assert.False(t, record.Deleted)
+
+	_, err = loaded.FindURL(ctx, "unknown")
+	assert.Error(t, err)
+}
+
+// failingWriter возвращает ошибку при любой записи.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("ошибка записи")
+}
+
+func Test_fileStorage_deleteWriteError(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	_, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{Alias: "yandex"})
+	assert.NoError(t, err)
+
+	encoder := s.encoder
+	s.encoder = json.NewEncoder(failingWriter{})
+	assert.Error(t, s.delete(ctx, []string{"yandex"}))
+
+	record, err := s.FindURL(ctx, "yandex")
+	assert.NoError(t, err)
+	assert.False(t, record.Deleted, "при ошибке записи в файл запись в памяти не меняется")
+
+	s.encoder = encoder
+	assert.NoError(t, s.delete(ctx, []string{"yandex"}), "повторная попытка удаления записывает запись в файл")
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	record, err = loaded.FindURL(ctx, "yandex")
+	assert.NoError(t, err)
+	assert.True(t, record.Deleted)
+}
+
+func Test_fileStorage_manageWriteError(t *testing.T) {
+	ctx := context.Background()
+	s := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer s.CloseFunc()()
+
+	_, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{Alias: "yandex"})
+	assert.NoError(t, err)
+	_, err = s.AddURL(ctx, "http://google.com", "user1", URLOptions{Alias: "google"})
+	assert.NoError(t, err)
+	assert.NoError(t, s.delete(ctx, []string{"google"}))
+
+	s.encoder = json.NewEncoder(failingWriter{})
+
+	assert.Error(t, s.UpdateURL(ctx, "yandex", "http://ya.ru/new", "user1"))
+	record, err := s.FindURL(ctx, "yandex")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://ya.ru", record.LongURL, "при ошибке записи в файл исходный URL не меняется")
+	sh, ok := s.findDuplicate("http://ya.ru", "user1", time.Now())
+	assert.True(t, ok && sh == "yandex", "при ошибке записи в файл индекс исходных URL не меняется")
+
+	assert.Error(t, s.RestoreURL(ctx, "google", "user1"))
+	record, err = s.FindURL(ctx, "google")
+	assert.NoError(t, err)
+	assert.True(t, record.Deleted, "при ошибке записи в файл удаление не отменяется")
+
+	assert.Error(t, s.FlagURL(ctx, "yandex", true))
+	record, err = s.FindURL(ctx, "yandex")
+	assert.NoError(t, err)
+	assert.False(t, record.Flagged, "при ошибке записи в файл пометка не меняется")
+}
+
+func Test_fileStorage_loadFromFileTorn(t *testing.T) {
+	valid := `{"short_url":"yandex","long_url":"http://ya.ru","user_id":"user1"}` + "\n"
+
+	var corrupted bytes.Buffer
+	assert.NoError(t, encodeRecord(json.NewEncoder(&corrupted), &Record{ShortURL: "google", LongURL: "http://google.com", UserID: "user1"}))
+	badChecksum := strings.Replace(corrupted.String(), "google.com", "goo9le.com", 1)
+
+	tests := []struct {
+		name      string
+		content   string
+		wantURLs  int
+		wantSize  int64
+		wantError error
+	}{
+		{"Целый файл", valid + corrupted.String(), 2, int64(len(valid) + corrupted.Len()), nil},
+		{"Оборванная последняя строка", valid + `{"short_url":"goo`, 1, int64(len(valid)), nil},
+		{"Последняя строка без перевода строки", valid + strings.TrimSuffix(corrupted.String(), "\n"), 1, int64(len(valid)), nil},
+		{"Неверная контрольная сумма в последней строке", valid + badChecksum, 1, int64(len(valid)), nil},
+		{"Повреждённая строка в середине файла", badChecksum + valid, 0, 0, ErrRecordCorrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+			assert.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0600))
+
+			s := &fileStorage{MemoryStorage: NewMemoryStorage()}
+			assert.NoError(t, s.openFile(filePath))
+			defer s.CloseFunc()()
+
+			err := s.loadFromFile()
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, s.container, tt.wantURLs)
+
+			info, err := os.Stat(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSize, info.Size())
+		})
+	}
+}
+
+func TestNewDedupScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   string
+		want    DedupScope
+		wantErr bool
+	}{
+		{"По умолчанию", "", DedupGlobal, false},
+		{"Глобальная область", "global", DedupGlobal, false},
+		{"Область пользователя", "user", DedupUser, false},
+		{"Неизвестная область", "tenant", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDedupScope(tt.scope)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrDedupScopeUnknown)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_memoryStorage_dedup(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		scope         DedupScope
+		wantDuplicate bool
+	}{
+		{"Глобальная область", DedupGlobal, true},
+		{"Область пользователя", DedupUser, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStorage()
+			s.dedup = tt.scope
+
+			sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+
+			sh1, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.ErrorIs(t, err, DBErrorDublicate)
+			assert.Equal(t, sh, sh1)
+
+			sh2, err := s.AddURL(ctx, "http://ya.ru", "user2", URLOptions{})
+			if tt.wantDuplicate {
+				assert.ErrorIs(t, err, DBErrorDublicate)
+				assert.Equal(t, sh, sh2)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, sh, sh2)
+			}
+
+			assert.NoError(t, s.delete(ctx, []string{sh}))
+			sh3, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err, "удалённый исходный URL можно сократить повторно")
+			assert.NotEqual(t, sh, sh3)
+		})
+	}
+}
+
+func TestStorager_dedupOptions(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+
+	tests := []struct {
+		name     string
+		existing URLOptions
+		opts     URLOptions
+	}{
+		{"Запрос с паролем к ранее сокращённому URL", URLOptions{}, URLOptions{PasswordHash: "hash"}},
+		{"Запрос со сроком действия к ранее сокращённому URL", URLOptions{}, URLOptions{ExpiresAt: expiresAt}},
+		{"Запрос без пароля к URL, защищённому паролем", URLOptions{PasswordHash: "hash"}, URLOptions{}},
+		{"Запрос без срока действия к URL со сроком действия", URLOptions{ExpiresAt: expiresAt}, URLOptions{}},
+	}
+	for _, tt := range tests {
+		redisStorage, _ := newTestRedisStorage(t)
+		fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+		defer fileStorage.CloseFunc()()
+
+		storages := []struct {
+			name    string
+			storage Storager
+		}{
+			{"в памяти", NewMemoryStorage()},
+			{"в файле", fileStorage},
+			{"во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+			{"на Redis-совместимом сервере", redisStorage},
+		}
+		for _, st := range storages {
+			t.Run(tt.name+", хранилище "+st.name, func(t *testing.T) {
+				existing, err := st.storage.AddURL(ctx, "http://ya.ru", "user1", tt.existing)
+				assert.NoError(t, err)
+
+				sh, err := st.storage.AddURL(ctx, "http://ya.ru", "user2", tt.opts)
+				assert.NoError(t, err, "короткий URL с паролем или сроком действия не считается дублирующимся")
+				assert.NotEqual(t, existing, sh)
+
+				mr, err := st.storage.FindURL(ctx, sh)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.opts.PasswordHash, mr.PasswordHash)
+				assert.True(t, tt.opts.ExpiresAt.Equal(mr.ExpiresAt))
+				assert.Equal(t, "user2", mr.User)
+			})
+		}
+	}
+}
+
+func TestStorager_AddURLsDedupExpiring(t *testing.T) {
+	ctx := context.Background()
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, err := tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+
+			expiresAt := time.Now().Add(time.Hour)
+			batch := BatchURLs{
+				{ID: "1", URL: "http://ya.ru", ExpiresAt: expiresAt},
+				{ID: "2", URL: "http://google.com", ExpiresAt: expiresAt},
+				{ID: "3", URL: "http://google.com"},
+			}
+			result, err := tt.storage.AddURLs(ctx, batch, "user1")
+			assert.NoError(t, err)
+			assert.Len(t, result, 3)
+
+			for _, r := range result {
+				assert.False(t, r.Duplicate, "URL со сроком действия не участвуют в поиске дублирующихся URL")
+				assert.NotEqual(t, sh, r.URL)
+			}
+			assert.NotEqual(t, result[1].URL, result[2].URL)
+
+			again, err := tt.storage.AddURL(ctx, "http://google.com", "user1", URLOptions{})
+			assert.ErrorIs(t, err, DBErrorDublicate)
+			assert.Equal(t, result[2].URL, again)
+		})
+	}
+}
+
+func Test_memoryStorage_AddURLsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStorage()
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	batch := BatchURLs{{ID: "1", URL: "http://ya.ru"}, {ID: "2", URL: "http://google.com"}, {ID: "3", URL: "http://google.com"}}
+	result, err := s.AddURLs(ctx, batch, "user1")
+	assert.NoError(t, err, "дублирующиеся URL не прерывают пакетную загрузку")
+	assert.Len(t, result, 3)
+
+	assert.Equal(t, RecordURL{ID: "1", URL: sh, Duplicate: true}, result[0])
+	assert.False(t, result[1].Duplicate)
+	assert.True(t, result[2].Duplicate)
+	assert.Equal(t, result[1].URL, result[2].URL)
+}
+
+func Test_fileStorage_dedupReload(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+	deleted, err := s.AddURL(ctx, "http://google.com", "user1", URLOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.delete(ctx, []string{deleted}))
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	sh1, err := loaded.AddURL(ctx, "http://ya.ru", "user2", URLOptions{})
+	assert.ErrorIs(t, err, DBErrorDublicate, "индекс исходных URL восстанавливается из файла")
+	assert.Equal(t, sh, sh1)
+
+	_, err = loaded.AddURL(ctx, "http://google.com", "user1", URLOptions{})
+	assert.NoError(t, err, "удалённый исходный URL не считается дублирующимся после загрузки")
+}
+
+func Test_redisStorage_AddURLsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestRedisStorage(t)
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	batch := BatchURLs{{ID: "1", URL: "http://ya.ru"}, {ID: "2", URL: "http://google.com"}, {ID: "3", URL: "http://google.com"}}
+	result, err := s.AddURLs(ctx, batch, "user2")
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+
+	assert.Equal(t, RecordURL{ID: "1", URL: sh, Duplicate: true}, result[0])
+	assert.False(t, result[1].Duplicate)
+	assert.True(t, result[2].Duplicate)
+	assert.Equal(t, result[1].URL, result[2].URL)
+}
+
+func TestFlagger_FlagURL(t *testing.T) {
+	ctx := context.Background()
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagger, ok := tt.storage.(Flagger)
+			assert.True(t, ok)
+
+			sh, err := tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+
+			assert.NoError(t, flagger.FlagURL(ctx, sh, true))
+			record, err := tt.storage.FindURL(ctx, sh)
+			assert.NoError(t, err)
+			assert.True(t, record.Flagged)
+			assert.Equal(t, "http://ya.ru", record.LongURL)
+
+			assert.NoError(t, flagger.FlagURL(ctx, sh, false))
+			record, err = tt.storage.FindURL(ctx, sh)
+			assert.NoError(t, err)
+			assert.False(t, record.Flagged)
+
+			assert.ErrorIs(t, flagger.FlagURL(ctx, "unknown", true), ErrURLNotFound)
+		})
+	}
+}
+
+func Test_fileStorage_FlagURLReload(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.FlagURL(ctx, sh, true))
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	record, err := loaded.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.True(t, record.Flagged, "пометка восстанавливается из файла")
+
+	_, err = loaded.AddURL(ctx, "http://ya.ru", "user2", URLOptions{})
+	assert.ErrorIs(t, err, DBErrorDublicate, "изменение пометки не нарушает индекс исходных URL")
+}
+
+func TestStorager_UpdateURL(t *testing.T) {
+	ctx := context.Background()
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh, err := tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+			_, err = tt.storage.AddURL(ctx, "http://google.com", "user1", URLOptions{})
+			assert.NoError(t, err)
+
+			assert.ErrorIs(t, tt.storage.UpdateURL(ctx, sh, "http://go.dev", "user2"), ErrNotOwner)
+			assert.ErrorIs(t, tt.storage.UpdateURL(ctx, "unknown", "http://go.dev", "user1"), ErrURLNotFound)
+			assert.ErrorIs(t, tt.storage.UpdateURL(ctx, sh, "http://google.com", "user1"), DBErrorDublicate)
+
+			assert.NoError(t, tt.storage.UpdateURL(ctx, sh, "http://go.dev", "user1"))
+			assert.NoError(t, tt.storage.UpdateURL(ctx, sh, "http://go.dev", "user1"), "замена на тот же исходный URL")
+
+			record, err := tt.storage.FindURL(ctx, sh)
+			assert.NoError(t, err)
+			assert.Equal(t, "http://go.dev", record.LongURL)
+
+			existing, err := tt.storage.AddURL(ctx, "http://go.dev", "user1", URLOptions{})
+			assert.ErrorIs(t, err, DBErrorDublicate, "новый исходный URL занят в индексе")
+			assert.Equal(t, sh, existing)
+
+			_, err = tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err, "прежний исходный URL освобождён в индексе")
+		})
+	}
+}
+
+func TestStorager_RestoreURL(t *testing.T) {
+	ctx := context.Background()
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleter, ok := tt.storage.(interface {
+				delete(ctx context.Context, deletionBatch []string) error
+			})
+			assert.True(t, ok)
+
+			sh, err := tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+			assert.ErrorIs(t, tt.storage.RestoreURL(ctx, sh, "user1"), ErrURLNotDeleted)
+
+			assert.NoError(t, deleter.delete(ctx, []string{sh}))
+			record, err := tt.storage.FindURL(ctx, sh)
+			assert.NoError(t, err)
+			assert.False(t, record.DeletedAt.IsZero(), "момент удаления сохраняется")
+
+			assert.ErrorIs(t, tt.storage.RestoreURL(ctx, sh, "user2"), ErrNotOwner)
+			assert.ErrorIs(t, tt.storage.UpdateURL(ctx, sh, "http://go.dev", "user1"), ErrURLDeleted)
+
+			other, err := tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+			assert.ErrorIs(t, tt.storage.RestoreURL(ctx, sh, "user1"), DBErrorDublicate, "исходный URL сокращён повторно")
+
+			assert.NoError(t, deleter.delete(ctx, []string{other}))
+			assert.NoError(t, tt.storage.RestoreURL(ctx, sh, "user1"))
+
+			record, err = tt.storage.FindURL(ctx, sh)
+			assert.NoError(t, err)
+			assert.False(t, record.Deleted)
+			assert.True(t, record.DeletedAt.IsZero())
+
+			existing, err := tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.ErrorIs(t, err, DBErrorDublicate, "восстановленный URL занимает исходный URL в индексе")
+			assert.Equal(t, sh, existing)
+		})
+	}
+}
+
+func Test_restorable(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		record MemoryRecord
+		user   string
+		want   error
+	}{
+		{"Удалён в течение срока восстановления", MemoryRecord{User: "user1", Deleted: true, DeletedAt: now.Add(-time.Hour)}, "user1", nil},
+		{"Срок восстановления истёк", MemoryRecord{User: "user1", Deleted: true, DeletedAt: now.Add(-25 * time.Hour)}, "user1", ErrRestorePeriodExpired},
+		{"Момент удаления неизвестен", MemoryRecord{User: "user1", Deleted: true}, "user1", ErrRestorePeriodExpired},
+		{"Срок действия истёк", MemoryRecord{User: "user1", Deleted: true, DeletedAt: now, ExpiresAt: now.Add(-time.Minute)}, "user1", ErrURLExpired},
+		{"Не удалён", MemoryRecord{User: "user1"}, "user1", ErrURLNotDeleted},
+		{"Другой пользователь", MemoryRecord{User: "user1", Deleted: true, DeletedAt: now}, "user2", ErrNotOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, restorable(tt.record, tt.user, now, DefaultRestoreGracePeriod), tt.want)
+		})
+	}
+}
+
+func Test_fileStorage_RestoreURLReload(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+	deleted, err := s.AddURL(ctx, "http://google.com", "user1", URLOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.UpdateURL(ctx, sh, "http://go.dev", "user1"))
+	assert.NoError(t, s.delete(ctx, []string{deleted}))
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	record, err := loaded.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://go.dev", record.LongURL, "изменение исходного URL восстанавливается из файла")
+
+	assert.NoError(t, loaded.RestoreURL(ctx, deleted, "user1"), "момент удаления восстанавливается из файла")
+
+	loaded.restoreGrace = time.Nanosecond
+	assert.NoError(t, loaded.delete(ctx, []string{deleted}))
+	time.Sleep(time.Millisecond)
+	assert.ErrorIs(t, loaded.RestoreURL(ctx, deleted, "user1"), ErrRestorePeriodExpired)
+}
+
+func TestNewListQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		cursor  string
+		order   string
+		status  string
+		want    ListQuery
+		wantErr error
+	}{
+		{"Значения по умолчанию", 0, "", "", "", ListQuery{Limit: DefaultListLimit, Order: OrderDesc}, nil},
+		{"Заданные значения", 10, "", "asc", "deleted", ListQuery{Limit: 10, Order: OrderAsc, Status: StatusDeleted}, nil},
+		{"Слишком большой размер страницы", MaxListLimit + 1, "", "", "", ListQuery{}, ErrInvalidLimit},
+		{"Отрицательный размер страницы", -1, "", "", "", ListQuery{}, ErrInvalidLimit},
+		{"Неверный порядок сортировки", 0, "", "up", "", ListQuery{}, ErrInvalidOrder},
+		{"Неверный фильтр состояния", 0, "", "", "removed", ListQuery{}, ErrInvalidStatus},
+		{"Неверный курсор", 0, "!!!", "", "", ListQuery{}, ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewListQuery(tt.limit, tt.cursor, tt.order, tt.status, "")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_paginate(t *testing.T) {
+	now := time.Now()
+	entries := []URLEntry{
+		{"b", MemoryRecord{LongURL: "http://ya.ru", CreatedAt: now.Add(-3 * time.Hour)}},
+		{"a", MemoryRecord{LongURL: "http://YA.ru/maps", CreatedAt: now.Add(-3 * time.Hour)}},
+		{"c", MemoryRecord{LongURL: "http://google.com", CreatedAt: now.Add(-2 * time.Hour), Deleted: true}},
+		{"d", MemoryRecord{LongURL: "http://go.dev", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}},
+		{"e", MemoryRecord{LongURL: "http://example.com", CreatedAt: now}},
+		{"f", MemoryRecord{LongURL: "http://mail.ru", CreatedAt: now.Add(-90 * time.Minute), ExpiresAt: now.Add(-10 * time.Minute),
+			Deleted: true, DeletedAt: now.Add(-9 * time.Minute)}},
+		{"g", MemoryRecord{LongURL: "http://ok.ru", CreatedAt: now.Add(-30 * time.Minute), ExpiresAt: now.Add(-5 * time.Minute),
+			Deleted: true, DeletedAt: now.Add(-20 * time.Minute)}},
+	}
+
+	tests := []struct {
+		name  string
+		query ListQuery
+		want  [][]string
+	}{
+		{"По убыванию момента создания без удалённых", ListQuery{Limit: 2, Order: OrderDesc}, [][]string{{"e", "d"}, {"f", "b"}, {"a"}}},
+		{"По возрастанию момента создания без удалённых", ListQuery{Limit: 3, Order: OrderAsc}, [][]string{{"a", "b", "f"}, {"d", "e"}}},
+		{"Действующие", ListQuery{Limit: 10, Order: OrderAsc, Status: StatusActive}, [][]string{{"a", "b", "e"}}},
+		{"Удалённые до истечения срока действия", ListQuery{Limit: 10, Order: OrderAsc, Status: StatusDeleted}, [][]string{{"c", "g"}}},
+		{"С истёкшим сроком действия, в том числе помеченные удалёнными", ListQuery{Limit: 10, Order: OrderAsc, Status: StatusExpired}, [][]string{{"f", "d"}}},
+		{"Поиск без учёта регистра", ListQuery{Limit: 1, Order: OrderAsc, Search: "ya.RU"}, [][]string{{"a"}, {"b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.query
+			for i, want := range tt.want {
+				page, err := paginate(entries, q, now)
+				assert.NoError(t, err)
+
+				got := make([]string, 0, len(page.URLs))
+				for _, e := range page.URLs {
+					got = append(got, e.ShortURL)
+				}
+				assert.Equal(t, want, got)
+
+				if i == len(tt.want)-1 {
+					assert.Empty(t, page.NextCursor, "последняя страница")
+				} else {
+					assert.NotEmpty(t, page.NextCursor)
+				}
+				q.Cursor = page.NextCursor
+			}
+		})
+	}
+}
+
+func TestStorager_ListURLs(t *testing.T) {
+	ctx := context.Background()
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			longURLs := []string{"http://ya.ru", "http://google.com", "http://go.dev"}
+			shortURLs := make([]string, 0, len(longURLs))
+			for _, l := range longURLs {
+				sh, err := tt.storage.AddURL(ctx, l, "user1", URLOptions{})
+				assert.NoError(t, err)
+				shortURLs = append(shortURLs, sh)
+				time.Sleep(time.Millisecond)
+			}
+			_, err := tt.storage.AddURL(ctx, "http://example.com", "user2", URLOptions{})
+			assert.NoError(t, err)
+
+			page, err := tt.storage.ListURLs(ctx, "user1", ListQuery{Limit: 2, Order: OrderDesc})
+			assert.NoError(t, err)
+			assert.Len(t, page.URLs, 2)
+			assert.Equal(t, shortURLs[2], page.URLs[0].ShortURL)
+			assert.Equal(t, "http://go.dev", page.URLs[0].LongURL)
+			assert.False(t, page.URLs[0].CreatedAt.IsZero(), "момент создания сохраняется")
+			assert.Equal(t, shortURLs[1], page.URLs[1].ShortURL)
+
+			page, err = tt.storage.ListURLs(ctx, "user1", ListQuery{Limit: 2, Order: OrderDesc, Cursor: page.NextCursor})
+			assert.NoError(t, err)
+			assert.Len(t, page.URLs, 1)
+			assert.Equal(t, shortURLs[0], page.URLs[0].ShortURL)
+			assert.Empty(t, page.NextCursor)
+
+			page, err = tt.storage.ListURLs(ctx, "user1", ListQuery{Limit: 10, Order: OrderAsc, Search: "GOOGLE"})
+			assert.NoError(t, err)
+			assert.Len(t, page.URLs, 1)
+			assert.Equal(t, shortURLs[1], page.URLs[0].ShortURL)
+
+			page, err = tt.storage.ListURLs(ctx, "unknown", ListQuery{Limit: 10})
+			assert.NoError(t, err)
+			assert.Empty(t, page.URLs)
+		})
+	}
+}
+
+func Test_fileStorage_createdAtReload(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+	created, err := s.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	record, err := loaded.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.True(t, created.CreatedAt.Equal(record.CreatedAt), "момент создания восстанавливается из файла")
+}
+
+func TestStorager_DeleteURLs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := tt.storage.(deleter)
+			assert.True(t, ok)
+
+			owned, err := tt.storage.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+			deleted, err := tt.storage.AddURL(ctx, "http://go.dev", "user1", URLOptions{})
+			assert.NoError(t, err)
+			foreign, err := tt.storage.AddURL(ctx, "http://google.com", "user2", URLOptions{})
+			assert.NoError(t, err)
+			assert.NoError(t, d.delete(ctx, []string{deleted}))
+
+			job, err := tt.storage.DeleteURLs(ctx, []string{owned, foreign, "unknown", deleted, owned}, "user1")
+			assert.NoError(t, err)
+			assert.NotEmpty(t, job.ID)
+			assert.False(t, job.Done())
+			assert.Equal(t, []DeletionResult{
+				{owned, DeletionQueued},
+				{foreign, DeletionForbidden},
+				{"unknown", DeletionNotFound},
+				{deleted, DeletionAlreadyDeleted},
+			}, job.Results)
+
+			_, err = tt.storage.GetDeletionJob(ctx, job.ID, "user2")
+			assert.ErrorIs(t, err, ErrDeletionJobNotFound, "задание доступно только создавшему его пользователю")
+
+			d.DeletionQueueProcess(ctx)
+			assert.Eventually(t, func() bool {
+				job, err = tt.storage.GetDeletionJob(ctx, job.ID, "user1")
+				return err == nil && job.Done()
+			}, 5*time.Second, 10*time.Millisecond)
+			assert.Equal(t, DeletionDeleted, job.Results[0].Status)
+
+			record, err := tt.storage.FindURL(ctx, owned)
+			assert.NoError(t, err)
+			assert.True(t, record.Deleted)
+
+			record, err = tt.storage.FindURL(ctx, foreign)
+			assert.NoError(t, err)
+			assert.False(t, record.Deleted)
+		})
+	}
+}
+
+func Test_deletionJobs(t *testing.T) {
+	ctx := context.Background()
+	jobs := newDeletionJobs()
+	now := time.Now()
+
+	old, err := jobs.start(ctx, "user1", []DeletionResult{{"a", DeletionQueued}}, now.Add(-2*DeletionJobRetention))
+	assert.NoError(t, err)
+
+	job, err := jobs.start(ctx, "user1", []DeletionResult{{"a", DeletionQueued}, {"b", DeletionQueued}}, now)
+	assert.NoError(t, err)
+	assert.NotEqual(t, old.ID, job.ID)
+
+	_, err = jobs.get(ctx, old.ID, "user1")
+	assert.ErrorIs(t, err, ErrDeletionJobNotFound, "устаревшее задание удаляется")
+
+	assert.NoError(t, jobs.finish(ctx, []deletionRequest{{job.ID, "a"}, {"unknown", "b"}}, DeletionDeleted))
+	assert.NoError(t, jobs.finish(ctx, []deletionRequest{{job.ID, "b"}}, DeletionFailed))
+	assert.NoError(t, jobs.finish(ctx, []deletionRequest{{job.ID, "a"}}, DeletionFailed))
+
+	got, err := jobs.get(ctx, job.ID, "user1")
+	assert.NoError(t, err)
+	assert.True(t, got.Done())
+	assert.Equal(t, []DeletionResult{{"a", DeletionDeleted}, {"b", DeletionFailed}}, got.Results, "итоговое состояние не меняется")
+	assert.Equal(t, DeletionQueued, job.Results[0].Status, "возвращается копия задания")
+}
+
+func Test_deletionQueue(t *testing.T) {
+	q := newDeletionQueue(DeletionOptions{BatchSize: 2})
+	assert.Equal(t, DefaultDeletionFlushInterval, q.options.FlushInterval)
+
+	assert.NoError(t, q.push(context.Background(), []deletionRequest{{"job", "a"}}))
+	select {
+	case <-q.full:
+		t.Fatal("неполный пакет не должен запускать удаление")
+	default:
+	}
+
+	assert.NoError(t, q.push(context.Background(), []deletionRequest{{"job", "b"}, {"job", "c"}}))
+	select {
+	case <-q.full:
+	default:
+		t.Fatal("заполненный пакет должен запускать удаление")
+	}
+
+	assert.Equal(t, []deletionRequest{{"job", "a"}, {"job", "b"}}, q.take())
+	assert.Equal(t, []deletionRequest{{"job", "c"}}, q.take())
+	assert.Empty(t, q.take())
+}
+
+// failingDeleter возвращает ошибку при удалении заданное количество раз.
+type failingDeleter struct {
+	*MemoryStorage
+	failures int
+}
+
+func (d *failingDeleter) delete(ctx context.Context, shortURLs []string) error {
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("хранилище недоступно")
+	}
+
+	return d.MemoryStorage.delete(ctx, shortURLs)
+}
+
+func Test_deletionQueue_retry(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		failures   int
+		wantStatus DeletionStatus
+		wantLen    int
+	}{
+		{"Успешная повторная попытка", 1, DeletionDeleted, 0},
+		{"Исчерпаны все попытки", DeletionMaxAttempts, DeletionFailed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &failingDeleter{MemoryStorage: NewMemoryStorage(), failures: tt.failures}
+			sh, err := d.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+			assert.NoError(t, err)
+
+			q := newDeletionQueue(DeletionOptions{})
+			jobs := newDeletionJobs()
+			job, err := jobs.start(ctx, "user1", []DeletionResult{{sh, DeletionQueued}}, time.Now())
+			assert.NoError(t, err)
+			assert.NoError(t, q.push(ctx, []deletionRequest{{job.ID, sh}}))
+
+			assert.Error(t, q.flush(ctx, d, jobs))
+			assert.True(t, q.waiting(time.Now()), "повторная попытка откладывается")
+			assert.Equal(t, 1, q.len(), "пакет возвращается в очередь")
+
+			job, err = jobs.get(ctx, job.ID, "user1")
+			assert.NoError(t, err)
+			assert.False(t, job.Done(), "состояние не меняется до окончательного результата")
+
+			for attempt := 1; attempt < DeletionMaxAttempts && q.len() > 0; attempt++ {
+				_ = q.flush(ctx, d, jobs)
+			}
+
+			job, err = jobs.get(ctx, job.ID, "user1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, job.Results[0].Status)
+			assert.Equal(t, tt.wantLen, q.len())
+			assert.False(t, q.waiting(time.Now()))
+		})
+	}
+}
+
+func TestDeletionDrainer_DrainDeletionQueue(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewMemoryStorage()
+	s.deletionQueue = newDeletionQueue(DeletionOptions{FlushInterval: time.Hour})
+	s.DeletionQueueProcess(ctx)
+
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	job, err := s.DeleteURLs(ctx, []string{sh}, "user1")
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.DrainDeletionQueue(ctx))
+
+	job, err = s.GetDeletionJob(ctx, job.ID, "user1")
+	assert.NoError(t, err)
+	assert.True(t, job.Done(), "очередь удаляется до истечения периода обработки неполного пакета")
+
+	record, err := s.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.True(t, record.Deleted)
+}
+
+func Test_deletionJournal(t *testing.T) {
+	ctx := context.Background()
+	f := filepath.Join(t.TempDir(), "shurldb.txt"+deletionJournalSuffix)
+
+	j, err := openDeletionJournal(f)
+	assert.NoError(t, err)
+	assert.NoError(t, j.savePending(ctx, []deletionRequest{{"job", "a"}, {"job", "b"}, {"job", "c"}}))
+	assert.NoError(t, j.removePending(ctx, []deletionRequest{{"job", "b"}}))
+	assert.NoError(t, j.close())
+
+	j, err = openDeletionJournal(f)
+	assert.NoError(t, err)
+	pending, err := j.loadPending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []deletionRequest{{"job", "a"}, {"job", "c"}}, pending, "загружаются только необработанные запросы")
+
+	assert.NoError(t, j.removePending(ctx, pending))
+	assert.NoError(t, j.close())
+
+	info, err := os.Stat(f)
+	assert.NoError(t, err)
+	assert.Zero(t, info.Size(), "журнал очищается, когда необработанных запросов не остаётся")
+}
+
+func Test_fileStorage_deletionJournalReload(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	sh, err := s.AddURL(ctx, "http://ya.ru", "user1", URLOptions{})
+	assert.NoError(t, err)
+
+	_, err = s.DeleteURLs(ctx, []string{sh}, "user1")
+	assert.NoError(t, err)
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	record, err := loaded.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.False(t, record.Deleted, "удаление не выполнено до остановки")
+
+	loaded.DeletionQueueProcess(ctx)
+	assert.NoError(t, loaded.DrainDeletionQueue(ctx))
+
+	record, err = loaded.FindURL(ctx, sh)
+	assert.NoError(t, err)
+	assert.True(t, record.Deleted, "запрос на удаление восстанавливается из журнала")
+}
+
+func TestAPIKeyStorager(t *testing.T) {
+	ctx := context.Background()
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, ok := tt.storage.(APIKeyStorager)
+			assert.True(t, ok)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			first := APIKey{ID: "key1", User: "user1", Name: "jobs", Hash: "hash1", Scope: "create", CreatedAt: now}
+			second := APIKey{ID: "key2", User: "user2", Hash: "hash2", CreatedAt: now.Add(time.Second)}
+			assert.NoError(t, keys.AddAPIKey(ctx, second))
+			assert.NoError(t, keys.AddAPIKey(ctx, first))
+
+			key, err := keys.GetAPIKey(ctx, "key1")
+			assert.NoError(t, err)
+			assert.Equal(t, first, key)
+
+			_, err = keys.GetAPIKey(ctx, "unknown")
+			assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+			assert.NoError(t, keys.RevokeAPIKey(ctx, "key1", now.Add(time.Minute)))
+			assert.NoError(t, keys.RevokeAPIKey(ctx, "key1", now.Add(time.Hour)))
+			assert.ErrorIs(t, keys.RevokeAPIKey(ctx, "unknown", now), ErrAPIKeyNotFound)
+
+			list, err := keys.ListAPIKeys(ctx)
+			assert.NoError(t, err)
+			if assert.Len(t, list, 2) {
+				assert.Equal(t, "key1", list[0].ID, "ключи упорядочены по моменту создания")
+				assert.True(t, list[0].Revoked())
+				assert.True(t, now.Add(time.Minute).Equal(list[0].RevokedAt), "повторный отзыв не меняет момент отзыва")
+				assert.Equal(t, second, list[1])
+			}
+		})
+	}
+}
+
+func Test_fileStorage_APIKeysReload(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	s := newTestFileStorage(t, filePath)
+	assert.NoError(t, s.AddAPIKey(ctx, APIKey{ID: "key1", User: "user1", Hash: "hash1", CreatedAt: now}))
+	assert.NoError(t, s.AddAPIKey(ctx, APIKey{ID: "key2", User: "user2", Hash: "hash2", CreatedAt: now}))
+	assert.NoError(t, s.RevokeAPIKey(ctx, "key1", now))
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	key, err := loaded.GetAPIKey(ctx, "key1")
+	assert.NoError(t, err)
+	assert.True(t, key.Revoked(), "отзыв ключа восстанавливается из файла")
+
+	key, err = loaded.GetAPIKey(ctx, "key2")
+	assert.NoError(t, err)
+	assert.False(t, key.Revoked())
+	assert.Equal(t, "hash2", key.Hash)
+}
+
+func TestUserMerger_MergeUser(t *testing.T) {
+	ctx := context.Background()
+	redisStorage, _ := newTestRedisStorage(t)
+	fileStorage := newTestFileStorage(t, filepath.Join(t.TempDir(), "shurldb.txt"))
+	defer fileStorage.CloseFunc()()
+
+	tests := []struct {
+		name    string
+		storage Storager
+	}{
+		{"Хранилище в памяти", NewMemoryStorage()},
+		{"Хранилище в файле", fileStorage},
+		{"Хранилище во встроенной БД", newTestBoltStorage(t, filepath.Join(t.TempDir(), "shurl.db"))},
+		{"Хранилище на Redis-совместимом сервере", redisStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger, ok := tt.storage.(UserMerger)
+			assert.True(t, ok)
+
+			first, err := tt.storage.AddURL(ctx, "http://ya.ru/1", "anonymous", URLOptions{})
+			assert.NoError(t, err)
+			second, err := tt.storage.AddURL(ctx, "http://ya.ru/2", "anonymous", URLOptions{})
+			assert.NoError(t, err)
+			own, err := tt.storage.AddURL(ctx, "http://ya.ru/3", "named", URLOptions{})
+			assert.NoError(t, err)
+
+			merged, err := merger.MergeUser(ctx, "anonymous", "named")
+			assert.NoError(t, err)
+			assert.Equal(t, 2, merged)
+
+			urls, err := tt.storage.GetURLsByUser(ctx, "named")
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, []string{first, second, own}, urls)
+
+			urls, err = tt.storage.GetURLsByUser(ctx, "anonymous")
+			assert.NoError(t, err)
+			assert.Empty(t, urls)
+
+			record, err := tt.storage.FindURL(ctx, first)
+			assert.NoError(t, err)
+			assert.Equal(t, "named", record.User)
+
+			assert.NoError(t, tt.storage.UpdateURL(ctx, second, "http://ya.ru/updated", "named"),
+				"новый владелец может изменять перенесённые короткие URL")
+
+			merged, err = merger.MergeUser(ctx, "anonymous", "named")
+			assert.NoError(t, err)
+			assert.Zero(t, merged, "повторный перенос ничего не меняет")
+		})
+	}
+}
+
+func Test_fileStorage_MergeUserReload(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "shurldb.txt")
+
+	s := newTestFileStorage(t, filePath)
+	sh, err := s.AddURL(ctx, "http://ya.ru", "anonymous", URLOptions{})
+	assert.NoError(t, err)
+	_, err = s.MergeUser(ctx, "anonymous", "named")
+	assert.NoError(t, err)
+	s.CloseFunc()()
+
+	loaded := newTestFileStorage(t, filePath)
+	defer loaded.CloseFunc()()
+
+	urls, err := loaded.GetURLsByUser(ctx, "named")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{sh}, urls, "перенос восстанавливается из файла")
+
+	urls, err = loaded.GetURLsByUser(ctx, "anonymous")
+	assert.NoError(t, err)
+	assert.Empty(t, urls)
+}
@@ -0,0 +1,226 @@
+// Пакет urlnorm проверяет и приводит к единому виду исходные длинные URL перед сокращением.
+package urlnorm
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Настройки проверки исходных URL по умолчанию.
+const (
+	// DefaultMaxLength задаёт максимальную длину исходного URL в байтах.
+	DefaultMaxLength = 2048
+)
+
+// Причины отказа в сокращении исходного URL.
+const (
+	ReasonEmpty     Reason = "empty"              // Исходный URL не задан
+	ReasonTooLong   Reason = "too_long"           // Исходный URL длиннее допустимого
+	ReasonMalformed Reason = "malformed"          // Исходный URL не удалось разобрать
+	ReasonScheme    Reason = "scheme_not_allowed" // Схема исходного URL не входит в список разрешённых
+	ReasonHost      Reason = "invalid_host"       // Имя хоста исходного URL не задано или недопустимо
+)
+
+var (
+	// DefaultSchemes содержит схемы исходных URL, разрешённые по умолчанию.
+	DefaultSchemes = []string{"http", "https"}
+	// DefaultStripParams содержит параметры запроса, удаляемые из исходных URL по умолчанию.
+	DefaultStripParams = []string{"utm_*", "fbclid"}
+
+	defaultPorts = map[string]string{
+		"http":  "80",
+		"https": "443",
+	}
+)
+
+// Типы данных для проверки и нормализации исходных URL.
+type (
+	// Reason содержит машиночитаемую причину отказа в сокращении исходного URL.
+	Reason string
+
+	// Error содержит описание ошибки проверки исходного URL.
+	Error struct {
+		Reason  Reason // Причина отказа
+		Message string // Описание ошибки для пользователя
+	}
+
+	// Options содержит настройки проверки и нормализации исходных URL.
+	// Незаданные настройки заменяются значениями по умолчанию.
+	Options struct {
+		Schemes     []string // Разрешённые схемы исходных URL
+		StripParams []string // Удаляемые параметры запроса, шаблон вида "utm_*" задаёт префикс имени параметра
+		MaxLength   int      // Максимальная длина исходного URL в байтах
+	}
+
+	// Normalizer проверяет исходные URL и приводит их к единому виду.
+	Normalizer struct {
+		schemes     map[string]bool
+		stripParams []string
+		maxLength   int
+	}
+)
+
+// Error возвращает описание ошибки проверки исходного URL.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewNormalizer создаёт обработчик исходных URL с заданными настройками.
+func NewNormalizer(opts Options) *Normalizer {
+	schemes := opts.Schemes
+	if len(schemes) == 0 {
+		schemes = DefaultSchemes
+	}
+
+	stripParams := opts.StripParams
+	if stripParams == nil {
+		stripParams = DefaultStripParams
+	}
+
+	n := &Normalizer{
+		schemes:     make(map[string]bool, len(schemes)),
+		stripParams: make([]string, 0, len(stripParams)),
+		maxLength:   opts.MaxLength,
+	}
+
+	if n.maxLength <= 0 {
+		n.maxLength = DefaultMaxLength
+	}
+
+	for _, scheme := range schemes {
+		n.schemes[strings.ToLower(strings.TrimSpace(scheme))] = true
+	}
+
+	for _, param := range stripParams {
+		param = strings.TrimSpace(param)
+		if param != "" {
+			n.stripParams = append(n.stripParams, param)
+		}
+	}
+
+	return n
+}
+
+// Normalize проверяет исходный URL и возвращает его в едином виде:
+// имя хоста в нижнем регистре и в кодировке punycode, без порта по умолчанию для схемы
+// и без параметров запроса, служащих для отслеживания переходов.
+// При недопустимом исходном URL возвращается ошибка типа *Error.
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", &Error{ReasonEmpty, "исходный URL не задан"}
+	}
+
+	if len(raw) > n.maxLength {
+		return "", n.tooLong()
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", &Error{ReasonMalformed, "неверный формат URL: " + err.Error()}
+	}
+
+	if !n.schemes[u.Scheme] {
+		return "", &Error{ReasonScheme, "недопустимая схема URL: '" + u.Scheme + "'"}
+	}
+
+	if u.Opaque != "" {
+		return "", &Error{ReasonMalformed, "неверный формат URL: отсутствует имя хоста"}
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", &Error{ReasonHost, "недопустимое имя хоста: " + err.Error()}
+	}
+
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	u.RawQuery = n.stripQuery(u.RawQuery)
+	u.ForceQuery = false
+
+	result := u.String()
+	if len(result) > n.maxLength {
+		return "", n.tooLong()
+	}
+
+	return result, nil
+}
+
+func (n *Normalizer) tooLong() *Error {
+	return &Error{ReasonTooLong, "длина URL превышает допустимую"}
+}
+
+// normalizeHost приводит имя хоста к нижнему регистру и кодировке punycode.
+// IP-адреса возвращаются без изменений.
+func normalizeHost(host string) (string, error) {
+	if host == "" {
+		return "", errors.New("имя хоста не задано")
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return strings.ToLower(host), nil
+	}
+
+	return idna.Lookup.ToASCII(strings.ToLower(host))
+}
+
+// stripQuery удаляет из строки запроса параметры, заданные в настройках, сохраняя порядок остальных параметров.
+func (n *Normalizer) stripQuery(query string) string {
+	if query == "" || len(n.stripParams) == 0 {
+		return query
+	}
+
+	params := strings.Split(query, "&")
+	kept := params[:0]
+	for _, param := range params {
+		if param == "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+
+		if !n.stripped(name) {
+			kept = append(kept, param)
+		}
+	}
+
+	return strings.Join(kept, "&")
+}
+
+// stripped проверяет, нужно ли удалить параметр запроса с заданным именем.
+func (n *Normalizer) stripped(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range n.stripParams {
+		pattern = strings.ToLower(pattern)
+		if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			continue
+		}
+
+		if name == pattern {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package urlnorm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	n := NewNormalizer(Options{})
+
+	tests := []struct {
+		name       string
+		raw        string
+		want       string
+		wantReason Reason
+	}{
+		{"Имя хоста в нижнем регистре", "HTTP://Example.COM/Path", "http://example.com/Path", ""},
+		{"Пробелы по краям", "  https://ya.ru  ", "https://ya.ru", ""},
+		{"Порт по умолчанию для http", "http://ya.ru:80/a", "http://ya.ru/a", ""},
+		{"Порт по умолчанию для https", "https://ya.ru:443", "https://ya.ru", ""},
+		{"Другой порт сохраняется", "https://ya.ru:8443/", "https://ya.ru:8443/", ""},
+		{"Интернационализированное имя хоста", "http://ЯНДЕКС.рф/", "http://xn--d1acpjx3f.xn--p1ai/", ""},
+		{"IPv6-адрес", "http://[::1]:80/", "http://[::1]/", ""},
+		{
+			"Параметры отслеживания",
+			"https://ya.ru/?utm_source=a&q=go&fbclid=b&UTM_Medium=c&page=2#top",
+			"https://ya.ru/?q=go&page=2#top",
+			"",
+		},
+		{"Только параметры отслеживания", "https://ya.ru/?utm_source=a", "https://ya.ru/", ""},
+		{"Пустой URL", " ", "", ReasonEmpty},
+		{"Слишком длинный URL", "https://ya.ru/" + strings.Repeat("a", DefaultMaxLength), "", ReasonTooLong},
+		{"Неразбираемый URL", "http://ya.ru/%zz", "", ReasonMalformed},
+		{"Недопустимая схема", "ftp://ya.ru/file", "", ReasonScheme},
+		{"Нет схемы", "ya.ru", "", ReasonScheme},
+		{"Нет имени хоста", "http:///path", "", ReasonHost},
+		{"Схема без имени хоста", "http:ya.ru", "", ReasonMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := n.Normalize(tt.raw)
+			if tt.wantReason != "" {
+				var urlErr *Error
+				assert.True(t, errors.As(err, &urlErr))
+				assert.Equal(t, tt.wantReason, urlErr.Reason)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewNormalizer(t *testing.T) {
+	n := NewNormalizer(Options{Schemes: []string{"HTTPS", " ftp"}, StripParams: []string{"ref"}, MaxLength: 40})
+
+	got, err := n.Normalize("ftp://ya.ru/?ref=a&utm_source=b")
+	assert.NoError(t, err)
+	assert.Equal(t, "ftp://ya.ru/?utm_source=b", got)
+
+	_, err = n.Normalize("http://ya.ru")
+	assert.Error(t, err, "схема http не разрешена настройками")
+
+	_, err = n.Normalize("https://ya.ru/" + strings.Repeat("a", 30))
+	assert.Error(t, err, "длина URL превышает заданную в настройках")
+
+	got, err = NewNormalizer(Options{StripParams: []string{}}).Normalize("https://ya.ru/?utm_source=a")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ya.ru/?utm_source=a", got, "пустой список параметров отключает их удаление")
+}